@@ -0,0 +1,310 @@
+// Package bitcoin implements the transaction merkle tree of Bitcoin stored in the memory of the system.
+// Nodes are hashed with double SHA-256 over the internal byte order of their children and the odd node is
+// paired with itself, the same way memory.MerkleTree does. All hashes are communicated the way Bitcoin Core
+// displays them - byte reversed hex without prefix - so txids and roots can be compared with block explorers
+// and the RPC of a node. The package also builds and parses the partial merkle trees of gettxoutproof
+package bitcoin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/LimeChain/merkletree"
+	"strings"
+	"sync"
+)
+
+const (
+	outOfBounds = "Incorrect index - Index out of bounds"
+	invalidSize = "Incorrect size - Size out of bounds"
+	invalidHash = "Incorrect hash - The hash is not a 32 byte hex"
+)
+
+// Hash is a double SHA-256 hash in internal byte order
+type Hash [32]byte
+
+// String returns the byte reversed hex of the hash, as displayed by Bitcoin Core
+func (hash Hash) String() string {
+	reversed := hash
+	for i, j := 0, len(reversed)-1; i < j; i, j = i+1, j-1 {
+		reversed[i], reversed[j] = reversed[j], reversed[i]
+	}
+	return hex.EncodeToString(reversed[:])
+}
+
+// NewHashFromString parses byte reversed hex, as displayed by Bitcoin Core, into a Hash in internal byte order
+func NewHashFromString(s string) (Hash, error) {
+	var hash Hash
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil || len(b) != len(hash) {
+		return hash, errors.New(invalidHash)
+	}
+	for i := range b {
+		hash[len(hash)-1-i] = b[i]
+	}
+	return hash, nil
+}
+
+// DoubleHash returns SHA-256(SHA-256(data))
+func DoubleHash(data ...[]byte) Hash {
+	h := sha256.New()
+	for _, d := range data {
+		h.Write(d)
+	}
+	return sha256.Sum256(h.Sum(nil))
+}
+
+func hashPair(left, right Hash) Hash {
+	return DoubleHash(left[:], right[:])
+}
+
+// Node is implementation of types.Node and representation of a single leaf in the merkle tree
+type Node struct {
+	hash  Hash
+	index int
+}
+
+// Hash returns the byte reversed hex of the hash of the node
+func (node *Node) Hash() string {
+	return node.hash.String()
+}
+
+// Index returns the index of this node in its level
+func (node *Node) Index() int {
+	return node.index
+}
+
+// String returns the hash of this node. Alias to Hash()
+func (node Node) String() string {
+	return node.Hash()
+}
+
+// MerkleTree is the transaction merkle tree of a Bitcoin block. The leafs are txids and the root is
+// recalculated lazily on the next request
+type MerkleTree struct {
+	leafs []*Node
+	root  *Hash
+	mutex sync.RWMutex
+}
+
+// levelUp returns the parents of the given level, duplicating the odd node
+func levelUp(level []Hash) []Hash {
+	parents := make([]Hash, 0, (len(level)+1)/2)
+	for i := 0; i < len(level); i += 2 {
+		right := level[i]
+		if i+1 < len(level) {
+			right = level[i+1]
+		}
+		parents = append(parents, hashPair(level[i], right))
+	}
+	return parents
+}
+
+func (tree *MerkleTree) txids() []Hash {
+	level := make([]Hash, len(tree.leafs))
+	for i, leaf := range tree.leafs {
+		level[i] = leaf.hash
+	}
+	return level
+}
+
+func (tree *MerkleTree) getRoot() string {
+	if len(tree.leafs) == 0 {
+		return ""
+	}
+	if tree.root == nil {
+		level := tree.txids()
+		for len(level) > 1 {
+			level = levelUp(level)
+		}
+		tree.root = &level[0]
+	}
+	return tree.root.String()
+}
+
+// Add double hashes the serialized transaction into its txid and inserts it on the next available slot in the tree.
+// Returns the index it was inserted and the txid
+func (tree *MerkleTree) Add(data []byte) (index int, hash string) {
+	txid := DoubleHash(data)
+	index = tree.Insert(txid.String())
+	return index, txid.String()
+}
+
+// RawAdd is alias to Add. The root is always recalculated lazily on the next request
+func (tree *MerkleTree) RawAdd(data []byte) (index int, hash string) {
+	return tree.Add(data)
+}
+
+// Push appends the txid as the next leaf of the tree. Returns the index of the leaf or error if the txid is not valid hex
+func (tree *MerkleTree) Push(txid string) (index int, err error) {
+	hash, err := NewHashFromString(txid)
+	if err != nil {
+		return -1, err
+	}
+	return tree.push(hash).index, nil
+}
+
+// RawInsert pushes the txid into the tree. Returns the index of the leaf and the node or -1 and nil if the txid is not valid hex.
+// Use Push to get the error
+func (tree *MerkleTree) RawInsert(hash string) (index int, insertedLeaf merkletree.Node) {
+	txid, err := NewHashFromString(hash)
+	if err != nil {
+		return -1, nil
+	}
+	leaf := tree.push(txid)
+	return leaf.index, leaf
+}
+
+func (tree *MerkleTree) push(txid Hash) *Node {
+	tree.mutex.Lock()
+	defer tree.mutex.Unlock()
+
+	leaf := &Node{txid, len(tree.leafs)}
+	tree.leafs = append(tree.leafs, leaf)
+	tree.root = nil
+
+	return leaf
+}
+
+// Insert is alias to RawInsert returning only the index
+func (tree *MerkleTree) Insert(hash string) (index int) {
+	index, _ = tree.RawInsert(hash)
+	return index
+}
+
+// Recalculate recalculates the root and returns it
+func (tree *MerkleTree) Recalculate() (treeRoot string) {
+	tree.mutex.Lock()
+	defer tree.mutex.Unlock()
+
+	tree.root = nil
+	return tree.getRoot()
+}
+
+// Truncate removes all leafs from the given size onwards.
+// Returns error if the size is negative or bigger than the current length of the tree
+func (tree *MerkleTree) Truncate(size int) error {
+	tree.mutex.Lock()
+	defer tree.mutex.Unlock()
+
+	if size < 0 || size > len(tree.leafs) {
+		return errors.New(invalidSize)
+	}
+	tree.leafs = tree.leafs[:size]
+	tree.root = nil
+
+	return nil
+}
+
+// IntermediaryHashesByIndex returns the merkle branch of the txid at the given index - the siblings from the leaf up to the root
+func (tree *MerkleTree) IntermediaryHashesByIndex(index int) (intermediaryHashes []string, err error) {
+	tree.mutex.RLock()
+	defer tree.mutex.RUnlock()
+
+	if index < 0 || index >= len(tree.leafs) {
+		return nil, errors.New(outOfBounds)
+	}
+
+	intermediaryHashes = make([]string, 0)
+	for level := tree.txids(); len(level) > 1; level = levelUp(level) {
+		sibling := index ^ 1
+		if sibling >= len(level) {
+			sibling = index
+		}
+		intermediaryHashes = append(intermediaryHashes, level[sibling].String())
+		index /= 2
+	}
+
+	return intermediaryHashes, nil
+}
+
+// ValidateExistence emulates how third party would validate the transaction. Given the serialized transaction, the index it is supposed to be and its merkle branch,
+// the method validates that this is the correct transaction for that slot
+func (tree *MerkleTree) ValidateExistence(original []byte, index int, intermediaryHashes []string) (result bool, err error) {
+	tree.mutex.Lock()
+	defer tree.mutex.Unlock()
+
+	if index < 0 || index >= len(tree.leafs) {
+		return false, errors.New(outOfBounds)
+	}
+
+	return Verify(tree.getRoot(), index, DoubleHash(original).String(), intermediaryHashes), nil
+}
+
+// Verify validates that the txid is at the given index in the block with the given merkle root using its merkle branch
+func Verify(root string, index int, txid string, intermediaryHashes []string) bool {
+	hash, err := NewHashFromString(txid)
+	if err != nil || index < 0 {
+		return false
+	}
+
+	for _, h := range intermediaryHashes {
+		sibling, err := NewHashFromString(h)
+		if err != nil {
+			return false
+		}
+		if index%2 == 0 {
+			hash = hashPair(hash, sibling)
+		} else {
+			hash = hashPair(sibling, hash)
+		}
+		index /= 2
+	}
+
+	expected, err := NewHashFromString(root)
+	return err == nil && index == 0 && hash == expected
+}
+
+// HashAt returns the txid at given index
+func (tree *MerkleTree) HashAt(index int) (string, error) {
+	tree.mutex.RLock()
+	defer tree.mutex.RUnlock()
+
+	if index < 0 || index >= len(tree.leafs) {
+		return "", errors.New(outOfBounds)
+	}
+	return tree.leafs[index].Hash(), nil
+}
+
+// Root returns the merkle root of the transactions or empty string if there are none
+func (tree *MerkleTree) Root() string {
+	tree.mutex.Lock()
+	defer tree.mutex.Unlock()
+
+	return tree.getRoot()
+}
+
+// Length returns the count of the transactions
+func (tree *MerkleTree) Length() int {
+	tree.mutex.RLock()
+	defer tree.mutex.RUnlock()
+
+	return len(tree.leafs)
+}
+
+// String returns human readable version of the tree
+func (tree *MerkleTree) String() string {
+	b := strings.Builder{}
+	b.WriteString(fmt.Sprintf("Root: %v, Count: %v\n", tree.Root(), tree.Length()))
+
+	tree.mutex.RLock()
+	defer tree.mutex.RUnlock()
+	for _, leaf := range tree.leafs {
+		b.WriteString(fmt.Sprintf("%v\t", leaf.Hash()))
+	}
+	b.WriteString("\n")
+
+	return b.String()
+}
+
+// MarshalJSON Creates JSON version of the needed fields of the tree
+func (tree *MerkleTree) MarshalJSON() ([]byte, error) {
+	res := fmt.Sprintf("{\"root\":\"%v\", \"length\":%v}", tree.Root(), tree.Length())
+	return []byte(res), nil
+}
+
+// NewMerkleTree returns a pointer to an initialized MerkleTree
+func NewMerkleTree() *MerkleTree {
+	return &MerkleTree{}
+}
@@ -0,0 +1,23 @@
+package bitcoin_test
+
+import (
+	"fmt"
+	"github.com/LimeChain/merkletree/bitcoin"
+)
+
+func Example() {
+	tree := bitcoin.NewMerkleTree()
+	tree.Insert("8c14f0db3df150123e6f3dbbf30f8b955a8249b62ac1d1ff16284aefa3d06d87")
+	tree.Insert("fff2525b8931402dd09222c50775608f75787bd2b87e56995a7bdd30f79702c4")
+	tree.Insert("6359f0868171b1d194cbee1af2f16ea598ae8fad666d9b012c8ed2b79a236ec4")
+	tree.Insert("e9a66845e05d5abc0ad04ec80f774a7e585c6e8db975962d069a522137b80c1d")
+	fmt.Printf("Merkle Root: %v\n", tree.Root())
+
+	pmt, _ := tree.PartialMerkleTree(2)
+	root, txids, _, _ := pmt.ExtractMatches()
+	fmt.Printf("Proven: %v %v\n", root == tree.Root(), txids[0])
+
+	// Output:
+	// Merkle Root: f3e94742aca4b5ef85488dc37c06c3282295ffec960994b2c0d5ac2a25a95766
+	// Proven: true 6359f0868171b1d194cbee1af2f16ea598ae8fad666d9b012c8ed2b79a236ec4
+}
@@ -0,0 +1,216 @@
+package bitcoin
+
+import (
+	"encoding/hex"
+	"github.com/LimeChain/merkletree/merkletreetest"
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+// Transactions of block 100000
+var block100000 = []string{
+	"8c14f0db3df150123e6f3dbbf30f8b955a8249b62ac1d1ff16284aefa3d06d87",
+	"fff2525b8931402dd09222c50775608f75787bd2b87e56995a7bdd30f79702c4",
+	"6359f0868171b1d194cbee1af2f16ea598ae8fad666d9b012c8ed2b79a236ec4",
+	"e9a66845e05d5abc0ad04ec80f774a7e585c6e8db975962d069a522137b80c1d",
+}
+
+const (
+	block100000Root = "f3e94742aca4b5ef85488dc37c06c3282295ffec960994b2c0d5ac2a25a95766"
+	block100000Hash = "000000000003ba27aa200b1cecaad478d2b00432346c3f1f3986da1afd33e506"
+)
+
+// gettxoutproof output for transactions of block 100000 by their indexes. It is the serialized merkleblock message
+// of the block, produced independently of this package with the bloom filtering of btcd
+var block100000Proofs = map[string][]int{
+	"0100000050120119172a610421a6c3011dd330d9df07b63616c2cc1f1cd00200000000006657a9252aacd5c0b2940996ecff952228c3067cc38d4885efb5a4ac4247e9f337221b4d4c86041b0f2b57100400000003876dd0a3ef4a2816ffd1c12ab649825a958b0ff3bb3d6f3e1250f13ddbf0148cc40297f730dd7b5a99567eb8d27b78758f607507c52292d02d4031895b52f2ff49aef42d78e3e9999c9e6ec9e1dddd6cb880bf3b076a03be1318ca789089308e0107":                                                                 {0},
+	"0100000050120119172a610421a6c3011dd330d9df07b63616c2cc1f1cd00200000000006657a9252aacd5c0b2940996ecff952228c3067cc38d4885efb5a4ac4247e9f337221b4d4c86041b0f2b5710040000000315b88c5107195bf09eb9da89b83d95b3d070079a3c5c5d3d17d0dcd873fbdaccc46e239ab7d28e2c019b6d66ad8fae98a56ef1f21aeecb94d1b1718186f059631d0cb83721529a062d9675b98d6e5c587e4a770fc84ed00abc5a5de04568a6e9010d":                                                                 {2},
+	"0100000050120119172a610421a6c3011dd330d9df07b63616c2cc1f1cd00200000000006657a9252aacd5c0b2940996ecff952228c3067cc38d4885efb5a4ac4247e9f337221b4d4c86041b0f2b57100400000004876dd0a3ef4a2816ffd1c12ab649825a958b0ff3bb3d6f3e1250f13ddbf0148cc40297f730dd7b5a99567eb8d27b78758f607507c52292d02d4031895b52f2ffc46e239ab7d28e2c019b6d66ad8fae98a56ef1f21aeecb94d1b1718186f059631d0cb83721529a062d9675b98d6e5c587e4a770fc84ed00abc5a5de04568a6e9015b": {1, 3},
+}
+
+func block100000Header(et *merkletreetest.ExtendedTesting) Header {
+	prev, _ := NewHashFromString("000000000002d01c1fccc21636b607dfd930d31d01c3a62104612a1719011250")
+	root, _ := NewHashFromString(block100000Root)
+	header := Header{Version: 1, PrevBlock: prev, MerkleRoot: root, Time: 1293623863, Bits: 0x1b04864c, Nonce: 274148111}
+	et.Assert(header.Hash().String() == block100000Hash, "Block hash was not correct", header.Hash().String())
+	return header
+}
+
+func block100000Tree() *MerkleTree {
+	tree := NewMerkleTree()
+	for _, txid := range block100000 {
+		tree.Insert(txid)
+	}
+	return tree
+}
+
+func TestHashString(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	hash, err := NewHashFromString(block100000Root)
+	et.Assert(err == nil, "Error was thrown on parsing the hash")
+	et.Assert(hash[0] == 0x66 && hash[31] == 0xf3, "Hash was not in internal byte order")
+	et.Assert(hash.String() == block100000Root, "Hash string was not correct", hash.String())
+
+	_, err = NewHashFromString("f3e9")
+	et.Assert(err != nil, "Short hash did not return error")
+}
+
+func TestRoot(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree := block100000Tree()
+	et.Assert(tree.Root() == block100000Root, "Merkle root was not correct", tree.Root())
+
+	single := NewMerkleTree()
+	single.Insert(block100000[0])
+	et.Assert(single.Root() == block100000[0], "Merkle root of a single transaction was not its txid")
+}
+
+func TestOddDuplication(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree := NewMerkleTree()
+	for _, txid := range block100000[:3] {
+		tree.Insert(txid)
+	}
+	root := tree.Root()
+
+	tree.Insert(block100000[2])
+	et.Assert(tree.Root() == root, "The odd transaction was not paired with itself")
+}
+
+func TestAdd(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree := NewMerkleTree()
+	raw, _ := hex.DecodeString("01000000010000000000000000000000000000000000000000000000000000000000000000ffffffff08044c86041b020602ffffffff0100f2052a010000004341041b0e8c2567c12536aa13357b79a073dc4444acb83c4ec7a0e2f99dd7457516c5817242da796924ca4e99947d087fedf9ce467cb9f7c6287078f801df276fdf84ac00000000")
+	index, txid := tree.Add(raw)
+	et.Assert(index == 0, "The index of first addition was not 0")
+	et.Assert(txid == block100000[0], "The txid was not the double hash of the transaction", txid)
+}
+
+func TestPush(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree := NewMerkleTree()
+
+	index, err := tree.Push(block100000[0])
+	et.Assert(err == nil && index == 0, "Valid txid was not pushed")
+
+	index, err = tree.Push("not hex")
+	et.Assert(err != nil && err.Error() == invalidHash, "The error of invalid txid was not returned")
+	et.Assert(index == -1, "Invalid txid was pushed")
+
+	index, leaf := tree.RawInsert("f3e9")
+	et.Assert(index == -1 && leaf == nil, "Short txid was inserted")
+	et.Assert(tree.Insert("f3e9") == -1, "Short txid was inserted")
+	et.Assert(tree.Length() == 1, "Invalid txids changed the length")
+}
+
+func TestIntermediaryHashesByIndex(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree := block100000Tree()
+	for i, txid := range block100000 {
+		branch, err := tree.IntermediaryHashesByIndex(i)
+		et.Assert(err == nil, "Error was thrown for intermediary hashes", i)
+		et.Assert(len(branch) == 2, "Branch length was not correct", len(branch))
+		et.Assert(Verify(tree.Root(), i, txid, branch), "Branch did not verify", i)
+		et.Assert(!Verify(tree.Root(), i^1, txid, branch), "Branch verified at wrong index", i)
+	}
+
+	_, err := tree.IntermediaryHashesByIndex(4)
+	et.Assert(err != nil, "Out of bounds index did not return error")
+}
+
+func TestTruncate(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree := block100000Tree()
+	tree.Truncate(1)
+	et.Assert(tree.Root() == block100000[0], "Root after truncate was not correct")
+
+	err := tree.Truncate(2)
+	et.Assert(err != nil, "Truncate to bigger size did not return error")
+}
+
+func TestTxOutProof(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	header := block100000Header(et)
+
+	for expected, indexes := range block100000Proofs {
+		matches := make([]bool, len(block100000))
+		for _, i := range indexes {
+			matches[i] = true
+		}
+		block, err := NewMerkleBlock(header, block100000, matches)
+		et.Assert(err == nil, "Error was thrown on creating the merkle block", indexes)
+
+		proof, err := block.TxOutProof()
+		et.Assert(err == nil, "Error was thrown on serializing the proof", indexes)
+		et.Assert(proof == expected, "Proof was not the gettxoutproof output", indexes, proof)
+
+		txids, blockHash, err := VerifyTxOutProof(expected)
+		et.Assert(err == nil, "Error was thrown on verifying the output of gettxoutproof", indexes)
+		et.Assert(blockHash == block100000Hash, "Incorrect block hash was returned", blockHash)
+		et.Assert(len(txids) == len(indexes), "Incorrect count of proven txids", indexes)
+		for i, index := range indexes {
+			et.Assert(txids[i] == block100000[index], "Incorrect proven txid", index)
+		}
+	}
+
+	block, _ := NewMerkleBlock(header, block100000, []bool{true, false, false, false})
+	proof, _ := block.TxOutProof()
+	tampered := []byte(proof)
+	tampered[len(tampered)-1] = '5'
+	_, _, err := VerifyTxOutProof(string(tampered))
+	et.Assert(err != nil, "Proof with wrong flags did not return error")
+
+	header.MerkleRoot, _ = NewHashFromString(block100000[0])
+	block.Header = header
+	proof, _ = block.TxOutProof()
+	_, _, err = VerifyTxOutProof(proof)
+	et.Assert(err != nil, "Proof with wrong merkle root did not return error")
+}
+
+func TestPartialMerkleTree(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	r := rand.New(rand.NewSource(1))
+	for _, n := range []int{1, 4, 7, 17, 56, 100, 127, 256, 312, 513, 1000, 4095} {
+		tree := NewMerkleTree()
+		for i := 0; i < n; i++ {
+			tree.Add([]byte(strconv.Itoa(n) + "-" + strconv.Itoa(i)))
+		}
+
+		for _, ratio := range []int{1, 2, 3, 10, 100, n + 1} {
+			indexes := make([]int, 0)
+			for i := 0; i < n; i++ {
+				if r.Intn(ratio) == 0 {
+					indexes = append(indexes, i)
+				}
+			}
+
+			pmt, err := tree.PartialMerkleTree(indexes...)
+			et.Assert(err == nil, "Error was thrown on creating the partial merkle tree", n, ratio)
+			data, _ := pmt.MarshalBinary()
+			parsed := &PartialMerkleTree{}
+			err = parsed.UnmarshalBinary(data)
+			et.Assert(err == nil, "Error was thrown on parsing the partial merkle tree", n, ratio)
+
+			root, txids, proven, err := parsed.ExtractMatches()
+			et.Assert(err == nil, "Error was thrown on extracting the matches", n, ratio)
+			et.Assert(root == tree.Root(), "Root was not correct", n, ratio)
+			et.Assert(len(proven) == len(indexes), "Count of matches was not correct", n, ratio, len(proven))
+			for i, index := range indexes {
+				txid, _ := tree.HashAt(index)
+				et.Assert(proven[i] == index && txids[i] == txid, "Match was not correct", i, n, ratio)
+			}
+		}
+	}
+}
+
+func TestPartialMerkleTreeDuplicate(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	// Duplicating the last two transactions of an odd block keeps the root but must not verify (CVE-2012-2459)
+	txids := []string{block100000[0], block100000[1], block100000[2], block100000[2]}
+	pmt, err := NewPartialMerkleTree(txids, []bool{false, false, true, true})
+	et.Assert(err == nil, "Error was thrown on creating the partial merkle tree")
+
+	_, _, _, err = pmt.ExtractMatches()
+	et.Assert(err != nil, "Partial merkle tree with identical siblings did not return error")
+}
@@ -0,0 +1,412 @@
+package bitcoin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+)
+
+const (
+	noTransactions     = "Incorrect proof - The block has no transactions"
+	tooManyTxs         = "Incorrect proof - The block has more transactions than fit in a block"
+	tooManyHashes      = "Incorrect proof - The proof has more hashes than transactions"
+	tooFewBits         = "Incorrect proof - The proof has less flag bits than hashes"
+	malformedProof     = "Incorrect proof - The proof does not describe a valid partial merkle tree"
+	duplicateSubtree   = "Incorrect proof - The proof contains identical sibling subtrees"
+	unusedProof        = "Incorrect proof - The proof has unused hashes or flag bits"
+	rootMismatch       = "Incorrect proof - The merkle root does not match the block header"
+	invalidMatches     = "Incorrect matches - The count of matches differs from the count of txids"
+	trailingData       = "Incorrect proof - The proof has trailing data"
+	maxTransactions    = 4000000 / 240 // MAX_BLOCK_WEIGHT / MIN_TRANSACTION_WEIGHT of Bitcoin Core
+	maxCompactSizeSize = 0x02000000
+	headerSize         = 80
+)
+
+// PartialMerkleTree is the partial merkle tree of BIP 37, as used by the merkle blocks of gettxoutproof.
+// It is the depth first traversal of the tree, where Bits flags whether a node is parent of a matched txid
+// and Hashes carries the nodes that are not descended into
+type PartialMerkleTree struct {
+	Transactions uint32
+	Hashes       []Hash
+	Bits         []bool
+}
+
+// width returns the count of the nodes on the given height
+func (pmt *PartialMerkleTree) width(height uint) uint32 {
+	return uint32((uint64(pmt.Transactions) + (1 << height) - 1) >> height)
+}
+
+func (pmt *PartialMerkleTree) height() uint {
+	height := uint(0)
+	for pmt.width(height) > 1 {
+		height++
+	}
+	return height
+}
+
+func (pmt *PartialMerkleTree) calcHash(height uint, pos uint32, txids []Hash) Hash {
+	if height == 0 {
+		return txids[pos]
+	}
+	left := pmt.calcHash(height-1, pos*2, txids)
+	right := left
+	if pos*2+1 < pmt.width(height-1) {
+		right = pmt.calcHash(height-1, pos*2+1, txids)
+	}
+	return hashPair(left, right)
+}
+
+func (pmt *PartialMerkleTree) build(height uint, pos uint32, txids []Hash, matches []bool) {
+	parentOfMatch := false
+	for p := uint64(pos) << height; p < uint64(pos+1)<<height && p < uint64(pmt.Transactions); p++ {
+		parentOfMatch = parentOfMatch || matches[p]
+	}
+	pmt.Bits = append(pmt.Bits, parentOfMatch)
+
+	if height == 0 || !parentOfMatch {
+		pmt.Hashes = append(pmt.Hashes, pmt.calcHash(height, pos, txids))
+		return
+	}
+	pmt.build(height-1, pos*2, txids, matches)
+	if pos*2+1 < pmt.width(height-1) {
+		pmt.build(height-1, pos*2+1, txids, matches)
+	}
+}
+
+// NewPartialMerkleTree builds the partial merkle tree of the block with the given txids that proves the matched ones
+func NewPartialMerkleTree(txids []string, matches []bool) (*PartialMerkleTree, error) {
+	if len(txids) != len(matches) {
+		return nil, errors.New(invalidMatches)
+	}
+	if len(txids) == 0 {
+		return nil, errors.New(noTransactions)
+	}
+
+	hashes := make([]Hash, len(txids))
+	for i, txid := range txids {
+		hash, err := NewHashFromString(txid)
+		if err != nil {
+			return nil, err
+		}
+		hashes[i] = hash
+	}
+
+	pmt := &PartialMerkleTree{Transactions: uint32(len(txids))}
+	pmt.build(pmt.height(), 0, hashes, matches)
+
+	return pmt, nil
+}
+
+// PartialMerkleTree returns the partial merkle tree that proves the transactions at the given indexes
+func (tree *MerkleTree) PartialMerkleTree(indexes ...int) (*PartialMerkleTree, error) {
+	tree.mutex.RLock()
+	defer tree.mutex.RUnlock()
+
+	matches := make([]bool, len(tree.leafs))
+	for _, index := range indexes {
+		if index < 0 || index >= len(tree.leafs) {
+			return nil, errors.New(outOfBounds)
+		}
+		matches[index] = true
+	}
+
+	txids := make([]string, len(tree.leafs))
+	for i, leaf := range tree.leafs {
+		txids[i] = leaf.Hash()
+	}
+
+	return NewPartialMerkleTree(txids, matches)
+}
+
+type extraction struct {
+	pmt      *PartialMerkleTree
+	bitsUsed int
+	hashUsed int
+	matches  []string
+	indexes  []int
+	err      error
+}
+
+func (e *extraction) traverse(height uint, pos uint32) Hash {
+	if e.bitsUsed >= len(e.pmt.Bits) {
+		e.err = errors.New(malformedProof)
+		return Hash{}
+	}
+	parentOfMatch := e.pmt.Bits[e.bitsUsed]
+	e.bitsUsed++
+
+	if height == 0 || !parentOfMatch {
+		if e.hashUsed >= len(e.pmt.Hashes) {
+			e.err = errors.New(malformedProof)
+			return Hash{}
+		}
+		hash := e.pmt.Hashes[e.hashUsed]
+		e.hashUsed++
+		if height == 0 && parentOfMatch {
+			e.matches = append(e.matches, hash.String())
+			e.indexes = append(e.indexes, int(pos))
+		}
+		return hash
+	}
+
+	left := e.traverse(height-1, pos*2)
+	right := left
+	if pos*2+1 < e.pmt.width(height-1) {
+		right = e.traverse(height-1, pos*2+1)
+		if right == left {
+			// Identical siblings allow faking a different transaction list with the same root (CVE-2012-2459)
+			e.err = errors.New(duplicateSubtree)
+		}
+	}
+	return hashPair(left, right)
+}
+
+// ExtractMatches validates the partial merkle tree and returns the merkle root it commits to,
+// together with the matched txids and their indexes in the block
+func (pmt *PartialMerkleTree) ExtractMatches() (root string, txids []string, indexes []int, err error) {
+	if pmt.Transactions == 0 {
+		return "", nil, nil, errors.New(noTransactions)
+	}
+	if pmt.Transactions > maxTransactions {
+		return "", nil, nil, errors.New(tooManyTxs)
+	}
+	if len(pmt.Hashes) > int(pmt.Transactions) {
+		return "", nil, nil, errors.New(tooManyHashes)
+	}
+	if len(pmt.Bits) < len(pmt.Hashes) {
+		return "", nil, nil, errors.New(tooFewBits)
+	}
+
+	e := &extraction{pmt: pmt, matches: make([]string, 0), indexes: make([]int, 0)}
+	hash := e.traverse(pmt.height(), 0)
+	if e.err != nil {
+		return "", nil, nil, e.err
+	}
+	// Only the padding of the last byte may be left unused
+	if (e.bitsUsed+7)/8 != (len(pmt.Bits)+7)/8 || e.hashUsed != len(pmt.Hashes) {
+		return "", nil, nil, errors.New(unusedProof)
+	}
+
+	return hash.String(), e.matches, e.indexes, nil
+}
+
+func writeCompactSize(w *bytes.Buffer, n uint64) {
+	var buf [9]byte
+	switch {
+	case n < 0xfd:
+		w.WriteByte(byte(n))
+	case n <= 0xffff:
+		buf[0] = 0xfd
+		binary.LittleEndian.PutUint16(buf[1:], uint16(n))
+		w.Write(buf[:3])
+	case n <= 0xffffffff:
+		buf[0] = 0xfe
+		binary.LittleEndian.PutUint32(buf[1:], uint32(n))
+		w.Write(buf[:5])
+	default:
+		buf[0] = 0xff
+		binary.LittleEndian.PutUint64(buf[1:], n)
+		w.Write(buf[:9])
+	}
+}
+
+func readCompactSize(r *bytes.Reader) (uint64, error) {
+	prefix, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+
+	var n uint64
+	switch prefix {
+	case 0xfd:
+		var v uint16
+		err = binary.Read(r, binary.LittleEndian, &v)
+		n = uint64(v)
+	case 0xfe:
+		var v uint32
+		err = binary.Read(r, binary.LittleEndian, &v)
+		n = uint64(v)
+	case 0xff:
+		err = binary.Read(r, binary.LittleEndian, &n)
+	default:
+		n = uint64(prefix)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if n > maxCompactSizeSize {
+		return 0, errors.New(malformedProof)
+	}
+	return n, nil
+}
+
+// MarshalBinary serializes the partial merkle tree the way Bitcoin Core does -
+// the count of transactions, the hashes and the flag bits packed into bytes
+func (pmt *PartialMerkleTree) MarshalBinary() ([]byte, error) {
+	w := &bytes.Buffer{}
+	binary.Write(w, binary.LittleEndian, pmt.Transactions)
+
+	writeCompactSize(w, uint64(len(pmt.Hashes)))
+	for _, hash := range pmt.Hashes {
+		w.Write(hash[:])
+	}
+
+	flags := make([]byte, (len(pmt.Bits)+7)/8)
+	for i, bit := range pmt.Bits {
+		if bit {
+			flags[i/8] |= 1 << uint(i%8)
+		}
+	}
+	writeCompactSize(w, uint64(len(flags)))
+	w.Write(flags)
+
+	return w.Bytes(), nil
+}
+
+func (pmt *PartialMerkleTree) read(r *bytes.Reader) error {
+	if err := binary.Read(r, binary.LittleEndian, &pmt.Transactions); err != nil {
+		return err
+	}
+
+	count, err := readCompactSize(r)
+	if err != nil {
+		return err
+	}
+	if count > uint64(r.Len())/32 {
+		return io.ErrUnexpectedEOF
+	}
+	pmt.Hashes = make([]Hash, count)
+	for i := range pmt.Hashes {
+		if _, err := io.ReadFull(r, pmt.Hashes[i][:]); err != nil {
+			return err
+		}
+	}
+
+	count, err = readCompactSize(r)
+	if err != nil {
+		return err
+	}
+	flags := make([]byte, count)
+	if _, err := io.ReadFull(r, flags); err != nil {
+		return err
+	}
+	pmt.Bits = make([]bool, len(flags)*8)
+	for i := range pmt.Bits {
+		pmt.Bits[i] = flags[i/8]&(1<<uint(i%8)) != 0
+	}
+
+	return nil
+}
+
+// UnmarshalBinary parses partial merkle tree serialized the way Bitcoin Core does
+func (pmt *PartialMerkleTree) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+	if err := pmt.read(r); err != nil {
+		return err
+	}
+	if r.Len() != 0 {
+		return errors.New(trailingData)
+	}
+	return nil
+}
+
+// Header is the 80 byte header of a Bitcoin block. The hashes are in internal byte order
+type Header struct {
+	Version    int32
+	PrevBlock  Hash
+	MerkleRoot Hash
+	Time       uint32
+	Bits       uint32
+	Nonce      uint32
+}
+
+// Hash returns the hash of the block
+func (header *Header) Hash() Hash {
+	data, _ := header.MarshalBinary()
+	return DoubleHash(data)
+}
+
+// MarshalBinary serializes the header the way it is hashed
+func (header *Header) MarshalBinary() ([]byte, error) {
+	w := &bytes.Buffer{}
+	binary.Write(w, binary.LittleEndian, header)
+	return w.Bytes(), nil
+}
+
+// MerkleBlock is the header of a block together with a partial merkle tree of its transactions.
+// Its serialization is the proof returned by gettxoutproof and accepted by verifytxoutproof
+type MerkleBlock struct {
+	Header Header
+	Tree   *PartialMerkleTree
+}
+
+// NewMerkleBlock returns a merkle block that proves the matched txids of the block with the given header
+func NewMerkleBlock(header Header, txids []string, matches []bool) (*MerkleBlock, error) {
+	pmt, err := NewPartialMerkleTree(txids, matches)
+	if err != nil {
+		return nil, err
+	}
+	return &MerkleBlock{header, pmt}, nil
+}
+
+// MarshalBinary serializes the merkle block the way Bitcoin Core does
+func (block *MerkleBlock) MarshalBinary() ([]byte, error) {
+	header, _ := block.Header.MarshalBinary()
+	pmt, err := block.Tree.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return append(header, pmt...), nil
+}
+
+// UnmarshalBinary parses merkle block serialized the way Bitcoin Core does
+func (block *MerkleBlock) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+	if err := binary.Read(r, binary.LittleEndian, &block.Header); err != nil {
+		return err
+	}
+	block.Tree = &PartialMerkleTree{}
+	if err := block.Tree.read(r); err != nil {
+		return err
+	}
+	if r.Len() != 0 {
+		return errors.New(trailingData)
+	}
+	return nil
+}
+
+// TxOutProof returns the hex serialized merkle block in the format of gettxoutproof
+func (block *MerkleBlock) TxOutProof() (string, error) {
+	data, err := block.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(data), nil
+}
+
+// VerifyTxOutProof emulates verifytxoutproof. It parses the hex serialized merkle block, validates that the partial
+// merkle tree commits to the merkle root of the header and returns the proven txids and the hash of the block.
+// The caller still has to check that the block is in the best chain
+func VerifyTxOutProof(proof string) (txids []string, blockHash string, err error) {
+	data, err := hex.DecodeString(proof)
+	if err != nil {
+		return nil, "", err
+	}
+
+	block := &MerkleBlock{}
+	if err := block.UnmarshalBinary(data); err != nil {
+		return nil, "", err
+	}
+
+	root, txids, _, err := block.Tree.ExtractMatches()
+	if err != nil {
+		return nil, "", err
+	}
+	if root != block.Header.MerkleRoot.String() {
+		return nil, "", errors.New(rootMismatch)
+	}
+
+	return txids, block.Header.Hash().String(), nil
+}
@@ -0,0 +1,221 @@
+// Package distributor builds the merkle trees of token airdrops in the style of the Uniswap MerkleDistributor.
+// Every claim is the leaf keccak256(abi.encodePacked(uint256 index, address account, uint256 amount)) and the
+// distribution is published in the claims JSON of the Uniswap tooling. The leafs are not the leafs of the OpenZeppelin
+// standard tree, which hashes abi.encode of the values twice.
+//
+// The tree is memory.MerkleTree, so siblings are paired by position and the odd node is paired with itself instead of
+// the sorted pairs of OpenZeppelin's MerkleProof. The proofs can NOT be verified by the Uniswap MerkleDistributor
+// contract, which uses MerkleProof. Contracts verify the claims with the library generated by
+// solidity.GenerateVerifier in Memory mode, passing the index of the claim as the index of the leaf
+package distributor
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/LimeChain/merkletree/memory"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"io"
+	"math/big"
+	"strings"
+)
+
+const (
+	noBalances       = "Incorrect balances - Expected at least one account"
+	invalidAccount   = "Incorrect account - The account is not a hex address"
+	invalidAmount    = "Incorrect amount - The amount must be a positive integer that fits in 256 bits"
+	duplicateAccount = "Incorrect account - The account is listed more than once"
+	invalidRecord    = "Incorrect record - Expected account and amount"
+	claimNotFound    = "Incorrect account - The account has no claim"
+)
+
+// ProofFormat names how the proofs of the claims are verified - by position, as in memory.Verify, and not by sorted pairs
+const ProofFormat = "positional"
+
+// Balance is the amount of tokens an account can claim
+type Balance struct {
+	Account common.Address
+	Amount  *big.Int
+}
+
+// Claim is the entry of an account in the claims JSON. The amount is hex encoded
+type Claim struct {
+	Index  int      `json:"index"`
+	Amount string   `json:"amount"`
+	Proof  []string `json:"proof"`
+}
+
+// Distribution is the claims JSON - the root of the tree, the hex encoded sum of all amounts and the claims by account
+type Distribution struct {
+	MerkleRoot string           `json:"merkleRoot"`
+	TokenTotal string           `json:"tokenTotal"`
+	Claims     map[string]Claim `json:"claims"`
+}
+
+// Distributor holds the tree of the claims. The index of every claim is the position of its account in the balances
+type Distributor struct {
+	tree     *memory.MerkleTree
+	balances []Balance
+	indexes  map[common.Address]int
+	total    *big.Int
+}
+
+// LeafHash returns keccak256(abi.encodePacked(uint256 index, address account, uint256 amount))
+func LeafHash(index int, account common.Address, amount *big.Int) common.Hash {
+	return crypto.Keccak256Hash(math.PaddedBigBytes(big.NewInt(int64(index)), 32), account[:], math.PaddedBigBytes(amount, 32))
+}
+
+func validAmount(amount *big.Int) bool {
+	return amount != nil && amount.Sign() > 0 && amount.BitLen() <= 256
+}
+
+// ReadCSV reads balances from CSV records of account and amount. Amounts are decimal or 0x prefixed hex.
+// A first record that is not a balance is treated as header
+func ReadCSV(r io.Reader) ([]Balance, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	balances := make([]Balance, 0, len(records))
+	for i, record := range records {
+		if len(record) != 2 {
+			return nil, errors.New(invalidRecord)
+		}
+		account := strings.TrimSpace(record[0])
+		if !common.IsHexAddress(account) {
+			if i == 0 {
+				continue // Header
+			}
+			return nil, fmt.Errorf("%v: %v", invalidAccount, account)
+		}
+
+		amount, ok := parseAmount(strings.TrimSpace(record[1]))
+		if !ok {
+			return nil, fmt.Errorf("%v: %v", invalidAmount, record[1])
+		}
+		balances = append(balances, Balance{common.HexToAddress(account), amount})
+	}
+
+	return balances, nil
+}
+
+func parseAmount(s string) (*big.Int, bool) {
+	if strings.HasPrefix(s, "0x") {
+		return new(big.Int).SetString(s[2:], 16)
+	}
+	return new(big.Int).SetString(s, 10)
+}
+
+// New builds the tree of the claims of the balances
+func New(balances []Balance) (*Distributor, error) {
+	if len(balances) == 0 {
+		return nil, errors.New(noBalances)
+	}
+
+	d := &Distributor{
+		tree:     memory.NewMerkleTree(),
+		balances: make([]Balance, len(balances)),
+		indexes:  make(map[common.Address]int, len(balances)),
+		total:    new(big.Int),
+	}
+	for i, balance := range balances {
+		if !validAmount(balance.Amount) {
+			return nil, fmt.Errorf("%v: %v", invalidAmount, balance.Amount)
+		}
+		if _, ok := d.indexes[balance.Account]; ok {
+			return nil, fmt.Errorf("%v: %v", duplicateAccount, balance.Account.Hex())
+		}
+
+		d.balances[i] = Balance{balance.Account, new(big.Int).Set(balance.Amount)}
+		d.indexes[balance.Account] = i
+		d.total.Add(d.total, balance.Amount)
+		d.tree.RawInsert(LeafHash(i, balance.Account, balance.Amount).Hex())
+	}
+	if d.total.BitLen() > 256 {
+		return nil, errors.New(invalidAmount)
+	}
+	d.tree.Recalculate()
+
+	return d, nil
+}
+
+// Root returns the merkle root of the claims
+func (d *Distributor) Root() string {
+	return d.tree.Root()
+}
+
+// Total returns the sum of all amounts
+func (d *Distributor) Total() *big.Int {
+	return new(big.Int).Set(d.total)
+}
+
+// Length returns the count of the claims
+func (d *Distributor) Length() int {
+	return len(d.balances)
+}
+
+func (d *Distributor) claim(index int) (Claim, error) {
+	proof, err := d.tree.IntermediaryHashesByIndex(index)
+	if err != nil {
+		return Claim{}, err
+	}
+	return Claim{index, hexutil.EncodeBig(d.balances[index].Amount), proof}, nil
+}
+
+// Claim returns the claim of the account
+func (d *Distributor) Claim(account common.Address) (*Claim, error) {
+	index, ok := d.indexes[account]
+	if !ok {
+		return nil, errors.New(claimNotFound)
+	}
+	claim, err := d.claim(index)
+	if err != nil {
+		return nil, err
+	}
+	return &claim, nil
+}
+
+// Distribution returns the claims JSON of all accounts
+func (d *Distributor) Distribution() (*Distribution, error) {
+	distribution := &Distribution{
+		MerkleRoot: d.Root(),
+		TokenTotal: hexutil.EncodeBig(d.total),
+		Claims:     make(map[string]Claim, len(d.balances)),
+	}
+	for i, balance := range d.balances {
+		claim, err := d.claim(i)
+		if err != nil {
+			return nil, err
+		}
+		distribution.Claims[balance.Account.Hex()] = claim
+	}
+	return distribution, nil
+}
+
+// WriteJSON writes the claims JSON of all accounts
+func (d *Distributor) WriteJSON(w io.Writer) error {
+	distribution, err := d.Distribution()
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(distribution)
+}
+
+// VerifyClaim emulates how the distributor contract validates a claim of the account against the merkle root
+func VerifyClaim(root string, account common.Address, claim *Claim) bool {
+	amount, err := hexutil.DecodeBig(claim.Amount)
+	if err != nil || !validAmount(amount) || claim.Index < 0 {
+		return false
+	}
+	return memory.Verify(root, claim.Index, LeafHash(claim.Index, account, amount).Hex(), claim.Proof)
+}
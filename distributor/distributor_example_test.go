@@ -0,0 +1,29 @@
+package distributor_test
+
+import (
+	"fmt"
+	"github.com/LimeChain/merkletree/distributor"
+	"github.com/ethereum/go-ethereum/common"
+	"os"
+	"strings"
+)
+
+func Example() {
+	balances, err := distributor.ReadCSV(strings.NewReader("account,amount\n" +
+		"0x1111111111111111111111111111111111111111,100\n" +
+		"0x2222222222222222222222222222222222222222,0xc8\n"))
+	if err != nil {
+		panic(err)
+	}
+	d, err := distributor.New(balances)
+	if err != nil {
+		panic(err)
+	}
+
+	account := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	claim, _ := d.Claim(account)
+	fmt.Println(claim.Index, claim.Amount, distributor.VerifyClaim(d.Root(), account, claim))
+	fmt.Println(d.Total())
+
+	d.WriteJSON(os.Stdout) // The claims JSON of all accounts
+}
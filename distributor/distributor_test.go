@@ -0,0 +1,153 @@
+package distributor
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/LimeChain/merkletree/merkletreetest"
+	"github.com/LimeChain/merkletree/standard"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+const balancesCSV = `account,amount
+0x1111111111111111111111111111111111111111,100
+0x2222222222222222222222222222222222222222, 0xc8
+0x3333333333333333333333333333333333333333,300
+`
+
+// verifySorted emulates MerkleProof of OpenZeppelin, used by the Uniswap MerkleDistributor, which hashes sorted pairs
+func verifySorted(root string, leaf common.Hash, proof []string) bool {
+	hash := leaf
+	for _, p := range proof {
+		sibling := common.HexToHash(p)
+		if bytes.Compare(hash[:], sibling[:]) < 0 {
+			hash = crypto.Keccak256Hash(hash[:], sibling[:])
+		} else {
+			hash = crypto.Keccak256Hash(sibling[:], hash[:])
+		}
+	}
+	return hash == common.HexToHash(root)
+}
+
+func TestLeafHash(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	account := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	packed := common.FromHex("0x0000000000000000000000000000000000000000000000000000000000000005" +
+		"1111111111111111111111111111111111111111" +
+		"0000000000000000000000000000000000000000000000000000000000000064")
+	et.Assert(LeafHash(5, account, big.NewInt(100)) == crypto.Keccak256Hash(packed), "Leaf was not keccak256 of the packed index, account and amount")
+
+	standardLeaf, err := standard.LeafHash([]string{"uint256", "address", "uint256"}, []interface{}{5, account, big.NewInt(100)})
+	et.Assert(err == nil, "Error was thrown on the leaf of the standard tree")
+	et.Assert(standardLeaf != LeafHash(5, account, big.NewInt(100)).Hex(), "Leaf was the leaf of the standard tree")
+}
+
+func TestReadCSV(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	balances, err := ReadCSV(strings.NewReader(balancesCSV))
+	if err != nil {
+		et.Fatal(err)
+	}
+	et.Assert(len(balances) == 3 && balances[1].Amount.Int64() == 200 && balances[2].Account == common.HexToAddress("0x3333333333333333333333333333333333333333"),
+		"Balances were not correct", balances)
+
+	invalid := []string{
+		"0x1111111111111111111111111111111111111111,abc\n",
+		"0x1111111111111111111111111111111111111111,100\n0x22,100\n",
+		"0x1111111111111111111111111111111111111111\n",
+	}
+	for i, data := range invalid {
+		_, err := ReadCSV(strings.NewReader(data))
+		et.Assert(err != nil, "Invalid CSV did not return error", i)
+	}
+}
+
+func TestNew(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	account := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	invalid := [][]Balance{
+		{},
+		{{account, big.NewInt(0)}},
+		{{account, big.NewInt(-1)}},
+		{{account, big.NewInt(1)}, {account, big.NewInt(2)}},
+	}
+	for i, balances := range invalid {
+		_, err := New(balances)
+		et.Assert(err != nil, "Invalid balances did not return error", i)
+	}
+}
+
+func TestDistribution(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	balances, _ := ReadCSV(strings.NewReader(balancesCSV))
+	d, err := New(balances)
+	if err != nil {
+		et.Fatal(err)
+	}
+
+	b := bytes.Buffer{}
+	if err := d.WriteJSON(&b); err != nil {
+		et.Fatal(err)
+	}
+	distribution := Distribution{}
+	if err := json.Unmarshal(b.Bytes(), &distribution); err != nil {
+		et.Fatal(err)
+	}
+
+	et.Assert(distribution.MerkleRoot == d.Root() && distribution.TokenTotal == "0x258" && len(distribution.Claims) == 3,
+		"Distribution was not correct", b.String())
+
+	for _, balance := range balances {
+		claim, ok := distribution.Claims[balance.Account.Hex()]
+		if !ok {
+			et.Fatal("Claim was missing", balance.Account.Hex())
+		}
+		et.Assert(VerifyClaim(distribution.MerkleRoot, balance.Account, &claim), "Claim did not verify", balance.Account.Hex())
+
+		claim.Amount = "0x1"
+		et.Assert(!VerifyClaim(distribution.MerkleRoot, balance.Account, &claim), "Claim with wrong amount verified", balance.Account.Hex())
+	}
+
+	claim, _ := d.Claim(balances[0].Account)
+	et.Assert(!VerifyClaim(d.Root(), balances[1].Account, claim), "Claim verified for another account")
+	_, err = d.Claim(common.HexToAddress("0x4444444444444444444444444444444444444444"))
+	et.Assert(err != nil, "Claim of unknown account did not return error")
+}
+
+// TestSortedPairs pins down that the positional proofs are not the proofs the Uniswap MerkleDistributor verifies
+func TestSortedPairs(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	balances := make([]Balance, 16)
+	for i := range balances {
+		balances[i] = Balance{common.BigToAddress(big.NewInt(int64(i + 1))), big.NewInt(int64(i+1) * 100)}
+	}
+	d, err := New(balances)
+	if err != nil {
+		et.Fatal(err)
+	}
+
+	verified := 0
+	for i, balance := range balances {
+		claim, _ := d.Claim(balance.Account)
+		et.Assert(VerifyClaim(d.Root(), balance.Account, claim), "Claim did not verify by position", i)
+		if verifySorted(d.Root(), LeafHash(i, balance.Account, balance.Amount), claim.Proof) {
+			verified++
+		}
+	}
+	et.Assert(verified < len(balances), "All claims verified with sorted pairs", verified)
+}
+
+func TestSingleClaim(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	account := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	d, err := New([]Balance{{account, big.NewInt(100)}})
+	if err != nil {
+		et.Fatal(err)
+	}
+	et.Assert(d.Root() == LeafHash(0, account, big.NewInt(100)).Hex(), "Root of single claim was not its leaf")
+	claim, _ := d.Claim(account)
+	et.Assert(VerifyClaim(d.Root(), account, claim), "Single claim did not verify")
+}
@@ -0,0 +1,144 @@
+package encoder
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"math/big"
+	"strings"
+)
+
+const (
+	unsupportedABIType = "Incorrect type - Only address, bool, string, bytes, bytesN, uintN and intN are supported"
+	wrongValueCount    = "Incorrect values - The count of values does not match the count of types"
+)
+
+// ABIPayload is the JSON payload of the abi-packed encoding. Integers are decimal or 0x prefixed hex strings or JSON numbers,
+// addresses and byte values are 0x prefixed hex
+type ABIPayload struct {
+	Types  []string      `json:"types"`
+	Values []interface{} `json:"values"`
+}
+
+func invalidABIValue(t string, v interface{}) error {
+	return fmt.Errorf("Incorrect value - %v is not a valid %v", v, t)
+}
+
+func encodeABIPacked(payload []byte) ([]byte, error) {
+	d := json.NewDecoder(bytes.NewReader(payload))
+	d.UseNumber()
+
+	var p ABIPayload
+	if err := d.Decode(&p); err != nil {
+		return nil, err
+	}
+	return EncodePacked(p.Types, p.Values)
+}
+
+// EncodePacked returns abi.encodePacked of the values of the given elementary types. Unlike abi.encode the values are not
+// padded to 32 bytes and the dynamic values are not length prefixed
+func EncodePacked(types []string, values []interface{}) ([]byte, error) {
+	if len(types) != len(values) {
+		return nil, errors.New(wrongValueCount)
+	}
+
+	b := bytes.Buffer{}
+	for i, t := range types {
+		typ, err := abi.NewType(t, "", nil)
+		if err != nil {
+			return nil, err
+		}
+		packed, err := packValue(typ, values[i])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(packed)
+	}
+	return b.Bytes(), nil
+}
+
+func packValue(typ abi.Type, v interface{}) ([]byte, error) {
+	t := typ.String()
+	switch typ.T {
+	case abi.AddressTy:
+		s, ok := v.(string)
+		if !ok || !common.IsHexAddress(s) || !strings.HasPrefix(s, "0x") {
+			return nil, invalidABIValue(t, v)
+		}
+		return common.HexToAddress(s).Bytes(), nil
+
+	case abi.BoolTy:
+		b, ok := v.(bool)
+		if !ok {
+			return nil, invalidABIValue(t, v)
+		}
+		if b {
+			return []byte{1}, nil
+		}
+		return []byte{0}, nil
+
+	case abi.StringTy:
+		s, ok := v.(string)
+		if !ok {
+			return nil, invalidABIValue(t, v)
+		}
+		return []byte(s), nil
+
+	case abi.BytesTy, abi.FixedBytesTy:
+		s, ok := v.(string)
+		if !ok {
+			return nil, invalidABIValue(t, v)
+		}
+		b, err := hexutil.Decode(s)
+		if err != nil || (typ.T == abi.FixedBytesTy && len(b) != typ.Size) {
+			return nil, invalidABIValue(t, v)
+		}
+		return b, nil
+
+	case abi.UintTy, abi.IntTy:
+		i, ok := toInteger(v)
+		if !ok {
+			return nil, invalidABIValue(t, v)
+		}
+		min, max := new(big.Int), new(big.Int).Lsh(big.NewInt(1), uint(typ.Size))
+		if typ.T == abi.IntTy {
+			max.Rsh(max, 1)
+			min.Neg(max)
+		}
+		if i.Cmp(min) < 0 || i.Cmp(max) >= 0 {
+			return nil, invalidABIValue(t, v)
+		}
+		if i.Sign() < 0 {
+			i.Add(i, new(big.Int).Lsh(big.NewInt(1), uint(typ.Size))) // Two's complement
+		}
+		return math.PaddedBigBytes(i, typ.Size/8), nil
+	}
+	return nil, errors.New(unsupportedABIType)
+}
+
+func toInteger(v interface{}) (*big.Int, bool) {
+	switch value := v.(type) {
+	case string:
+		if strings.HasPrefix(value, "0x") || strings.HasPrefix(value, "0X") {
+			return new(big.Int).SetString(value[2:], 16)
+		}
+		return new(big.Int).SetString(value, 10)
+	case json.Number:
+		return new(big.Int).SetString(value.String(), 10)
+	case *big.Int:
+		if value == nil {
+			return nil, false
+		}
+		return new(big.Int).Set(value), true
+	case int:
+		return big.NewInt(int64(value)), true
+	case int64:
+		return big.NewInt(value), true
+	}
+	return nil, false
+}
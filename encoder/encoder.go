@@ -0,0 +1,43 @@
+// Package encoder canonicalizes structured leaf data before it is hashed, so semantically equal values produce the same leaf.
+// Payloads are given as JSON and are encoded with one of the supported encodings - RFC 8785 canonical JSON, Solidity
+// abi.encodePacked tuples or deterministic protobuf
+package encoder
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	// JSON is the name of the RFC 8785 JSON Canonicalization Scheme encoding
+	JSON = "jcs"
+	// ABIPacked is the name of the Solidity abi.encodePacked encoding
+	ABIPacked = "abi-packed"
+	// Protobuf is the name of the deterministic protobuf encoding
+	Protobuf = "protobuf"
+)
+
+const (
+	missingPayload = "Incorrect payload - Expected payload to encode"
+)
+
+// Encoder converts JSON payload into the canonical bytes of its encoding
+type Encoder func(payload []byte) ([]byte, error)
+
+var encoders = map[string]Encoder{
+	JSON:      CanonicalJSON,
+	ABIPacked: encodeABIPacked,
+	Protobuf:  encodeProtobuf,
+}
+
+// Encode returns the canonical bytes of the JSON payload in the encoding with the given name
+func Encode(encoding string, payload []byte) ([]byte, error) {
+	encoder, ok := encoders[encoding]
+	if !ok {
+		return nil, fmt.Errorf("Incorrect encoding - Unknown encoding %v", encoding)
+	}
+	if len(payload) == 0 {
+		return nil, errors.New(missingPayload)
+	}
+	return encoder(payload)
+}
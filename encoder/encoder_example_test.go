@@ -0,0 +1,27 @@
+package encoder_test
+
+import (
+	"fmt"
+	"github.com/LimeChain/merkletree/encoder"
+	"github.com/LimeChain/merkletree/memory"
+)
+
+func Example() {
+	tree := memory.NewMerkleTree()
+
+	data, _ := encoder.Encode(encoder.JSON, []byte(`{ "name": "Alice", "age": 30.0 }`))
+	fmt.Println(string(data))
+	_, h1 := tree.Add(data)
+
+	data, _ = encoder.Encode(encoder.JSON, []byte(`{"age":30,"name":"Alice"}`))
+	_, h2 := tree.Add(data)
+	fmt.Println(h1 == h2)
+
+	data, _ = encoder.Encode(encoder.ABIPacked, []byte(`{"types": ["string", "uint8"], "values": ["Alice", 30]}`))
+	fmt.Printf("%x\n", data)
+
+	// Output:
+	// {"age":30,"name":"Alice"}
+	// true
+	// 416c6963651e
+}
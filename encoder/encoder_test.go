@@ -0,0 +1,111 @@
+package encoder
+
+import (
+	"encoding/hex"
+	"github.com/LimeChain/merkletree/merkletreetest"
+	"github.com/ethereum/go-ethereum/crypto"
+	"testing"
+)
+
+func TestCanonicalJSON(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+
+	// RFC 8785 section 3.2.2
+	input := `{
+  "numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+  "string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+  "literals": [null, true, false]
+}`
+	expected := "{\"literals\":[null,true,false],\"numbers\":[333333333.3333333,1e+30,4.5,0.002,1e-27]," +
+		"\"string\":\"\u20ac$\\u000f\\nA'B\\\"\\\\\\\\\\\"/\"}"
+	c, err := CanonicalJSON([]byte(input))
+	et.Assert(err == nil, "Error was thrown on canonicalizing JSON")
+	et.Assert(string(c) == expected, "The canonical JSON was not correct: "+string(c))
+
+	// RFC 8785 section 3.2.3
+	input = `{"\u20ac": "Euro Sign", "\r": "Carriage Return", "\ufb33": "Hebrew Letter Dalet With Dagesh", "1": "One",
+"\ud83d\ude00": "Emoji: Grinning Face", "\u0080": "Control", "\u00f6": "Latin Small Letter O With Diaeresis"}`
+	expected = "{\"\\r\":\"Carriage Return\",\"1\":\"One\",\"\u0080\":\"Control\",\"\u00f6\":\"Latin Small Letter O With Diaeresis\"," +
+		"\"\u20ac\":\"Euro Sign\",\"\U0001f600\":\"Emoji: Grinning Face\",\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}"
+	c, err = CanonicalJSON([]byte(input))
+	et.Assert(err == nil, "Error was thrown on canonicalizing JSON")
+	et.Assert(string(c) == expected, "The members were not sorted by UTF-16 code units: "+string(c))
+
+	a, _ := CanonicalJSON([]byte(`{"b": {"y": 1.0, "x": [1, "2"]}, "a": -0}`))
+	b, _ := CanonicalJSON([]byte(`{"a":0,"b":{"x":[1,"2"],"y":1}}`))
+	et.Assert(string(a) == string(b), "Semantically equal documents were not canonicalized equally")
+
+	numbers := map[string]string{
+		"1e21":     "1e+21",
+		"1e20":     "100000000000000000000",
+		"123e-9":   "1.23e-7",
+		"0.000001": "0.000001",
+		"-1.5e300": "-1.5e+300",
+		"5e-324":   "5e-324",
+	}
+	for in, out := range numbers {
+		c, err := CanonicalJSON([]byte(in))
+		et.Assert(err == nil && string(c) == out, "The number "+in+" was not serialized as "+out+": "+string(c))
+	}
+
+	_, err = CanonicalJSON([]byte(`{"a": 1, "a": 2}`))
+	et.Assert(err != nil, "Error was not thrown on duplicate key")
+	_, err = CanonicalJSON([]byte(`1e400`))
+	et.Assert(err != nil, "Error was not thrown on number out of range")
+	_, err = CanonicalJSON([]byte(`{} {}`))
+	et.Assert(err != nil && err.Error() == trailingData, "Error was not thrown on trailing data")
+	_, err = CanonicalJSON([]byte(`{"a": }`))
+	et.Assert(err != nil, "Error was not thrown on invalid JSON")
+}
+
+func TestEncodePacked(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+
+	// Example of the Solidity documentation
+	packed, err := EncodePacked([]string{"int8", "bytes1", "uint16", "string"}, []interface{}{-1, "0x42", "0x03", "Hello, world!"})
+	et.Assert(err == nil, "Error was thrown on packing values")
+	et.Assert(hex.EncodeToString(packed) == "ff42000348656c6c6f2c20776f726c6421", "The packed values were not correct")
+
+	packed, err = Encode(ABIPacked, []byte(`{"types": ["address", "uint256", "bool"],
+"values": ["0x1111111111111111111111111111111111111111", 100, true]}`))
+	et.Assert(err == nil, "Error was thrown on encoding abi-packed payload")
+	et.Assert(hex.EncodeToString(packed) == "1111111111111111111111111111111111111111"+
+		"0000000000000000000000000000000000000000000000000000000000000064"+"01", "The encoded payload was not correct")
+
+	_, err = EncodePacked([]string{"uint8"}, []interface{}{256})
+	et.Assert(err != nil, "Error was not thrown on integer out of range")
+	_, err = EncodePacked([]string{"bytes2"}, []interface{}{"0x42"})
+	et.Assert(err != nil, "Error was not thrown on bytes of wrong size")
+	_, err = EncodePacked([]string{"uint256[]"}, []interface{}{"1"})
+	et.Assert(err != nil && err.Error() == unsupportedABIType, "Error was not thrown on array type")
+	_, err = EncodePacked([]string{"uint256", "bool"}, []interface{}{"1"})
+	et.Assert(err != nil && err.Error() == wrongValueCount, "Error was not thrown on missing value")
+}
+
+func TestEncodeProtobuf(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+
+	a, err := Encode(Protobuf, []byte(`{"type": "google.protobuf.Struct", "message": {"name": "Alice", "age": 30, "tags": ["a", "b"]}}`))
+	et.Assert(err == nil, "Error was thrown on encoding protobuf payload")
+	b, err := Encode(Protobuf, []byte(`{"type": "google.protobuf.Struct", "message": {"tags": ["a", "b"], "age": 30.0, "name": "Alice"}}`))
+	et.Assert(err == nil, "Error was thrown on encoding protobuf payload")
+	et.Assert(hex.EncodeToString(a) == hex.EncodeToString(b), "Equal messages were not encoded deterministically")
+
+	_, err = Encode(Protobuf, []byte(`{"type": "unknown.Message", "message": {}}`))
+	et.Assert(err != nil, "Error was not thrown on unknown message type")
+	_, err = Encode(Protobuf, []byte(`{"message": {}}`))
+	et.Assert(err != nil && err.Error() == missingMessage, "Error was not thrown on missing type")
+}
+
+func TestEncode(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+
+	a, _ := Encode(JSON, []byte(`{"b": 2, "a": 1}`))
+	b, _ := Encode(JSON, []byte(`{ "a": 1, "b": 2 }`))
+	et.Assert(crypto.Keccak256Hash(a) == crypto.Keccak256Hash(b), "Semantically equal payloads did not produce the same leaf")
+
+	_, err := Encode("xml", []byte(`<a/>`))
+	et.Assert(err != nil, "Error was not thrown on unknown encoding")
+	_, err = Encode(JSON, nil)
+	et.Assert(err != nil && err.Error() == missingPayload, "Error was not thrown on missing payload")
+}
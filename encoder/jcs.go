@@ -0,0 +1,206 @@
+package encoder
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+const (
+	duplicateKey   = "Incorrect JSON - Duplicate object key"
+	invalidNumber  = "Incorrect JSON - The number is not a finite double"
+	trailingData   = "Incorrect JSON - Unexpected data after the top level value"
+	hexDigits      = "0123456789abcdef"
+	maxFixedDigits = 21
+)
+
+// CanonicalJSON returns the RFC 8785 canonical form of the JSON data - object members sorted by the UTF-16 code units
+// of their keys, no whitespace, numbers serialized as ECMAScript doubles and strings with the minimal escaping
+func CanonicalJSON(data []byte) ([]byte, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+
+	b := bytes.Buffer{}
+	if err := canonicalize(d, &b); err != nil {
+		return nil, err
+	}
+	if _, err := d.Token(); err != io.EOF {
+		return nil, errors.New(trailingData)
+	}
+	return b.Bytes(), nil
+}
+
+type member struct {
+	key   []uint16
+	value []byte
+}
+
+func canonicalize(d *json.Decoder, b *bytes.Buffer) error {
+	t, err := d.Token()
+	if err != nil {
+		return err
+	}
+
+	switch v := t.(type) {
+	case json.Delim:
+		if v == '[' {
+			b.WriteByte('[')
+			for i := 0; d.More(); i++ {
+				if i > 0 {
+					b.WriteByte(',')
+				}
+				if err := canonicalize(d, b); err != nil {
+					return err
+				}
+			}
+			b.WriteByte(']')
+		} else {
+			if err := canonicalizeObject(d, b); err != nil {
+				return err
+			}
+		}
+		_, err = d.Token() // Closing delimiter
+		return err
+	case string:
+		writeString(b, v)
+	case json.Number:
+		s, err := formatNumber(v)
+		if err != nil {
+			return err
+		}
+		b.WriteString(s)
+	case bool:
+		b.WriteString(strconv.FormatBool(v))
+	case nil:
+		b.WriteString("null")
+	}
+	return nil
+}
+
+func canonicalizeObject(d *json.Decoder, b *bytes.Buffer) error {
+	members := make([]member, 0)
+	keys := make(map[string]bool)
+	for d.More() {
+		t, err := d.Token()
+		if err != nil {
+			return err
+		}
+		key := t.(string)
+		if keys[key] {
+			return fmt.Errorf("%v: %v", duplicateKey, key)
+		}
+		keys[key] = true
+
+		value := bytes.Buffer{}
+		if err := canonicalize(d, &value); err != nil {
+			return err
+		}
+		members = append(members, member{utf16.Encode([]rune(key)), value.Bytes()})
+	}
+
+	sort.Slice(members, func(i, j int) bool {
+		return lessUTF16(members[i].key, members[j].key)
+	})
+
+	b.WriteByte('{')
+	for i, m := range members {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		writeString(b, string(utf16.Decode(m.key)))
+		b.WriteByte(':')
+		b.Write(m.value)
+	}
+	b.WriteByte('}')
+	return nil
+}
+
+func lessUTF16(a, b []uint16) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
+
+// writeString escapes only the quote, the backslash and the control characters, using the short forms where JSON has them
+func writeString(b *bytes.Buffer, s string) {
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString("\\\"")
+		case '\\':
+			b.WriteString("\\\\")
+		case '\b':
+			b.WriteString("\\b")
+		case '\f':
+			b.WriteString("\\f")
+		case '\n':
+			b.WriteString("\\n")
+		case '\r':
+			b.WriteString("\\r")
+		case '\t':
+			b.WriteString("\\t")
+		default:
+			if r < 0x20 {
+				b.WriteString("\\u00")
+				b.WriteByte(hexDigits[r>>4])
+				b.WriteByte(hexDigits[r&0xf])
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+}
+
+// formatNumber serializes the number the way ECMAScript Number.prototype.toString does
+func formatNumber(n json.Number) (string, error) {
+	f, err := strconv.ParseFloat(string(n), 64)
+	if err != nil || math.IsInf(f, 0) || math.IsNaN(f) {
+		return "", fmt.Errorf("%v: %v", invalidNumber, n)
+	}
+	if f == 0 {
+		return "0", nil // Also -0
+	}
+
+	sign := ""
+	if f < 0 {
+		sign = "-"
+		f = -f
+	}
+
+	// The shortest digits that round trip and the position of the decimal point relative to them
+	e := strconv.FormatFloat(f, 'e', -1, 64)
+	mantissa, exponent := e[:strings.IndexByte(e, 'e')], e[strings.IndexByte(e, 'e')+1:]
+	digits := strings.Replace(mantissa, ".", "", 1)
+	exp, _ := strconv.Atoi(exponent)
+	k, point := len(digits), exp+1
+
+	switch {
+	case k <= point && point <= maxFixedDigits:
+		return sign + digits + strings.Repeat("0", point-k), nil
+	case 0 < point && point <= maxFixedDigits:
+		return sign + digits[:point] + "." + digits[point:], nil
+	case -6 < point && point <= 0:
+		return sign + "0." + strings.Repeat("0", -point) + digits, nil
+	}
+
+	expSign := "+"
+	if point-1 < 0 {
+		expSign = "-"
+	}
+	if k > 1 {
+		digits = digits[:1] + "." + digits[1:]
+	}
+	return sign + digits + "e" + expSign + strconv.Itoa(int(math.Abs(float64(point-1)))), nil
+}
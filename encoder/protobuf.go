@@ -0,0 +1,52 @@
+package encoder
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"reflect"
+)
+
+const (
+	missingMessage = "Incorrect payload - Expected type and message"
+)
+
+// ProtobufPayload is the JSON payload of the protobuf encoding - the full name of a registered message type and the message
+// in the protobuf JSON mapping. Message types are registered by importing the packages generated for them
+type ProtobufPayload struct {
+	Type    string          `json:"type"`
+	Message json.RawMessage `json:"message"`
+}
+
+func encodeProtobuf(payload []byte) ([]byte, error) {
+	var p ProtobufPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return nil, err
+	}
+	if p.Type == "" || len(p.Message) == 0 {
+		return nil, errors.New(missingMessage)
+	}
+
+	t := proto.MessageType(p.Type)
+	if t == nil || t.Kind() != reflect.Ptr {
+		return nil, fmt.Errorf("Incorrect type - Unknown message type %v", p.Type)
+	}
+	m := reflect.New(t.Elem()).Interface().(proto.Message)
+	if err := jsonpb.Unmarshal(bytes.NewReader(p.Message), m); err != nil {
+		return nil, err
+	}
+	return EncodeProtobuf(m)
+}
+
+// EncodeProtobuf returns the deterministic wire format of the message, where the entries of map fields are sorted by key
+func EncodeProtobuf(m proto.Message) ([]byte, error) {
+	b := proto.NewBuffer(nil)
+	b.SetDeterministic(true)
+	if err := b.Marshal(m); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
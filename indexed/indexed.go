@@ -0,0 +1,263 @@
+// Package indexed implements indexed merkle tree on top of memory.MerkleTree. Every leaf carries a key and
+// links to the leaf with the next higher key, so the tree can prove both that a key is present and that it is absent
+package indexed
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/LimeChain/merkletree/memory"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	outOfBounds  = "Incorrect index - Index out of bounds"
+	keyExists    = "Incorrect data - The key is already in the tree"
+	keyNotExists = "Incorrect data - The key is not in the tree"
+	reservedKey  = "Incorrect data - The key is reserved for the sentinel leaf"
+)
+
+// Leaf is a single entry of the indexed merkle tree. NextKey and NextIndex point to the leaf with the next higher key
+// or are zero if this is the leaf with the highest key
+type Leaf struct {
+	Key       common.Hash `json:"key"`
+	NextKey   common.Hash `json:"nextKey"`
+	NextIndex int         `json:"nextIndex"`
+}
+
+// Hash returns keccak256(key || nextKey || nextIndex), nextIndex encoded as uint256
+func (leaf *Leaf) Hash() common.Hash {
+	return crypto.Keccak256Hash(leaf.Key[:], leaf.NextKey[:], math.PaddedBigBytes(big.NewInt(int64(leaf.NextIndex)), 32))
+}
+
+// Proof carries a leaf, its index and the intermediary hashes needed to produce the root from it.
+// For membership the leaf holds the key, for non-membership it is the leaf with the next lower key
+type Proof struct {
+	Leaf   Leaf     `json:"leaf"`
+	Index  int      `json:"index"`
+	Hashes []string `json:"hashes"`
+}
+
+// MerkleTree is an indexed merkle tree. The keys are the keccak256 hashes of the added data.
+// Leaf 0 is a sentinel with zero key, so every key has a lower neighbour
+type MerkleTree struct {
+	tree   *memory.MerkleTree
+	leafs  []*Leaf
+	sorted []int // Indexes of the leafs sorted by key
+	mutex  sync.RWMutex
+}
+
+func less(a, b common.Hash) bool {
+	return bytes.Compare(a[:], b[:]) < 0
+}
+
+// lowIndex returns the position in sorted of the leaf with the biggest key lower than the given one
+func (tree *MerkleTree) lowIndex(key common.Hash) int {
+	return sort.Search(len(tree.sorted), func(i int) bool {
+		return !less(tree.leafs[tree.sorted[i]].Key, key)
+	}) - 1
+}
+
+func (tree *MerkleTree) find(key common.Hash) (index int, found bool) {
+	low := tree.lowIndex(key)
+	if low+1 < len(tree.sorted) && tree.leafs[tree.sorted[low+1]].Key == key {
+		return tree.sorted[low+1], true
+	}
+	return tree.sorted[low], false
+}
+
+// Append hashes the data into a key and inserts it on the next available slot in the tree, updating the leaf with the next lower key to point to it.
+// Returns the index it was inserted and the hash of the new leaf or error if the key is already in the tree
+func (tree *MerkleTree) Append(data []byte) (index int, hash string, err error) {
+	tree.mutex.Lock()
+	defer tree.mutex.Unlock()
+
+	key := crypto.Keccak256Hash(data)
+	if key == (common.Hash{}) {
+		return -1, "", errors.New(reservedKey)
+	}
+	lowIndex, found := tree.find(key)
+	if found {
+		return -1, "", errors.New(keyExists)
+	}
+
+	low := tree.leafs[lowIndex]
+	leaf := &Leaf{key, low.NextKey, low.NextIndex}
+	index = len(tree.leafs)
+	low.NextKey = key
+	low.NextIndex = index
+
+	tree.tree.Update(lowIndex, low.Hash().Hex())
+	tree.tree.Insert(leaf.Hash().Hex())
+	tree.leafs = append(tree.leafs, leaf)
+
+	position := tree.lowIndex(key) + 1
+	tree.sorted = append(tree.sorted, 0)
+	copy(tree.sorted[position+1:], tree.sorted[position:])
+	tree.sorted[position] = index
+
+	return index, leaf.Hash().Hex(), nil
+}
+
+// RawAppend is alias to Append as inserting a key always updates the leaf with the next lower key
+func (tree *MerkleTree) RawAppend(data []byte) (index int, hash string, err error) {
+	return tree.Append(data)
+}
+
+// Add is Append that keeps the tree a merkletree.MerkleTree. Returns index -1 and empty hash if the key is already in the tree
+func (tree *MerkleTree) Add(data []byte) (index int, hash string) {
+	index, hash, _ = tree.Append(data)
+	return index, hash
+}
+
+// RawAdd is alias to Add as inserting a key always updates the leaf with the next lower key
+func (tree *MerkleTree) RawAdd(data []byte) (index int, hash string) {
+	return tree.Add(data)
+}
+
+// ProveMembership returns proof that the data is in the tree
+func (tree *MerkleTree) ProveMembership(data []byte) (*Proof, error) {
+	tree.mutex.RLock()
+	defer tree.mutex.RUnlock()
+
+	index, found := tree.find(crypto.Keccak256Hash(data))
+	if !found {
+		return nil, errors.New(keyNotExists)
+	}
+	return tree.proof(index)
+}
+
+// ProveNonMembership returns proof that the data is not in the tree. The proof is for the leaf with the next lower key,
+// whose link skips over the key of the data
+func (tree *MerkleTree) ProveNonMembership(data []byte) (*Proof, error) {
+	tree.mutex.RLock()
+	defer tree.mutex.RUnlock()
+
+	index, found := tree.find(crypto.Keccak256Hash(data))
+	if found {
+		return nil, errors.New(keyExists)
+	}
+	return tree.proof(index)
+}
+
+func (tree *MerkleTree) proof(index int) (*Proof, error) {
+	hashes, err := tree.tree.IntermediaryHashesByIndex(index)
+	if err != nil {
+		return nil, err
+	}
+	return &Proof{*tree.leafs[index], index, hashes}, nil
+}
+
+// VerifyMembership validates that the data is in the tree with the given root
+func VerifyMembership(root string, data []byte, proof *Proof) bool {
+	return proof.Leaf.Key == crypto.Keccak256Hash(data) &&
+		memory.Verify(root, proof.Index, proof.Leaf.Hash().Hex(), proof.Hashes)
+}
+
+// VerifyNonMembership validates that the data is not in the tree with the given root.
+// The leaf in the proof must have lower key than the data and link to higher key or to nothing
+func VerifyNonMembership(root string, data []byte, proof *Proof) bool {
+	key := crypto.Keccak256Hash(data)
+	if !less(proof.Leaf.Key, key) {
+		return false
+	}
+	if proof.Leaf.NextKey != (common.Hash{}) && !less(key, proof.Leaf.NextKey) {
+		return false
+	}
+	return memory.Verify(root, proof.Index, proof.Leaf.Hash().Hex(), proof.Hashes)
+}
+
+// IntermediaryHashesByIndex returns all hashes needed to produce the root from the given index
+func (tree *MerkleTree) IntermediaryHashesByIndex(index int) (intermediaryHashes []string, err error) {
+	tree.mutex.RLock()
+	defer tree.mutex.RUnlock()
+
+	return tree.tree.IntermediaryHashesByIndex(index)
+}
+
+// ValidateExistence emulates how third party would validate the data. Given original data, the index it is supposed to be and the intermediaryHashes,
+// the method validates that this is the key of the leaf at that slot
+func (tree *MerkleTree) ValidateExistence(original []byte, index int, intermediaryHashes []string) (result bool, err error) {
+	tree.mutex.RLock()
+	defer tree.mutex.RUnlock()
+
+	if index < 0 || index >= len(tree.leafs) {
+		return false, errors.New(outOfBounds)
+	}
+	proof := &Proof{*tree.leafs[index], index, intermediaryHashes}
+	return VerifyMembership(tree.tree.Root(), original, proof), nil
+}
+
+// LeafAt returns the leaf at given index
+func (tree *MerkleTree) LeafAt(index int) (Leaf, error) {
+	tree.mutex.RLock()
+	defer tree.mutex.RUnlock()
+
+	if index < 0 || index >= len(tree.leafs) {
+		return Leaf{}, errors.New(outOfBounds)
+	}
+	return *tree.leafs[index], nil
+}
+
+// HashAt returns the hash of the leaf at given index
+func (tree *MerkleTree) HashAt(index int) (string, error) {
+	tree.mutex.RLock()
+	defer tree.mutex.RUnlock()
+
+	return tree.tree.HashAt(index)
+}
+
+// Root returns the hash of the root of the tree
+func (tree *MerkleTree) Root() string {
+	tree.mutex.RLock()
+	defer tree.mutex.RUnlock()
+
+	return tree.tree.Root()
+}
+
+// Length returns the count of the tree leafs including the sentinel leaf
+func (tree *MerkleTree) Length() int {
+	tree.mutex.RLock()
+	defer tree.mutex.RUnlock()
+
+	return len(tree.leafs)
+}
+
+// String returns human readable version of the tree
+func (tree *MerkleTree) String() string {
+	tree.mutex.RLock()
+	defer tree.mutex.RUnlock()
+
+	b := strings.Builder{}
+	for i, leaf := range tree.leafs {
+		b.WriteString(fmt.Sprintf("Index: %v, Key: %v, Next: %v -> %v\n", i, leaf.Key.Hex(), leaf.NextIndex, leaf.NextKey.Hex()))
+	}
+	b.WriteString(tree.tree.String())
+
+	return b.String()
+}
+
+// MarshalJSON Creates JSON version of the needed fields of the tree
+func (tree *MerkleTree) MarshalJSON() ([]byte, error) {
+	res := fmt.Sprintf("{\"root\":\"%v\", \"length\":%v}", tree.Root(), tree.Length())
+	return []byte(res), nil
+}
+
+// NewMerkleTree returns a pointer to an initialized MerkleTree containing only the sentinel leaf
+func NewMerkleTree() *MerkleTree {
+	sentinel := &Leaf{}
+	tree := &MerkleTree{
+		tree:   memory.NewMerkleTree(),
+		leafs:  []*Leaf{sentinel},
+		sorted: []int{0},
+	}
+	tree.tree.Insert(sentinel.Hash().Hex())
+
+	return tree
+}
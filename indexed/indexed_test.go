@@ -0,0 +1,121 @@
+package indexed
+
+import (
+	"fmt"
+	"github.com/LimeChain/merkletree"
+	"github.com/LimeChain/merkletree/memory"
+	"github.com/LimeChain/merkletree/merkletreetest"
+	"github.com/ethereum/go-ethereum/crypto"
+	"testing"
+)
+
+func TestNewMerkleTree(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree := NewMerkleTree()
+
+	sentinel := Leaf{}
+	et.Assert(tree.Length() == 1, "The tree did not start with the sentinel leaf")
+	et.Assert(tree.Root() == sentinel.Hash().Hex(), "The root was not the hash of the sentinel leaf")
+	_, isExternalMerkleTree := interface{}(tree).(merkletree.ExternalMerkleTree)
+	et.Assert(isExternalMerkleTree, "The tree did not implement the ExternalMerkleTree interface")
+}
+
+func TestAdd(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree := NewMerkleTree()
+	expected := memory.NewMerkleTree()
+
+	data := make([][]byte, 10)
+	for i := range data {
+		data[i] = []byte(fmt.Sprintf("Key %v", i))
+		index, hash := tree.Add(data[i])
+		et.Assert(index == i+1, "Incorrect index of addition", i)
+
+		leaf, _ := tree.LeafAt(index)
+		et.Assert(hash == leaf.Hash().Hex(), "The returned hash was not the hash of the new leaf")
+		et.Assert(leaf.Key == crypto.Keccak256Hash(data[i]), "The key of the leaf was not the keccak256 hash of the data")
+	}
+
+	// Walking the links from the sentinel visits every key in ascending order
+	leaf, _ := tree.LeafAt(0)
+	visited := 1
+	for leaf.NextIndex != 0 {
+		next, _ := tree.LeafAt(leaf.NextIndex)
+		et.Assert(less(leaf.Key, next.Key), "The links were not in ascending order of the keys")
+		et.Assert(leaf.NextKey == next.Key, "The next key did not match the linked leaf")
+		leaf = next
+		visited++
+	}
+	et.Assert(visited == 11, "Not all leafs were linked", visited)
+
+	for i := 0; i < tree.Length(); i++ {
+		leaf, _ := tree.LeafAt(i)
+		expected.Insert(leaf.Hash().Hex())
+	}
+	et.Assert(tree.Root() == expected.Root(), "The root was not the root of the current leafs")
+
+	index, hash := tree.Add(data[3])
+	et.Assert(index == -1 && hash == "", "Duplicate key was added")
+	et.Assert(tree.Length() == 11, "Duplicate key changed the length")
+
+	_, _, err := tree.Append(data[3])
+	et.Assert(err != nil && err.Error() == keyExists, "The error of duplicate key was not returned")
+	_, isAppender := interface{}(tree).(merkletree.Appender)
+	et.Assert(isAppender, "The tree did not implement the Appender interface")
+}
+
+func TestMembership(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree := NewMerkleTree()
+
+	for i := 0; i < 10; i++ {
+		tree.Add([]byte(fmt.Sprintf("Key %v", i)))
+	}
+	root := tree.Root()
+
+	for i := 0; i < 10; i++ {
+		present := []byte(fmt.Sprintf("Key %v", i))
+		proof, err := tree.ProveMembership(present)
+		et.Assert(err == nil, "Error was thrown on membership proof")
+		et.Assert(VerifyMembership(root, present, proof), "Could not verify membership of", i)
+		et.Assert(!VerifyNonMembership(root, present, proof), "Verified non-membership of present key", i)
+
+		_, err = tree.ProveNonMembership(present)
+		et.Assert(err != nil, "Error was not thrown on non-membership proof of present key")
+		et.Assert(err.Error() == keyExists, "Incorrect message was thrown on non-membership proof of present key")
+
+		absent := []byte(fmt.Sprintf("Missing %v", i))
+		proof, err = tree.ProveNonMembership(absent)
+		et.Assert(err == nil, "Error was thrown on non-membership proof")
+		et.Assert(VerifyNonMembership(root, absent, proof), "Could not verify non-membership of", i)
+		et.Assert(!VerifyMembership(root, absent, proof), "Verified membership of absent key", i)
+
+		_, err = tree.ProveMembership(absent)
+		et.Assert(err != nil, "Error was not thrown on membership proof of absent key")
+	}
+
+	absent := []byte("Missing")
+	proof, _ := tree.ProveNonMembership(absent)
+	proof.Leaf.NextKey = crypto.Keccak256Hash(absent)
+	et.Assert(!VerifyNonMembership(root, absent, proof), "Verified non-membership with tampered leaf")
+}
+
+func TestValidateExistence(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree := NewMerkleTree()
+	tree.Add([]byte("First Key"))
+	index, _ := tree.Add([]byte("Second Key"))
+
+	hashes, err := tree.IntermediaryHashesByIndex(index)
+	et.Assert(err == nil, "Error was thrown for intermediary hashes")
+
+	exists, err := tree.ValidateExistence([]byte("Second Key"), index, hashes)
+	et.Assert(err == nil, "Error was thrown on validation")
+	et.Assert(exists, "Could not validate existing key")
+
+	exists, _ = tree.ValidateExistence([]byte("First Key"), index, hashes)
+	et.Assert(!exists, "Validated key at wrong index")
+
+	_, err = tree.ValidateExistence([]byte("First Key"), 5, hashes)
+	et.Assert(err != nil, "Error was not thrown on index out of bounds")
+}
@@ -0,0 +1,327 @@
+// Package kary implements merkle tree with configurable branching factor stored in the memory of the system.
+// With arity 2 it produces the same roots and proofs as memory.MerkleTree
+package kary
+
+import (
+	"errors"
+	"fmt"
+	"github.com/LimeChain/merkletree"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"strings"
+	"sync"
+)
+
+const (
+	outOfBounds    = "Incorrect index - Index out of bounds"
+	invalidSize    = "Incorrect size - Size out of bounds"
+	invalidArity   = "Incorrect arity - Arity must be at least 2"
+	corruptedProof = "Incorrect proof - The count of hashes is not a multiple of arity - 1"
+)
+
+// Node is implementation of types.Node and representation of a single node or leaf in the merkle tree
+type Node struct {
+	hash  common.Hash
+	index int
+}
+
+// Hash returns the string representation of the hash of the node
+func (node *Node) Hash() string {
+	return node.hash.Hex()
+}
+
+// Index returns the index of this node in its level
+func (node *Node) Index() int {
+	return node.index
+}
+
+// String returns the hash of this node. Alias to Hash()
+func (node Node) String() string {
+	return node.Hash()
+}
+
+// MerkleTree is a merkle tree in which every parent hashes the concatenation of up to Arity children.
+// Incomplete groups at the right edge are padded with their last node, the same way memory.MerkleTree duplicates the odd node
+type MerkleTree struct {
+	Nodes [][]*Node
+	Arity int
+	Mutex sync.RWMutex
+}
+
+func hashGroup(group []common.Hash) common.Hash {
+	data := make([][]byte, len(group))
+	for i := range group {
+		data[i] = group[i][:]
+	}
+	return crypto.Keccak256Hash(data...)
+}
+
+// group returns the hashes of the children of the given parent, padded to arity
+func (tree *MerkleTree) group(level int, parent int) []common.Hash {
+	nodes := tree.Nodes[level]
+	group := make([]common.Hash, tree.Arity)
+	for i := range group {
+		child := parent*tree.Arity + i
+		if child >= len(nodes) {
+			child = len(nodes) - 1
+		}
+		group[i] = nodes[child].hash
+	}
+	return group
+}
+
+func (tree *MerkleTree) updateParent(level int, parent int) {
+	node := &Node{hashGroup(tree.group(level, parent)), parent}
+	if len(tree.Nodes) == level+1 {
+		tree.Nodes = append(tree.Nodes, make([]*Node, 0, 1))
+	}
+	if parent == len(tree.Nodes[level+1]) {
+		tree.Nodes[level+1] = append(tree.Nodes[level+1], node)
+	} else {
+		tree.Nodes[level+1][parent] = node
+	}
+}
+
+func (tree *MerkleTree) propagateChange() {
+	for level := 0; len(tree.Nodes[level]) > 1; level++ {
+		tree.updateParent(level, (len(tree.Nodes[level])-1)/tree.Arity)
+	}
+}
+
+// Add hashes and inserts data on the next available slot in the tree.
+// Also recalculates and recalibrates the tree.
+// Returns the index it was inserted and the hash of the new data
+func (tree *MerkleTree) Add(data []byte) (index int, hash string) {
+	h := crypto.Keccak256Hash(data)
+	index = tree.Insert(h.Hex())
+	return index, h.Hex()
+}
+
+// RawAdd adds data to the tree without recalculating the tree
+// Returns the index of the leaf and the hash of the data
+func (tree *MerkleTree) RawAdd(data []byte) (index int, hash string) {
+	h := crypto.Keccak256Hash(data)
+	index, _ = tree.RawInsert(h.Hex())
+	return index, h.Hex()
+}
+
+// RawInsert creates node out of the hash and pushes it into the tree without recalculating the tree
+// Returns the index of the leaf and the node
+func (tree *MerkleTree) RawInsert(hash string) (index int, insertedLeaf merkletree.Node) {
+	tree.Mutex.Lock()
+	defer tree.Mutex.Unlock()
+
+	return tree.rawInsert(hash)
+}
+
+func (tree *MerkleTree) rawInsert(hash string) (index int, leaf *Node) {
+	index = len(tree.Nodes[0])
+	leaf = &Node{common.HexToHash(hash), index}
+	tree.Nodes[0] = append(tree.Nodes[0], leaf)
+
+	return index, leaf
+}
+
+// Insert creates node out of the hash and pushes it into the tree
+// Also recalculates the right edge of the tree
+// Returns the index it was inserted at
+func (tree *MerkleTree) Insert(hash string) (index int) {
+	tree.Mutex.Lock()
+	defer tree.Mutex.Unlock()
+
+	index, _ = tree.rawInsert(hash)
+	tree.propagateChange()
+
+	return index
+}
+
+// Recalculate recreates the whole tree bottom up and returns the hex string of the new root.
+// Great to be used with RawInsert when loading up the tree data.
+func (tree *MerkleTree) Recalculate() (treeRoot string) {
+	tree.Mutex.Lock()
+	defer tree.Mutex.Unlock()
+
+	return tree.recalculate()
+}
+
+func (tree *MerkleTree) recalculate() string {
+	tree.Nodes = tree.Nodes[:1]
+	for level := 0; len(tree.Nodes[level]) > 1; level++ {
+		parents := (len(tree.Nodes[level]) + tree.Arity - 1) / tree.Arity
+		for parent := 0; parent < parents; parent++ {
+			tree.updateParent(level, parent)
+		}
+	}
+
+	return tree.root()
+}
+
+// Truncate removes all leafs from the given size onwards and recalculates the right edge and the root.
+// Returns error if the size is negative or bigger than the current length of the tree
+func (tree *MerkleTree) Truncate(size int) error {
+	tree.Mutex.Lock()
+	defer tree.Mutex.Unlock()
+
+	if size < 0 || size > len(tree.Nodes[0]) {
+		return errors.New(invalidSize)
+	}
+
+	tree.Nodes[0] = tree.Nodes[0][:size]
+	levelLen, level := size, 1
+	for ; levelLen > 1; level++ {
+		levelLen = (levelLen + tree.Arity - 1) / tree.Arity
+		if level >= len(tree.Nodes) || levelLen > len(tree.Nodes[level]) { // Leafs added with RawInsert left the upper levels short
+			tree.recalculate()
+			return nil
+		}
+		tree.Nodes[level] = tree.Nodes[level][:levelLen]
+	}
+	tree.Nodes = tree.Nodes[:level]
+	tree.propagateChange()
+
+	return nil
+}
+
+func (tree *MerkleTree) root() string {
+	top := tree.Nodes[len(tree.Nodes)-1]
+	if len(top) == 0 {
+		return ""
+	}
+	return top[0].Hash()
+}
+
+// IntermediaryHashesByIndex returns all hashes needed to produce the root from the given index.
+// Every level contributes the arity - 1 siblings of the node in the order they appear in their group
+func (tree *MerkleTree) IntermediaryHashesByIndex(index int) (intermediaryHashes []string, err error) {
+	tree.Mutex.RLock()
+	defer tree.Mutex.RUnlock()
+
+	if index < 0 || index >= len(tree.Nodes[0]) {
+		return nil, errors.New(outOfBounds)
+	}
+
+	intermediaryHashes = make([]string, 0, (len(tree.Nodes)-1)*(tree.Arity-1))
+	for level := 0; level < len(tree.Nodes)-1; level++ {
+		group := tree.group(level, index/tree.Arity)
+		for i, h := range group {
+			if i != index%tree.Arity {
+				intermediaryHashes = append(intermediaryHashes, h.Hex())
+			}
+		}
+		index /= tree.Arity
+	}
+
+	return intermediaryHashes, nil
+}
+
+// ValidateExistence emulates how third party would validate the data. Given original data, the index it is supposed to be and the intermediaryHashes,
+// the method validates that this is the correct data for that slot. Use Verify to validate without the tree
+func (tree *MerkleTree) ValidateExistence(original []byte, index int, intermediaryHashes []string) (result bool, err error) {
+	tree.Mutex.RLock()
+	defer tree.Mutex.RUnlock()
+
+	if index < 0 || index >= len(tree.Nodes[0]) {
+		return false, errors.New(outOfBounds)
+	}
+
+	leafHash := crypto.Keccak256Hash(original)
+	if leafHash != tree.Nodes[0][index].hash {
+		return false, nil
+	}
+
+	return Verify(tree.Arity, tree.root(), index, leafHash.Hex(), intermediaryHashes)
+}
+
+// Verify validates that the leaf hash is at the given index in a tree with the given arity and root,
+// using the intermediary hashes returned by IntermediaryHashesByIndex
+func Verify(arity int, root string, index int, leafHash string, intermediaryHashes []string) (bool, error) {
+	if arity < 2 {
+		return false, errors.New(invalidArity)
+	}
+	if index < 0 {
+		return false, errors.New(outOfBounds)
+	}
+	if len(intermediaryHashes)%(arity-1) != 0 {
+		return false, errors.New(corruptedProof)
+	}
+
+	hash := common.HexToHash(leafHash)
+	group := make([]common.Hash, arity)
+	for len(intermediaryHashes) > 0 {
+		position := index % arity
+		siblings := intermediaryHashes[:arity-1]
+		intermediaryHashes = intermediaryHashes[arity-1:]
+
+		for i, s := 0, 0; i < arity; i++ {
+			if i == position {
+				group[i] = hash
+				continue
+			}
+			group[i] = common.HexToHash(siblings[s])
+			s++
+		}
+		hash = hashGroup(group)
+		index /= arity
+	}
+
+	return index == 0 && hash == common.HexToHash(root), nil
+}
+
+// Root returns the hash of the root of the tree
+func (tree *MerkleTree) Root() string {
+	tree.Mutex.RLock()
+	defer tree.Mutex.RUnlock()
+
+	return tree.root()
+}
+
+// Length returns the count of the tree leafs
+func (tree *MerkleTree) Length() int {
+	tree.Mutex.RLock()
+	defer tree.Mutex.RUnlock()
+
+	return len(tree.Nodes[0])
+}
+
+// String returns human readable version of the tree
+func (tree *MerkleTree) String() string {
+	tree.Mutex.RLock()
+	defer tree.Mutex.RUnlock()
+
+	b := strings.Builder{}
+	for i := len(tree.Nodes) - 1; i >= 0; i-- {
+		b.WriteString(fmt.Sprintf("Level: %v, Count: %v\n", i, len(tree.Nodes[i])))
+		for _, node := range tree.Nodes[i] {
+			b.WriteString(fmt.Sprintf("%v\t", node.Hash()))
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// HashAt returns the hash at given index
+func (tree *MerkleTree) HashAt(index int) (string, error) {
+	tree.Mutex.RLock()
+	defer tree.Mutex.RUnlock()
+
+	if index < 0 || index >= len(tree.Nodes[0]) {
+		return "", errors.New(outOfBounds)
+	}
+	return tree.Nodes[0][index].Hash(), nil
+}
+
+// MarshalJSON Creates JSON version of the needed fields of the tree
+func (tree *MerkleTree) MarshalJSON() ([]byte, error) {
+	res := fmt.Sprintf("{\"root\":\"%v\", \"length\":%v, \"arity\":%v}", tree.Root(), tree.Length(), tree.Arity)
+	return []byte(res), nil
+}
+
+// NewMerkleTree returns a pointer to an initialized MerkleTree with the given branching factor
+func NewMerkleTree(arity int) (*MerkleTree, error) {
+	if arity < 2 {
+		return nil, errors.New(invalidArity)
+	}
+
+	return &MerkleTree{Nodes: make([][]*Node, 1), Arity: arity}, nil
+}
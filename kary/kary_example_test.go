@@ -0,0 +1,25 @@
+package kary_test
+
+import (
+	"fmt"
+	"github.com/LimeChain/merkletree/kary"
+	"strconv"
+)
+
+func Example() {
+	tree, _ := kary.NewMerkleTree(4)
+	for i := 0; i < 20; i++ {
+		tree.Add([]byte("hello" + strconv.Itoa(i)))
+	}
+
+	data := "Merkle Trees Rock"
+	i, h := tree.Add([]byte(data))
+	intermediaryHashes, _ := tree.IntermediaryHashesByIndex(i)
+	fmt.Printf("Hashes: %v\n", len(intermediaryHashes))
+	exists, _ := kary.Verify(tree.Arity, tree.Root(), i, h, intermediaryHashes)
+	fmt.Printf("Element Exists: %v\n", exists)
+
+	// Output:
+	// Hashes: 9
+	// Element Exists: true
+}
@@ -0,0 +1,179 @@
+package kary
+
+import (
+	"fmt"
+	"github.com/LimeChain/merkletree"
+	"github.com/LimeChain/merkletree/memory"
+	"github.com/LimeChain/merkletree/merkletreetest"
+	"github.com/ethereum/go-ethereum/crypto"
+	"testing"
+)
+
+func leafData(i int) []byte {
+	return []byte(fmt.Sprintf("Leaf %v", i))
+}
+
+func TestNewMerkleTree(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree, err := NewMerkleTree(4)
+	et.Assert(err == nil, "Error was thrown on valid arity")
+	et.Assert(tree.Root() == "", "The root was not empty after init")
+	_, isFullMerkleTree := interface{}(tree).(merkletree.FullMerkleTree)
+	et.Assert(isFullMerkleTree, "The tree did not implement the FullMerkleTree interface")
+
+	_, err = NewMerkleTree(1)
+	et.Assert(err != nil, "Error was not thrown on arity 1")
+	et.Assert(err.Error() == invalidArity, "Incorrect message was thrown on invalid arity")
+}
+
+func TestBinaryMatchesMemory(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree, _ := NewMerkleTree(2)
+	expected := memory.NewMerkleTree()
+
+	for n := 1; n <= 17; n++ {
+		tree.Add(leafData(n - 1))
+		expected.Add(leafData(n - 1))
+		et.Assert(tree.Root() == expected.Root(), "The binary root differed from memory.MerkleTree for", n)
+
+		for i := 0; i < n; i++ {
+			hashes, _ := tree.IntermediaryHashesByIndex(i)
+			expectedHashes, _ := expected.IntermediaryHashesByIndex(i)
+			et.Assert(fmt.Sprint(hashes) == fmt.Sprint(expectedHashes), "The binary proof differed from memory.MerkleTree", i, n)
+		}
+	}
+}
+
+func TestAdd(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree, _ := NewMerkleTree(4)
+
+	var leafs [6][]byte
+	for i := range leafs {
+		h := crypto.Keccak256Hash(leafData(i))
+		leafs[i] = h[:]
+		index, hash := tree.Add(leafData(i))
+		et.Assert(index == i, "Incorrect index of addition", i)
+		et.Assert(hash == h.Hex(), "The hash of the added node was not the keccak256 hash of the data")
+	}
+
+	left := crypto.Keccak256Hash(leafs[0], leafs[1], leafs[2], leafs[3])
+	right := crypto.Keccak256Hash(leafs[4], leafs[5], leafs[5], leafs[5])
+	expectedRoot := crypto.Keccak256Hash(left[:], right[:], right[:], right[:])
+
+	et.Assert(tree.Root() == expectedRoot.Hex(), "The root was not correctly calculated", tree)
+	et.Assert(len(tree.Nodes) == 3, "The tree did not have 3 levels")
+}
+
+func TestIntermediaryHashesByIndex(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+
+	for _, arity := range []int{2, 3, 4, 16} {
+		tree, _ := NewMerkleTree(arity)
+
+		_, err := tree.IntermediaryHashesByIndex(0)
+		et.Assert(err != nil, "Error was not thrown on empty tree")
+		et.Assert(err.Error() == outOfBounds, "Incorrect message was thrown on fetching hashes by out of bounds index")
+
+		for n := 1; n <= 40; n++ {
+			tree.Add(leafData(n - 1))
+			for i := 0; i < n; i++ {
+				hashes, err := tree.IntermediaryHashesByIndex(i)
+				et.Assert(err == nil, "Error was thrown for intermediary hashes")
+				et.Assert(len(hashes) == (len(tree.Nodes)-1)*(arity-1), "Incorrect count of intermediary hashes")
+
+				exists, err := tree.ValidateExistence(leafData(i), i, hashes)
+				et.Assert(err == nil, "Error was thrown on validation")
+				et.Assert(exists, "Could not validate leaf", i, "in tree of", n, "with arity", arity)
+
+				leafHash := crypto.Keccak256Hash(leafData(i)).Hex()
+				if i < n-1 { // The padding makes the last leaf valid at the slots it is copied to
+					exists, _ = Verify(arity, tree.Root(), i+1, leafHash, hashes)
+					et.Assert(!exists, "Verified leaf at wrong index", i, n, arity)
+				}
+			}
+		}
+	}
+
+	tree, _ := NewMerkleTree(4)
+	tree.Add(leafData(0))
+	tree.Add(leafData(1))
+	_, err := Verify(4, tree.Root(), 0, crypto.Keccak256Hash(leafData(0)).Hex(), []string{"0x01"})
+	et.Assert(err != nil, "Error was not thrown on incomplete proof")
+	et.Assert(err.Error() == corruptedProof, "Incorrect message was thrown on incomplete proof")
+}
+
+func TestRecalculate(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree, _ := NewMerkleTree(3)
+	expected, _ := NewMerkleTree(3)
+
+	for i := 0; i < 11; i++ {
+		tree.RawAdd(leafData(i))
+		expected.Add(leafData(i))
+	}
+
+	et.Assert(tree.Recalculate() == expected.Root(), "The recalculated root differed from the incremental one")
+	et.Assert(tree.Root() == expected.Root(), "The recalculated root was not stored")
+}
+
+func TestTruncate(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree, _ := NewMerkleTree(4)
+	for i := 0; i < 21; i++ {
+		tree.Add(leafData(i))
+	}
+
+	for size := 20; size >= 0; size-- {
+		err := tree.Truncate(size)
+		et.Assert(err == nil, "Error was thrown on truncating to", size)
+
+		expected, _ := NewMerkleTree(4)
+		for i := 0; i < size; i++ {
+			expected.Add(leafData(i))
+		}
+		et.Assert(tree.Root() == expected.Root(), "Incorrect root after truncating to", size)
+		et.Assert(len(tree.Nodes) == len(expected.Nodes), "The levels were not trimmed after truncating to", size)
+	}
+
+	err := tree.Truncate(1)
+	et.Assert(err != nil, "Error was not thrown on truncating above the length")
+	et.Assert(err.Error() == invalidSize, "Incorrect message was thrown on truncating above the length")
+}
+
+func TestTruncateRawAdditions(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+
+	for calculated := 0; calculated <= 5; calculated++ {
+		for size := 0; size <= calculated+9; size++ {
+			tree, _ := NewMerkleTree(4)
+			for i := 0; i < calculated; i++ {
+				tree.Add(leafData(i))
+			}
+			for i := calculated; i < calculated+9; i++ {
+				tree.RawAdd(leafData(i))
+			}
+
+			err := tree.Truncate(size)
+			et.Assert(err == nil, "Error was thrown on truncating raw additions to", size, err)
+
+			expected, _ := NewMerkleTree(4)
+			for i := 0; i < size; i++ {
+				expected.Add(leafData(i))
+			}
+			et.Assert(tree.Root() == expected.Root(), "Incorrect root after truncating raw additions", calculated, size)
+			et.Assert(len(tree.Nodes) == len(expected.Nodes), "The levels were not rebuilt after truncating raw additions", calculated, size)
+		}
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree, _ := NewMerkleTree(16)
+	_, h := tree.Add(leafData(0))
+
+	expected := fmt.Sprintf(`{"root":"%v", "length":1, "arity":16}`, h)
+	received, _ := tree.MarshalJSON()
+
+	et.Assert(string(received) == expected, string(received))
+}
@@ -14,6 +14,7 @@ import (
 
 const (
 	outOfBounds = "Incorrect index - Index out of bounds"
+	invalidSize = "Incorrect size - Size out of bounds"
 )
 
 // Node is implementation of types.Node and representation of a single node or leaf in the merkle tree
@@ -21,6 +22,7 @@ type Node struct {
 	hash   common.Hash
 	index  int
 	Parent *Node
+	salt   []byte
 }
 
 // Hash returns the string representation of the hash of the node
@@ -33,6 +35,11 @@ func (node *Node) Index() int {
 	return node.index
 }
 
+// Salt returns the salt the data of the leaf was hashed with or nil if the leaf is not salted
+func (node *Node) Salt() []byte {
+	return node.salt
+}
+
 // String returns the hash of this node. Alias to Hash()
 func (node Node) String() string {
 	return node.Hash()
@@ -180,16 +187,23 @@ func (tree *MerkleTree) RawAdd(data []byte) (index int, hash string) {
 // Returns the index of the leaf and the node
 func (tree *MerkleTree) RawInsert(hash string) (index int, insertedLeaf merkletree.Node) {
 	tree.Mutex.RLock()
+	index, leaf := tree.rawInsert(hash)
+	tree.Mutex.RUnlock()
+
+	return index, leaf
+}
+
+func (tree *MerkleTree) rawInsert(hash string) (index int, leaf *Node) {
 	index = len(tree.Nodes[0])
 
-	leaf := &Node{
+	leaf = &Node{
 		common.HexToHash(hash),
 		index,
 		nil,
+		nil,
 	}
 
 	tree.Nodes[0] = append(tree.Nodes[0], leaf)
-	tree.Mutex.RUnlock()
 
 	return index, leaf
 }
@@ -223,18 +237,109 @@ func (tree *MerkleTree) Recalculate() (treeRoot string) {
 // Returns the index it was inserted at
 func (tree *MerkleTree) Insert(hash string) (index int) {
 	tree.Mutex.RLock()
-	index, leaf := tree.RawInsert(hash)
+	index = tree.insert(hash)
+	tree.Mutex.RUnlock()
+	return index
+}
+
+func (tree *MerkleTree) insert(hash string) (index int) {
+	index, leaf := tree.rawInsert(hash)
 
 	if index == 0 {
-		rootNode, _ := leaf.(*Node)
-		tree.RootNode = rootNode
+		tree.RootNode = leaf
 	} else {
 		tree.RootNode = tree.propagateChange()
 	}
-	tree.Mutex.RUnlock()
 	return index
 }
 
+// Truncate removes all leafs from the given size onwards, trims every level accordingly and recalculates the right edge and the root.
+// Returns error if the size is negative or bigger than the current length of the tree
+func (tree *MerkleTree) Truncate(size int) error {
+	tree.Mutex.Lock()
+	defer tree.Mutex.Unlock()
+
+	if size < 0 || size > len(tree.Nodes[0]) {
+		return errors.New(invalidSize)
+	}
+
+	if size == 0 {
+		tree.init()
+		tree.RootNode = nil
+		return nil
+	}
+
+	levels := int(math.Ceil(math.Log2(float64(size)))) + 1
+	if !tree.calculated(size, levels) { // Leafs added with RawInsert left the upper levels short
+		tree.Nodes = [][]*Node{tree.Nodes[0][:size]}
+		tree.Recalculate()
+		tree.RootNode.Parent = nil
+		return nil
+	}
+	tree.Nodes = tree.Nodes[:levels]
+	for i := 0; i < levels; i++ {
+		tree.Nodes[i] = tree.Nodes[i][:levelLength(size, i)]
+	}
+
+	if size == 1 {
+		tree.RootNode = tree.Nodes[0][0]
+	} else {
+		tree.RootNode = tree.propagateChange()
+	}
+	tree.RootNode.Parent = nil // The old root might have been above the new one
+
+	return nil
+}
+
+// levelLength returns the count of the nodes at the level of a tree with the given size. Every level is half of the one
+// below, rounded up
+func levelLength(size, level int) int {
+	return (size + (1 << uint(level)) - 1) >> uint(level)
+}
+
+// calculated reports whether every level of the tree truncated to the given size already has its nodes
+func (tree *MerkleTree) calculated(size, levels int) bool {
+	if len(tree.Nodes) < levels {
+		return false
+	}
+	for i := 0; i < levels; i++ {
+		if len(tree.Nodes[i]) < levelLength(size, i) {
+			return false
+		}
+	}
+	return true
+}
+
+// Update replaces the hash of the leaf at given index and recalculates its path up to the root.
+// Returns error if the index is out of bounds
+func (tree *MerkleTree) Update(index int, hash string) error {
+	tree.Mutex.Lock()
+	defer tree.Mutex.Unlock()
+
+	if index < 0 || index >= len(tree.Nodes[0]) {
+		return errors.New(outOfBounds)
+	}
+
+	tree.Nodes[0][index] = &Node{
+		common.HexToHash(hash),
+		index,
+		nil,
+		nil,
+	}
+
+	levels := len(tree.Nodes)
+	for i := 0; i < levels-1; i++ {
+		left := tree.Nodes[i][index-index%2]
+		right := tree.getNodeSibling(i, index-index%2)
+		tree.Nodes[i+1][index/2] = createParent(left, right)
+		index /= 2
+	}
+
+	tree.RootNode = tree.Nodes[levels-1][0]
+
+	return nil
+}
+
 // IntermediaryHashesByIndex returns all hashes needed to produce the root from the given index
 func (tree *MerkleTree) IntermediaryHashesByIndex(index int) (intermediaryHashes []string, err error) {
 	if index >= len(tree.Nodes[0]) {
@@ -263,7 +368,14 @@ func (tree *MerkleTree) ValidateExistence(original []byte, index int, intermedia
 		return false, nil
 	}
 
-	tempBHash := leafHash
+	return Verify(tree.RootNode.Hash(), index, leafHash.Hex(), intermediaryHashes), nil
+
+}
+
+// Verify validates without the tree that the leaf hash is at the given index in a tree with the given root,
+// using the intermediary hashes returned by IntermediaryHashesByIndex
+func Verify(root string, index int, leafHash string, intermediaryHashes []string) bool {
+	tempBHash := common.HexToHash(leafHash)
 
 	for _, h := range intermediaryHashes {
 		oppositeHash := common.HexToHash(h)
@@ -277,8 +389,7 @@ func (tree *MerkleTree) ValidateExistence(original []byte, index int, intermedia
 		index /= 2
 	}
 
-	return tempBHash.Big().Cmp(tree.RootNode.hash.Big()) == 0, nil
-
+	return tempBHash == common.HexToHash(root)
 }
 
 // Root returns the hash of the root of the tree
@@ -312,6 +423,38 @@ func (tree *MerkleTree) String() string {
 	return b.String()
 }
 
+// Path returns the nodes from the leaf at the given index up to the root, one for every level
+func (tree *MerkleTree) Path(index int) (nodes []merkletree.Node, err error) {
+	if index < 0 || index >= len(tree.Nodes[0]) {
+		return nil, errors.New(outOfBounds)
+	}
+	nodes = make([]merkletree.Node, len(tree.Nodes))
+	for i := range tree.Nodes {
+		nodes[i] = tree.Nodes[i][index>>uint(i)]
+	}
+	return nodes, nil
+}
+
+// RootAt returns the root the tree had when it had the given count of leafs. It is calculated from the left siblings of the
+// last of these leafs, that were complete already at that size
+func (tree *MerkleTree) RootAt(size int) (string, error) {
+	if size <= 0 || size > len(tree.Nodes[0]) {
+		return "", errors.New(invalidSize)
+	}
+	index := size - 1
+	hash := tree.Nodes[0][index].hash
+	levels := int(math.Ceil(math.Log2(float64(size)))) + 1
+	for i := 0; i < levels-1; i++ {
+		if index%2 == 1 {
+			hash = crypto.Keccak256Hash(tree.Nodes[i][index-1].hash[:], hash[:])
+		} else {
+			hash = crypto.Keccak256Hash(hash[:], hash[:]) // The last node of the level was hashed with itself
+		}
+		index /= 2
+	}
+	return hash.Hex(), nil
+}
+
 // HashAt returns the hash at given index
 func (tree *MerkleTree) HashAt(index int) (string, error) {
 	if index >= len(tree.Nodes[0]) {
@@ -326,6 +469,11 @@ func (tree *MerkleTree) MarshalJSON() ([]byte, error) {
 	return []byte(res), nil
 }
 
+// Begin starts a transaction whose additions are published in the tree only on Commit
+func (tree *MerkleTree) Begin() (merkletree.Transaction, error) {
+	return &Transaction{tree: tree, start: tree.Length()}, nil
+}
+
 // NewMerkleTree returns a pointer to an initialized MerkleTree
 func NewMerkleTree() *MerkleTree {
 	var tree MerkleTree
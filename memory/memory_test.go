@@ -5,6 +5,7 @@ import (
 	"github.com/LimeChain/merkletree"
 	"github.com/LimeChain/merkletree/merkletreetest"
 	"github.com/ethereum/go-ethereum/crypto"
+	"strconv"
 	"testing"
 )
 
@@ -21,6 +22,8 @@ func TestNewMerkleTree(t *testing.T) {
 	et.Assert(isInternalMerkleTree, "The tree did not implement the InternalMerkleTree interface")
 	_, isFullMerkleTree := interface{}(tree).(merkletree.FullMerkleTree)
 	et.Assert(isFullMerkleTree, "The tree did not implement the FullMerkleTree interface")
+	_, isTruncater := interface{}(tree).(merkletree.Truncater)
+	et.Assert(isTruncater, "The tree did not implement the Truncater interface")
 }
 
 func TestAdd(t *testing.T) {
@@ -175,6 +178,133 @@ func TestValidateExistence(t *testing.T) {
 
 }
 
+func TestTruncate(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+
+	tree := NewMerkleTree()
+	for i := 0; i < 13; i++ {
+		tree.Add([]byte(fmt.Sprintf("Leaf %v", i)))
+	}
+
+	for size := 12; size >= 0; size-- {
+		err := tree.Truncate(size)
+		et.Assert(err == nil, "Error was thrown on truncating to", size)
+
+		expected := NewMerkleTree()
+		for i := 0; i < size; i++ {
+			expected.Add([]byte(fmt.Sprintf("Leaf %v", i)))
+		}
+
+		et.Assert(tree.Length() == size, "The length of the tree was not the truncated size", size)
+		et.Assert(len(tree.Nodes) == len(expected.Nodes), "The tree levels were not trimmed on truncating to", size)
+		et.Assert(tree.Root() == expected.Root(), "The root was not recalculated on truncating to", size)
+
+		for i := 0; i < size; i++ {
+			hashes, err := tree.IntermediaryHashesByIndex(i)
+			et.Assert(err == nil, "Error was thrown for intermediary hashes after truncating")
+			exists, err := tree.ValidateExistence([]byte(fmt.Sprintf("Leaf %v", i)), i, hashes)
+			et.Assert(err == nil, "Error was thrown on validating after truncating")
+			et.Assert(exists, "Could not validate leaf", i, "after truncating to", size)
+		}
+	}
+
+	tree.Add([]byte("First Leaf"))
+	tree.Add([]byte("Second Leaf"))
+	tree.Truncate(1)
+	tree.Add([]byte("Other Leaf"))
+
+	dh1 := crypto.Keccak256Hash([]byte("First Leaf"))
+	dh2 := crypto.Keccak256Hash([]byte("Other Leaf"))
+	expectedRoot := crypto.Keccak256Hash(dh1[:], dh2[:]).Hex()
+
+	et.Assert(tree.Root() == expectedRoot, "The tree did not grow correctly after truncating")
+
+	err := tree.Truncate(3)
+	et.Assert(err != nil, "Error was not thrown on truncating above the length")
+	et.Assert(err.Error() == invalidSize, "Incorrect message was thrown on truncating above the length")
+
+	err = tree.Truncate(-1)
+	et.Assert(err != nil, "Error was not thrown on truncating to negative size")
+}
+
+func TestTruncateRawAdditions(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+
+	for calculated := 0; calculated <= 5; calculated++ {
+		for size := 0; size <= calculated+5; size++ {
+			tree := NewMerkleTree()
+			for i := 0; i < calculated; i++ {
+				tree.Add([]byte(fmt.Sprintf("Leaf %v", i)))
+			}
+			for i := calculated; i < calculated+5; i++ {
+				tree.RawAdd([]byte(fmt.Sprintf("Leaf %v", i)))
+			}
+
+			err := tree.Truncate(size)
+			et.Assert(err == nil, "Error was thrown on truncating raw additions to", size, err)
+
+			expected := NewMerkleTree()
+			for i := 0; i < size; i++ {
+				expected.Add([]byte(fmt.Sprintf("Leaf %v", i)))
+			}
+			et.Assert(tree.Length() == size && tree.Root() == expected.Root(), "The root was not recalculated on truncating raw additions", calculated, size)
+			for i := 0; i < size; i++ {
+				hashes, _ := tree.IntermediaryHashesByIndex(i)
+				exists, err := tree.ValidateExistence([]byte(fmt.Sprintf("Leaf %v", i)), i, hashes)
+				et.Assert(err == nil && exists, "Could not validate leaf", i, "after truncating raw additions to", size)
+			}
+		}
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+
+	for size := 1; size <= 9; size++ {
+		for index := 0; index < size; index++ {
+			tree := NewMerkleTree()
+			expected := NewMerkleTree()
+			for i := 0; i < size; i++ {
+				tree.Add([]byte(fmt.Sprintf("Leaf %v", i)))
+				if i == index {
+					expected.Add([]byte("Updated Leaf"))
+				} else {
+					expected.Add([]byte(fmt.Sprintf("Leaf %v", i)))
+				}
+			}
+
+			err := tree.Update(index, crypto.Keccak256Hash([]byte("Updated Leaf")).Hex())
+			et.Assert(err == nil, "Error was thrown on update")
+			et.Assert(tree.Root() == expected.Root(), "The root was not recalculated on updating", index, "of", size)
+
+			hashes, _ := tree.IntermediaryHashesByIndex(index)
+			exists, _ := tree.ValidateExistence([]byte("Updated Leaf"), index, hashes)
+			et.Assert(exists, "Could not validate the updated leaf", index, "of", size)
+		}
+	}
+
+	tree := NewMerkleTree()
+	err := tree.Update(0, crypto.Keccak256Hash([]byte("Leaf")).Hex())
+	et.Assert(err != nil, "Error was not thrown on updating missing leaf")
+	et.Assert(err.Error() == outOfBounds, "Incorrect message was thrown on updating missing leaf")
+}
+
+func TestVerify(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+
+	tree := NewMerkleTree()
+	for i := 0; i < 5; i++ {
+		tree.Add([]byte(fmt.Sprintf("Leaf %v", i)))
+	}
+
+	leafHash := crypto.Keccak256Hash([]byte("Leaf 3")).Hex()
+	hashes, _ := tree.IntermediaryHashesByIndex(3)
+
+	et.Assert(Verify(tree.Root(), 3, leafHash, hashes), "Could not verify the leaf without the tree")
+	et.Assert(!Verify(tree.Root(), 2, leafHash, hashes), "Verified the leaf at wrong index")
+	et.Assert(!Verify(tree.Root(), 3, leafHash, hashes[1:]), "Verified the leaf with incomplete hashes")
+}
+
 func TestLength(t *testing.T) {
 	et := merkletreetest.WrapTesting(t)
 
@@ -239,6 +369,47 @@ func TestHashAt(t *testing.T) {
 
 }
 
+func TestPath(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+
+	tree := NewMerkleTree()
+	for i := 0; i < 5; i++ {
+		tree.Add([]byte("Leaf" + strconv.Itoa(i)))
+	}
+
+	nodes, err := tree.Path(4)
+	et.Assert(err == nil, "Error was thrown for fetching the path")
+	et.Assert(len(nodes) == 4, "The path did not have node for every level")
+	h, _ := tree.HashAt(4)
+	et.Assert(nodes[0].Hash() == h, "The path did not start with the leaf")
+	et.Assert(nodes[1].Index() == 2 && nodes[2].Index() == 1, "The indexes of the path nodes were not correct")
+	et.Assert(nodes[3].Hash() == tree.Root(), "The path did not end with the root")
+
+	_, err = tree.Path(5)
+	et.Assert(err != nil, "Error was not thrown on index out of bounds")
+	et.Assert(err.Error() == outOfBounds, "Incorrect message was thrown on requesting path at index out of bounds")
+}
+
+func TestRootAt(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree := NewMerkleTree()
+	_, err := tree.RootAt(0)
+	et.Assert(err != nil && err.Error() == invalidSize, "Error was not thrown for empty tree")
+
+	roots := make([]string, 0)
+	for i := 0; i < 17; i++ {
+		tree.Add([]byte("Leaf" + strconv.Itoa(i)))
+		roots = append(roots, tree.Root())
+	}
+	for size := 1; size <= 17; size++ {
+		root, err := tree.RootAt(size)
+		et.Assert(err == nil && root == roots[size-1], "The root at size "+strconv.Itoa(size)+" was not the root the tree had")
+	}
+
+	_, err = tree.RootAt(18)
+	et.Assert(err != nil && err.Error() == invalidSize, "Error was not thrown for size bigger than the tree")
+}
+
 func TestMarshalJSON(t *testing.T) {
 	et := merkletreetest.WrapTesting(t)
 
@@ -0,0 +1,102 @@
+package memory
+
+import (
+	"crypto/rand"
+	"errors"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// SaltSize is the count of random bytes every salted leaf is hashed with
+const SaltSize = 32
+
+const (
+	notSalted   = "Incorrect index - The leaf at the index is not salted"
+	invalidSalt = "Incorrect salt - Expected SaltSize bytes"
+)
+
+// Disclosure is everything a holder needs to reveal the data of a single salted leaf - the data, the salt it was hashed with,
+// the index of the leaf and the intermediary hashes up to the root. The data is hex encoded in JSON, as it can be any bytes.
+// The fields match the body of the validateapi request, so a disclosure can be posted as it is
+type Disclosure struct {
+	Data   hexutil.Bytes `json:"data"`
+	Salt   string        `json:"salt"`
+	Index  int           `json:"index"`
+	Hashes []string      `json:"hashes"`
+}
+
+// SaltedHash returns keccak256(salt || data)
+func SaltedHash(salt, data []byte) string {
+	return crypto.Keccak256Hash(salt, data).Hex()
+}
+
+// NewSalt returns SaltSize random bytes
+func NewSalt() ([]byte, error) {
+	salt := make([]byte, SaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return salt, nil
+}
+
+// AddSalted generates random salt, inserts keccak256(salt || data) on the next available slot in the tree and keeps
+// the salt with the leaf. Without the salt the published hashes can not be brute-forced back to low entropy data.
+// The salt is kept only in memory, use persistent.MerkleTree.AppendSalted to save it in a store.
+// Returns the disclosure of the data against the current root
+func (tree *MerkleTree) AddSalted(data []byte) (*Disclosure, error) {
+	salt, err := NewSalt()
+	if err != nil {
+		return nil, err
+	}
+
+	tree.Mutex.RLock()
+	index := tree.insert(SaltedHash(salt, data))
+	tree.Nodes[0][index].salt = salt
+	tree.Mutex.RUnlock()
+
+	return tree.Disclose(index, data)
+}
+
+// SetSalt keeps the salt with the leaf at the given index, e.g. when the tree is loaded from a store.
+// The leaf is expected to be keccak256(salt || data)
+func (tree *MerkleTree) SetSalt(index int, salt []byte) error {
+	if len(salt) != SaltSize {
+		return errors.New(invalidSalt)
+	}
+
+	tree.Mutex.Lock()
+	defer tree.Mutex.Unlock()
+
+	if index < 0 || index >= len(tree.Nodes[0]) {
+		return errors.New(outOfBounds)
+	}
+	tree.Nodes[0][index].salt = append([]byte{}, salt...)
+	return nil
+}
+
+// Disclose returns the disclosure of the data of the salted leaf at the given index against the current root.
+// Returns error if the leaf is not salted
+func (tree *MerkleTree) Disclose(index int, data []byte) (*Disclosure, error) {
+	if index < 0 || index >= len(tree.Nodes[0]) {
+		return nil, errors.New(outOfBounds)
+	}
+	salt := tree.Nodes[0][index].salt
+	if salt == nil {
+		return nil, errors.New(notSalted)
+	}
+
+	intermediaryHashes, err := tree.IntermediaryHashesByIndex(index)
+	if err != nil {
+		return nil, err
+	}
+	return &Disclosure{append(hexutil.Bytes{}, data...), hexutil.Encode(salt), index, intermediaryHashes}, nil
+}
+
+// VerifyDisclosure validates without the tree that the disclosed data is in a tree with the given root
+func VerifyDisclosure(root string, disclosure *Disclosure) bool {
+	salt, err := hexutil.Decode(disclosure.Salt)
+	if err != nil || len(salt) == 0 {
+		return false
+	}
+	return Verify(root, disclosure.Index, SaltedHash(salt, disclosure.Data), disclosure.Hashes)
+}
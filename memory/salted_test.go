@@ -0,0 +1,67 @@
+package memory
+
+import (
+	"encoding/json"
+	"github.com/LimeChain/merkletree/merkletreetest"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"testing"
+)
+
+func TestAddSalted(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree := NewMerkleTree()
+	tree.Add([]byte("First Leaf"))
+
+	d1, err := tree.AddSalted([]byte("1990-01-01"))
+	et.Assert(err == nil, "Error was thrown on adding salted data")
+	d2, err := tree.AddSalted([]byte("1990-01-01"))
+	et.Assert(err == nil, "Error was thrown on adding salted data")
+
+	et.Assert(d1.Index == 1 && d2.Index == 2, "The indexes of the salted leafs were not correct")
+	et.Assert(string(d1.Data) == "1990-01-01", "The disclosure did not contain the data")
+	et.Assert(d1.Salt != d2.Salt, "The same salt was generated twice")
+
+	salt, _ := hexutil.Decode(d2.Salt)
+	et.Assert(len(salt) == SaltSize, "The salt was not of SaltSize bytes")
+	h, _ := tree.HashAt(2)
+	et.Assert(h == SaltedHash(salt, []byte("1990-01-01")), "The leaf was not the hash of the salt and the data")
+	h1, _ := tree.HashAt(1)
+	et.Assert(h != h1, "The leafs of the same data were equal")
+	et.Assert(string(tree.Nodes[0][2].Salt()) == string(salt), "The salt was not kept with the leaf")
+	et.Assert(tree.Nodes[0][0].Salt() == nil, "The unsalted leaf had salt")
+
+	et.Assert(VerifyDisclosure(tree.Root(), d2), "The disclosure was not verified")
+	valid, err := tree.ValidateExistence(append(salt, []byte("1990-01-01")...), 2, d2.Hashes)
+	et.Assert(err == nil && valid, "The salted data was not validated as salt followed by data")
+
+	// The proof of the first disclosure is outdated after the second addition
+	et.Assert(!VerifyDisclosure(tree.Root(), d1), "The outdated disclosure was verified")
+	d1, err = tree.Disclose(1, []byte("1990-01-01"))
+	et.Assert(err == nil, "Error was thrown on disclosing salted leaf")
+	et.Assert(VerifyDisclosure(tree.Root(), d1), "The renewed disclosure was not verified")
+
+	d1.Data = []byte("1990-01-02")
+	et.Assert(!VerifyDisclosure(tree.Root(), d1), "The disclosure of wrong data was verified")
+	d1.Salt = "0x"
+	et.Assert(!VerifyDisclosure(tree.Root(), d1), "The disclosure without salt was verified")
+
+	// Data that is not UTF-8 is disclosed as it is
+	binary := []byte{0xff, 0xfe, 0x00, 0x80}
+	d3, _ := tree.AddSalted(binary)
+	encoded, _ := json.Marshal(d3)
+	decoded := &Disclosure{}
+	err = json.Unmarshal(encoded, decoded)
+	et.Assert(err == nil && string(decoded.Data) == string(binary), "The data did not survive the JSON round trip")
+	et.Assert(VerifyDisclosure(tree.Root(), decoded), "The disclosure of binary data was not verified")
+
+	err = tree.SetSalt(0, salt)
+	et.Assert(err == nil && string(tree.Nodes[0][0].Salt()) == string(salt), "The salt was not set")
+	et.Assert(tree.SetSalt(0, salt[1:]).Error() == invalidSalt, "Incorrect error was thrown on short salt")
+	et.Assert(tree.SetSalt(4, salt).Error() == outOfBounds, "Incorrect error was thrown on setting salt out of bounds")
+	tree.Nodes[0][0].salt = nil
+
+	_, err = tree.Disclose(0, []byte("First Leaf"))
+	et.Assert(err != nil && err.Error() == notSalted, "Incorrect error was thrown on disclosing unsalted leaf")
+	_, err = tree.Disclose(4, []byte("First Leaf"))
+	et.Assert(err != nil && err.Error() == outOfBounds, "Incorrect error was thrown on disclosing out of bounds index")
+}
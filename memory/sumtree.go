@@ -0,0 +1,261 @@
+package memory
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"math/big"
+	"sync"
+)
+
+const (
+	negativeSum = "Incorrect balance - Balance must not be negative"
+	missingSum  = "Incorrect balance - Balance must be set"
+	sumOverflow = "Incorrect balance - Sum does not fit in 256 bits"
+)
+
+// SumNode is a single node or leaf in the merkle sum tree. Besides the hash it carries the sum of all leafs below it
+type SumNode struct {
+	hash  common.Hash
+	sum   *big.Int
+	index int
+}
+
+// Hash returns the string representation of the hash of the node
+func (node *SumNode) Hash() string {
+	return node.hash.Hex()
+}
+
+// Index returns the index of this node in its level
+func (node *SumNode) Index() int {
+	return node.index
+}
+
+// Sum returns the sum of all leafs below this node
+func (node *SumNode) Sum() *big.Int {
+	return new(big.Int).Set(node.sum)
+}
+
+// String returns the hash of this node. Alias to Hash()
+func (node SumNode) String() string {
+	return node.Hash()
+}
+
+// SumProof carries the sibling hashes and sums needed to produce the root and the total from a leaf
+type SumProof struct {
+	Index  int      `json:"index"`
+	Hashes []string `json:"hashes"`
+	Sums   []string `json:"sums"`
+}
+
+// SumTree is a merkle sum tree. Every leaf hashes (data, balance) and every parent hashes (left, right, leftSum, rightSum)
+// and carries leftSum + rightSum, so the root commits both to the balance of every leaf and to their total. Unlike MerkleTree the odd node is paired with an empty
+// node (zero hash and zero sum) instead of itself, as duplicating it would count its sum twice
+type SumTree struct {
+	Nodes [][]*SumNode
+	Mutex sync.RWMutex
+}
+
+func emptySumNode(index int) *SumNode {
+	return &SumNode{common.Hash{}, new(big.Int), index}
+}
+
+// SumLeafHash returns the hash of the leaf with the given data and balance, keccak256(data || uint256(balance)).
+// Hashing the balance binds it to the leaf, so even the root of a single leaf commits to its sum
+func SumLeafHash(data []byte, balance *big.Int) common.Hash {
+	return crypto.Keccak256Hash(data, math.PaddedBigBytes(balance, 32))
+}
+
+func sumHash(left, right common.Hash, leftSum, rightSum *big.Int) common.Hash {
+	return crypto.Keccak256Hash(left[:], right[:], math.PaddedBigBytes(leftSum, 32), math.PaddedBigBytes(rightSum, 32))
+}
+
+func createSumParent(left, right *SumNode) *SumNode {
+	return &SumNode{
+		hash:  sumHash(left.hash, right.hash, left.sum, right.sum),
+		sum:   new(big.Int).Add(left.sum, right.sum),
+		index: left.index / 2,
+	}
+}
+
+func (tree *SumTree) getNodeSibling(level int, index int) *SumNode {
+	sibling := index ^ 1
+	if sibling >= len(tree.Nodes[level]) {
+		return emptySumNode(sibling)
+	}
+	return tree.Nodes[level][sibling]
+}
+
+func (tree *SumTree) propagateChange() {
+	for i := 0; len(tree.Nodes[i]) > 1; i++ {
+		if len(tree.Nodes) == i+1 {
+			tree.Nodes = append(tree.Nodes, make([]*SumNode, 0, 1))
+		}
+
+		last := len(tree.Nodes[i]) - 1
+		left := tree.Nodes[i][last&^1]
+		right := tree.getNodeSibling(i, last&^1)
+		parent := createSumParent(left, right)
+
+		if parent.index == len(tree.Nodes[i+1]) {
+			tree.Nodes[i+1] = append(tree.Nodes[i+1], parent)
+		} else {
+			tree.Nodes[i+1][parent.index] = parent
+		}
+	}
+}
+
+// Add hashes the data with the given balance and inserts it on the next available slot in the tree.
+// Returns the index it was inserted and the hash of the new leaf, see SumLeafHash. Missing and negative balances are rejected
+func (tree *SumTree) Add(data []byte, balance *big.Int) (index int, hash string, err error) {
+	if balance == nil {
+		return -1, "", errors.New(missingSum)
+	}
+	if balance.Sign() < 0 {
+		return -1, "", errors.New(negativeSum)
+	}
+
+	tree.Mutex.Lock()
+	defer tree.Mutex.Unlock()
+
+	total := new(big.Int).Set(balance)
+	if root := tree.root(); root != nil {
+		total.Add(total, root.sum)
+	}
+	if total.BitLen() > 256 {
+		return -1, "", errors.New(sumOverflow)
+	}
+
+	h := SumLeafHash(data, balance)
+	index = len(tree.Nodes[0])
+	tree.Nodes[0] = append(tree.Nodes[0], &SumNode{h, new(big.Int).Set(balance), index})
+	tree.propagateChange()
+
+	return index, h.Hex(), nil
+}
+
+func (tree *SumTree) root() *SumNode {
+	top := tree.Nodes[len(tree.Nodes)-1]
+	if len(top) == 0 {
+		return nil
+	}
+	return top[0]
+}
+
+// Root returns the hash of the root of the tree
+func (tree *SumTree) Root() string {
+	tree.Mutex.RLock()
+	defer tree.Mutex.RUnlock()
+
+	root := tree.root()
+	if root == nil {
+		return ""
+	}
+	return root.Hash()
+}
+
+// Total returns the sum of the balances of all leafs
+func (tree *SumTree) Total() *big.Int {
+	tree.Mutex.RLock()
+	defer tree.Mutex.RUnlock()
+
+	root := tree.root()
+	if root == nil {
+		return new(big.Int)
+	}
+	return root.Sum()
+}
+
+// Length returns the count of the tree leafs
+func (tree *SumTree) Length() int {
+	tree.Mutex.RLock()
+	defer tree.Mutex.RUnlock()
+
+	return len(tree.Nodes[0])
+}
+
+// Proof returns the sibling hashes and sums needed to produce the root and the total from the given index
+func (tree *SumTree) Proof(index int) (*SumProof, error) {
+	tree.Mutex.RLock()
+	defer tree.Mutex.RUnlock()
+
+	return tree.proof(index)
+}
+
+// ProofWithTotal returns the proof for the given index together with the root and the total it was produced against
+func (tree *SumTree) ProofWithTotal(index int) (proof *SumProof, root string, total *big.Int, err error) {
+	tree.Mutex.RLock()
+	defer tree.Mutex.RUnlock()
+
+	proof, err = tree.proof(index)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	rootNode := tree.root()
+	return proof, rootNode.Hash(), rootNode.Sum(), nil
+}
+
+func (tree *SumTree) proof(index int) (*SumProof, error) {
+	if index < 0 || index >= len(tree.Nodes[0]) {
+		return nil, errors.New(outOfBounds)
+	}
+
+	proof := &SumProof{Index: index, Hashes: make([]string, 0), Sums: make([]string, 0)}
+	for level := 0; len(tree.Nodes[level]) > 1; level++ {
+		sibling := tree.getNodeSibling(level, index)
+		proof.Hashes = append(proof.Hashes, sibling.Hash())
+		proof.Sums = append(proof.Sums, sibling.sum.String())
+		index /= 2
+	}
+
+	return proof, nil
+}
+
+// MarshalJSON Creates JSON version of the needed fields of the tree
+func (tree *SumTree) MarshalJSON() ([]byte, error) {
+	res := fmt.Sprintf("{\"root\":\"%v\", \"total\":\"%v\", \"length\":%v}", tree.Root(), tree.Total(), tree.Length())
+	return []byte(res), nil
+}
+
+// VerifySumProof emulates how third party would validate their leaf. Given the original data, the balance and the proof,
+// it validates that the leaf is included in the tree with the published root and total
+func VerifySumProof(original []byte, balance *big.Int, proof *SumProof, root string, total *big.Int) bool {
+	if balance == nil || total == nil || proof == nil {
+		return false
+	}
+	if balance.Sign() < 0 || balance.BitLen() > 256 || len(proof.Hashes) != len(proof.Sums) {
+		return false
+	}
+
+	hash := SumLeafHash(original, balance)
+	sum := new(big.Int).Set(balance)
+	index := proof.Index
+
+	for i, h := range proof.Hashes {
+		siblingHash := common.HexToHash(h)
+		siblingSum, ok := new(big.Int).SetString(proof.Sums[i], 10)
+		if !ok || siblingSum.Sign() < 0 || siblingSum.BitLen() > 256 {
+			return false
+		}
+
+		if index%2 == 0 {
+			hash = sumHash(hash, siblingHash, sum, siblingSum)
+		} else {
+			hash = sumHash(siblingHash, hash, siblingSum, sum)
+		}
+		sum.Add(sum, siblingSum)
+		if sum.BitLen() > 256 {
+			return false
+		}
+		index /= 2
+	}
+
+	return hash == common.HexToHash(root) && sum.Cmp(total) == 0
+}
+
+// NewSumTree returns a pointer to an initialized SumTree
+func NewSumTree() *SumTree {
+	return &SumTree{Nodes: make([][]*SumNode, 1)}
+}
@@ -0,0 +1,110 @@
+package memory
+
+import (
+	"fmt"
+	"github.com/LimeChain/merkletree/merkletreetest"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"math/big"
+	"testing"
+)
+
+func TestSumTreeAdd(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree := NewSumTree()
+
+	et.Assert(tree.Root() == "", "The root was not empty after init")
+	et.Assert(tree.Total().Sign() == 0, "The total was not 0 after init")
+
+	u256 := func(v int64) []byte {
+		return math.PaddedBigBytes(big.NewInt(v), 32)
+	}
+
+	data1 := []byte("alice")
+	dh1 := crypto.Keccak256Hash(data1, u256(10))
+	i, h, err := tree.Add(data1, big.NewInt(10))
+
+	et.Assert(err == nil, "Error was thrown on addition")
+	et.Assert(i == 0, "The index of first addition was not 0")
+	et.Assert(h == dh1.Hex(), "The hash of the added node was not the keccak256 hash of the data and the balance")
+	et.Assert(tree.Root() == dh1.Hex(), "The hash of the root was not equal to the only element")
+
+	data2 := []byte("bob")
+	dh2 := crypto.Keccak256Hash(data2, u256(20))
+	tree.Add(data2, big.NewInt(20))
+
+	data3 := []byte("carol")
+	dh3 := crypto.Keccak256Hash(data3, u256(5))
+	tree.Add(data3, big.NewInt(5))
+	left := crypto.Keccak256Hash(dh1[:], dh2[:], u256(10), u256(20))
+	right := crypto.Keccak256Hash(dh3[:], common.Hash{}.Bytes(), u256(5), u256(0))
+	expectedRoot := crypto.Keccak256Hash(left[:], right[:], u256(30), u256(5))
+
+	et.Assert(tree.Root() == expectedRoot.Hex(), "The root was not correctly calculated")
+	et.Assert(tree.Total().Int64() == 35, "The total was not the sum of the balances")
+	et.Assert(tree.Length() == 3, "The length was not 3 after 3 additions")
+
+	_, _, err = tree.Add([]byte("mallory"), big.NewInt(-1))
+	et.Assert(err != nil, "Error was not thrown on negative balance")
+	et.Assert(err.Error() == negativeSum, "Incorrect message was thrown on negative balance")
+	et.Assert(tree.Length() == 3, "The negative balance was added")
+
+	_, _, err = tree.Add([]byte("mallory"), math.MaxBig256)
+	et.Assert(err != nil, "Error was not thrown on overflowing the total")
+	et.Assert(err.Error() == sumOverflow, "Incorrect message was thrown on overflowing the total")
+
+	_, _, err = tree.Add([]byte("mallory"), nil)
+	et.Assert(err != nil && err.Error() == missingSum, "Incorrect error was thrown on missing balance", err)
+	et.Assert(tree.Length() == 3, "The missing balance was added")
+}
+
+func TestSumTreeSingleLeaf(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree := NewSumTree()
+	tree.Add([]byte("alice"), big.NewInt(100))
+
+	proof, _ := tree.Proof(0)
+	et.Assert(VerifySumProof([]byte("alice"), big.NewInt(100), proof, tree.Root(), tree.Total()), "Could not verify the single leaf")
+	et.Assert(!VerifySumProof([]byte("alice"), big.NewInt(5), proof, tree.Root(), big.NewInt(5)), "Verified the single leaf with another balance and total")
+	et.Assert(!VerifySumProof([]byte("alice"), nil, proof, tree.Root(), tree.Total()), "Verified missing balance")
+	et.Assert(!VerifySumProof([]byte("alice"), big.NewInt(100), nil, tree.Root(), tree.Total()), "Verified missing proof")
+	et.Assert(!VerifySumProof([]byte("alice"), big.NewInt(100), proof, tree.Root(), nil), "Verified missing total")
+}
+
+func TestSumTreeProof(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree := NewSumTree()
+
+	_, err := tree.Proof(0)
+	et.Assert(err != nil, "Error was not thrown on empty tree")
+
+	for n := 1; n <= 11; n++ {
+		tree.Add([]byte(fmt.Sprintf("user%v", n-1)), big.NewInt(int64(n-1)*100))
+		for i := 0; i < n; i++ {
+			data := []byte(fmt.Sprintf("user%v", i))
+			balance := big.NewInt(int64(i) * 100)
+			proof, err := tree.Proof(i)
+
+			et.Assert(err == nil, "Error was thrown for proof")
+			et.Assert(VerifySumProof(data, balance, proof, tree.Root(), tree.Total()), "Could not verify leaf", i, "in tree of", n)
+			et.Assert(!VerifySumProof(data, big.NewInt(int64(i)*100+1), proof, tree.Root(), tree.Total()), "Verified wrong balance", i)
+			et.Assert(!VerifySumProof(data, balance, proof, tree.Root(), big.NewInt(1)), "Verified wrong total", i)
+			if len(proof.Sums) > 0 {
+				proof.Sums[0] = "-1"
+				et.Assert(!VerifySumProof(data, balance, proof, tree.Root(), tree.Total()), "Verified negative sibling sum", i)
+			}
+		}
+	}
+}
+
+func TestSumTreeMarshalJSON(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree := NewSumTree()
+	_, h, _ := tree.Add([]byte("alice"), big.NewInt(10))
+
+	expected := fmt.Sprintf(`{"root":"%v", "total":"10", "length":1}`, h)
+	received, _ := tree.MarshalJSON()
+
+	et.Assert(string(received) == expected, string(received))
+}
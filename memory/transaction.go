@@ -0,0 +1,73 @@
+package memory
+
+import (
+	"errors"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	transactionClosed   = "Transaction already committed or rolled back"
+	transactionConflict = "Incorrect tree state - The tree was changed since the transaction began"
+)
+
+// Transaction is implementation of merkletree.Transaction. It buffers the added hashes and
+// inserts them in the tree only on Commit, so the new root is published all at once
+type Transaction struct {
+	tree   *MerkleTree
+	start  int
+	hashes []string
+	closed bool
+}
+
+// Add hashes the data and buffers it for insertion on Commit.
+// Returns the index it will be inserted at and the hash of the data, or -1 and empty hash if the transaction is closed
+func (tx *Transaction) Add(data []byte) (index int, hash string) {
+	if tx.closed {
+		return -1, ""
+	}
+	h := crypto.Keccak256Hash(data)
+	index = tx.Insert(h.Hex())
+	return index, h.Hex()
+}
+
+// Insert buffers the hash for insertion on Commit
+// Returns the index it will be inserted at, or -1 if the transaction is closed
+func (tx *Transaction) Insert(hash string) (index int) {
+	if tx.closed {
+		return -1
+	}
+	index = tx.start + len(tx.hashes)
+	tx.hashes = append(tx.hashes, hash)
+	return index
+}
+
+// Commit inserts all buffered hashes in the tree and returns the new root.
+// Fails if the tree was changed by anyone else since the transaction began
+func (tx *Transaction) Commit() (root string, err error) {
+	if tx.closed {
+		return "", errors.New(transactionClosed)
+	}
+	tx.tree.Mutex.Lock()
+	defer tx.tree.Mutex.Unlock()
+
+	if len(tx.tree.Nodes[0]) != tx.start {
+		return "", errors.New(transactionConflict)
+	}
+	tx.closed = true
+
+	for _, hash := range tx.hashes {
+		tx.tree.insert(hash)
+	}
+
+	return tx.tree.Root(), nil
+}
+
+// Rollback discards all buffered hashes
+func (tx *Transaction) Rollback() error {
+	if tx.closed {
+		return errors.New(transactionClosed)
+	}
+	tx.closed = true
+	tx.hashes = nil
+	return nil
+}
@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"github.com/LimeChain/merkletree"
+	"github.com/LimeChain/merkletree/merkletreetest"
+	"github.com/ethereum/go-ethereum/crypto"
+	"testing"
+)
+
+func TestBegin(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree := NewMerkleTree()
+	_, isTransactional := interface{}(tree).(merkletree.TransactionalMerkleTree)
+	et.Assert(isTransactional, "The tree did not implement the TransactionalMerkleTree interface")
+
+	tree.Add([]byte("First Leaf"))
+	rootBefore := tree.Root()
+
+	tx, err := tree.Begin()
+	et.Assert(err == nil, "Error was thrown on beginning a transaction")
+
+	i, h := tx.Add([]byte("Second Leaf"))
+	et.Assert(i == 1, "The index of the first transaction addition was not 1")
+	et.Assert(h == crypto.Keccak256Hash([]byte("Second Leaf")).Hex(), "The hash of the transaction addition was not the keccak256 hash of the data")
+
+	dh3 := crypto.Keccak256Hash([]byte("Third Leaf"))
+	i = tx.Insert(dh3.Hex())
+	et.Assert(i == 2, "The index of the second transaction addition was not 2")
+
+	et.Assert(tree.Root() == rootBefore, "The root was changed before commit")
+	et.Assert(tree.Length() == 1, "The length was changed before commit")
+
+	root, err := tx.Commit()
+	et.Assert(err == nil, "Error was thrown on commit")
+
+	expected := NewMerkleTree()
+	expected.Add([]byte("First Leaf"))
+	expected.Add([]byte("Second Leaf"))
+	expected.Add([]byte("Third Leaf"))
+
+	et.Assert(root == expected.Root(), "The returned root was not the root of all additions")
+	et.Assert(tree.Root() == expected.Root(), "The root was not published on commit")
+	et.Assert(tree.Length() == 3, "The length was not updated on commit")
+
+	_, err = tx.Commit()
+	et.Assert(err != nil, "Error was not thrown on second commit")
+	et.Assert(err.Error() == transactionClosed, "Incorrect message was thrown on second commit")
+}
+
+func TestRollback(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree := NewMerkleTree()
+	tree.Add([]byte("First Leaf"))
+	rootBefore := tree.Root()
+
+	tx, _ := tree.Begin()
+	tx.Add([]byte("Second Leaf"))
+	err := tx.Rollback()
+
+	et.Assert(err == nil, "Error was thrown on rollback")
+	et.Assert(tree.Root() == rootBefore, "The root was changed after rollback")
+	et.Assert(tree.Length() == 1, "The length was changed after rollback")
+
+	_, err = tx.Commit()
+	et.Assert(err != nil, "Error was not thrown on commit after rollback")
+	et.Assert(tx.Rollback() != nil, "Error was not thrown on second rollback")
+
+	index, hash := tx.Add([]byte("Third Leaf"))
+	et.Assert(index == -1 && hash == "", "Add after rollback did not return -1 and empty hash")
+	et.Assert(tx.Insert(hash) == -1, "Insert after rollback did not return -1")
+	et.Assert(tree.Length() == 1, "The length was changed by addition after rollback")
+}
+
+func TestCommitConflict(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree := NewMerkleTree()
+
+	tx, _ := tree.Begin()
+	tx.Add([]byte("First Leaf"))
+	tree.Add([]byte("Other Leaf"))
+
+	_, err := tx.Commit()
+	et.Assert(err != nil, "Error was not thrown on commit after concurrent change")
+	et.Assert(err.Error() == transactionConflict, "Incorrect message was thrown on conflicting commit")
+	et.Assert(tree.Length() == 1, "The conflicting transaction was applied")
+}
@@ -0,0 +1,187 @@
+package merkletreetest
+
+import (
+	"errors"
+	"github.com/LimeChain/merkletree"
+	"sync"
+	"time"
+)
+
+const (
+	outOfOrder  = "Incorrect index - The leafs must be appended at the end of the store"
+	invalidSize = "Incorrect size - Size out of bounds"
+	notFound    = "Incorrect node - The node is not stored"
+	noRoot      = "Incorrect root - No root was recorded"
+)
+
+// MemoryStore is in-memory implementation of merkletree.NodeStore, merkletree.SaltStore and merkletree.RootHistory to be
+// used in tests instead of a database. Setting Err makes all writes fail with it
+type MemoryStore struct {
+	Leafs []string
+	Nodes map[[2]int]string
+	Salts map[int][]byte
+	Roots []merkletree.RootRecord
+	Err   error
+	mutex sync.Mutex
+}
+
+// AppendLeaves appends the hashes at the end of the stored leafs. The start must be the current count of the leafs
+func (store *MemoryStore) AppendLeaves(start int, hashes []string) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if store.Err != nil {
+		return store.Err
+	}
+	if start != len(store.Leafs) {
+		return errors.New(outOfOrder)
+	}
+	store.Leafs = append(store.Leafs, hashes...)
+	return nil
+}
+
+// TruncateLeaves removes the leafs, their salts and roots and the nodes from the given size onwards
+func (store *MemoryStore) TruncateLeaves(size int) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if store.Err != nil {
+		return store.Err
+	}
+	if size < 0 || size > len(store.Leafs) {
+		return errors.New(invalidSize)
+	}
+	store.Leafs = store.Leafs[:size]
+	for index := range store.Salts {
+		if index >= size {
+			delete(store.Salts, index)
+		}
+	}
+	roots := store.Roots[:0]
+	for _, record := range store.Roots {
+		if record.Size <= size {
+			roots = append(roots, record)
+		}
+	}
+	store.Roots = roots
+	for key := range store.Nodes {
+		level, index := uint(key[0]), key[1]
+		if index<<level >= size || (level > 0 && 1<<(level-1) >= size) { // Past the last leaf or above the new root
+			delete(store.Nodes, key)
+		}
+	}
+	return nil
+}
+
+// LoadLeaves returns copy of the stored leafs
+func (store *MemoryStore) LoadLeaves() ([]string, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	return append([]string{}, store.Leafs...), nil
+}
+
+// IterateLeaves calls fn with every stored leaf in order
+func (store *MemoryStore) IterateLeaves(fn func(index int, hash string) error) error {
+	leafs, _ := store.LoadLeaves()
+	for i, hash := range leafs {
+		if err := fn(i, hash); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SaveNodes stores the nodes, replacing the ones stored at the same level and index
+func (store *MemoryStore) SaveNodes(nodes []merkletree.StoredNode) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if store.Err != nil {
+		return store.Err
+	}
+	if store.Nodes == nil {
+		store.Nodes = make(map[[2]int]string)
+	}
+	for _, node := range nodes {
+		store.Nodes[[2]int{node.Level, node.Index}] = node.Hash
+	}
+	return nil
+}
+
+// LoadNode returns the hash of the node stored at the given level and index
+func (store *MemoryStore) LoadNode(level int, index int) (string, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	hash, ok := store.Nodes[[2]int{level, index}]
+	if !ok {
+		return "", errors.New(notFound)
+	}
+	return hash, nil
+}
+
+// SaveSalt stores the salt of the leaf at the given index, replacing the one stored at the same index
+func (store *MemoryStore) SaveSalt(index int, salt []byte) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if store.Err != nil {
+		return store.Err
+	}
+	if store.Salts == nil {
+		store.Salts = make(map[int][]byte)
+	}
+	store.Salts[index] = append([]byte{}, salt...)
+	return nil
+}
+
+// LoadSalts returns copy of the stored salts by the index of their leafs
+func (store *MemoryStore) LoadSalts() (map[int][]byte, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	salts := make(map[int][]byte, len(store.Salts))
+	for index, salt := range store.Salts {
+		salts[index] = append([]byte{}, salt...)
+	}
+	return salts, nil
+}
+
+// RecordRoot appends the root to the history with the current time
+func (store *MemoryStore) RecordRoot(size int, root string) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if store.Err != nil {
+		return store.Err
+	}
+	store.Roots = append(store.Roots, merkletree.RootRecord{Size: size, Root: root, CreatedAt: time.Now()})
+	return nil
+}
+
+// RootAtSize returns the last root recorded at the given size
+func (store *MemoryStore) RootAtSize(size int) (merkletree.RootRecord, error) {
+	return store.lastRoot(func(record merkletree.RootRecord) bool {
+		return record.Size == size
+	})
+}
+
+// RootAtTime returns the last root recorded at or before the given time
+func (store *MemoryStore) RootAtTime(t time.Time) (merkletree.RootRecord, error) {
+	return store.lastRoot(func(record merkletree.RootRecord) bool {
+		return !record.CreatedAt.After(t)
+	})
+}
+
+func (store *MemoryStore) lastRoot(match func(record merkletree.RootRecord) bool) (merkletree.RootRecord, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	for i := len(store.Roots) - 1; i >= 0; i-- {
+		if match(store.Roots[i]) {
+			return store.Roots[i], nil
+		}
+	}
+	return merkletree.RootRecord{}, errors.New(noRoot)
+}
@@ -0,0 +1,259 @@
+// Package mmr implements Merkle Mountain Range stored in the memory of the system.
+// The node layout, the proof format and the peak bagging follow the widely used
+// nervosnetwork merkle-mountain-range library, using keccak256 as the merge function
+package mmr
+
+import (
+	"errors"
+	"fmt"
+	"github.com/LimeChain/merkletree"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"math/bits"
+	"strings"
+	"sync"
+)
+
+const (
+	outOfBounds    = "Incorrect index - Index out of bounds"
+	invalidSize    = "Incorrect size - Size out of bounds"
+	corruptedProof = "Incorrect proof - The proof does not match the positions"
+)
+
+// Node is implementation of types.Node and representation of a single leaf in the mountain range
+type Node struct {
+	hash     common.Hash
+	index    int
+	position uint64
+}
+
+// Hash returns the string representation of the hash of the node
+func (node *Node) Hash() string {
+	return node.hash.Hex()
+}
+
+// Index returns the index of this leaf among the leafs, as the index returned by Add and Insert
+func (node *Node) Index() int {
+	return node.index
+}
+
+// Position returns the position of this leaf among all nodes of the mountain range, as used by the proofs
+func (node *Node) Position() uint64 {
+	return node.position
+}
+
+// String returns the hash of this node. Alias to Hash()
+func (node Node) String() string {
+	return node.Hash()
+}
+
+// MerkleTree is a Merkle Mountain Range. All nodes are kept in post-order in a single slice,
+// so appending a leaf only touches the nodes merged on top of it
+type MerkleTree struct {
+	nodes []common.Hash
+	leafs int
+	root  string
+	mutex sync.RWMutex
+}
+
+func merge(left, right common.Hash) common.Hash {
+	return crypto.Keccak256Hash(left[:], right[:])
+}
+
+// Add hashes and inserts data on the next available slot in the mountain range.
+// Returns the index it was inserted and the hash of the new data
+func (tree *MerkleTree) Add(data []byte) (index int, hash string) {
+	h := crypto.Keccak256Hash(data)
+	index = tree.Insert(h.Hex())
+	return index, h.Hex()
+}
+
+// RawAdd adds data to the mountain range without bagging the peaks into a new root
+// Returns the index of the leaf and the hash of the data
+func (tree *MerkleTree) RawAdd(data []byte) (index int, hash string) {
+	h := crypto.Keccak256Hash(data)
+	index, _ = tree.RawInsert(h.Hex())
+	return index, h.Hex()
+}
+
+// RawInsert appends the hash as a leaf and merges the peaks below it, without bagging the peaks into a new root
+// Returns the index of the leaf and the node
+func (tree *MerkleTree) RawInsert(hash string) (index int, insertedLeaf merkletree.Node) {
+	tree.mutex.Lock()
+	index, leaf := tree.rawInsert(common.HexToHash(hash))
+	tree.mutex.Unlock()
+
+	return index, leaf
+}
+
+func (tree *MerkleTree) rawInsert(hash common.Hash) (index int, leaf *Node) {
+	index = tree.leafs
+	leaf = &Node{hash, index, uint64(len(tree.nodes))}
+	tree.nodes = append(tree.nodes, hash)
+	tree.leafs++
+
+	// Every trailing zero of the new leaf count means a peak of the same height to merge with
+	for h := 0; h < bits.TrailingZeros64(uint64(tree.leafs)); h++ {
+		right := uint64(len(tree.nodes) - 1)
+		left := right - siblingOffset(h)
+		tree.nodes = append(tree.nodes, merge(tree.nodes[left], tree.nodes[right]))
+	}
+
+	return index, leaf
+}
+
+// Insert appends the hash as a leaf and bags the peaks into the new root
+// Returns the index it was inserted at
+func (tree *MerkleTree) Insert(hash string) (index int) {
+	tree.mutex.Lock()
+	index, _ = tree.rawInsert(common.HexToHash(hash))
+	tree.root = tree.bagPeaks()
+	tree.mutex.Unlock()
+
+	return index
+}
+
+// Recalculate bags the current peaks and returns the hex string of the new root.
+// Great to be used with RawInsert when loading up the tree data.
+func (tree *MerkleTree) Recalculate() (treeRoot string) {
+	tree.mutex.Lock()
+	tree.root = tree.bagPeaks()
+	tree.mutex.Unlock()
+
+	return tree.root
+}
+
+// Truncate removes all leafs from the given size onwards together with the nodes merged on top of them.
+// Returns error if the size is negative or bigger than the current length of the tree
+func (tree *MerkleTree) Truncate(size int) error {
+	tree.mutex.Lock()
+	defer tree.mutex.Unlock()
+
+	if size < 0 || size > tree.leafs {
+		return errors.New(invalidSize)
+	}
+
+	tree.nodes = tree.nodes[:mmrSize(size)]
+	tree.leafs = size
+	tree.root = tree.bagPeaks()
+
+	return nil
+}
+
+func (tree *MerkleTree) bagPeaks() string {
+	peaks := peakPositions(uint64(len(tree.nodes)))
+	peakHashes := make([]common.Hash, len(peaks))
+	for i, p := range peaks {
+		peakHashes[i] = tree.nodes[p]
+	}
+	root, ok := bagPeaks(peakHashes)
+	if !ok {
+		return ""
+	}
+	return root.Hex()
+}
+
+// IntermediaryHashesByIndex returns all hashes needed to produce the root from the given index.
+// These are the peaks left of the leaf, the path up to its peak and the bagged peaks right of it
+func (tree *MerkleTree) IntermediaryHashesByIndex(index int) (intermediaryHashes []string, err error) {
+	tree.mutex.RLock()
+	defer tree.mutex.RUnlock()
+
+	if index < 0 || index >= tree.leafs {
+		return nil, errors.New(outOfBounds)
+	}
+
+	proof := tree.proof([]uint64{leafPosition(index)})
+	return hexes(proof), nil
+}
+
+// ValidateExistence emulates how third party would validate the data. Given original data, the index it is supposed to be and the intermediaryHashes,
+// the method validates that this is the correct data for that slot. Use VerifyProof to validate without the tree
+func (tree *MerkleTree) ValidateExistence(original []byte, index int, intermediaryHashes []string) (result bool, err error) {
+	tree.mutex.RLock()
+	defer tree.mutex.RUnlock()
+
+	if index < 0 || index >= tree.leafs {
+		return false, errors.New(outOfBounds)
+	}
+	leafHash := crypto.Keccak256Hash(original)
+
+	if leafHash != tree.nodes[leafPosition(index)] {
+		return false, nil
+	}
+
+	return VerifyProof(tree.root, uint64(len(tree.nodes)), index, leafHash.Hex(), intermediaryHashes)
+}
+
+// HashAt returns the hash of the leaf at given index
+func (tree *MerkleTree) HashAt(index int) (string, error) {
+	tree.mutex.RLock()
+	defer tree.mutex.RUnlock()
+
+	if index < 0 || index >= tree.leafs {
+		return "", errors.New(outOfBounds)
+	}
+	return tree.nodes[leafPosition(index)].Hex(), nil
+}
+
+// Root returns the bagged peaks of the mountain range
+func (tree *MerkleTree) Root() string {
+	tree.mutex.RLock()
+	defer tree.mutex.RUnlock()
+
+	return tree.root
+}
+
+// Length returns the count of the leafs
+func (tree *MerkleTree) Length() int {
+	tree.mutex.RLock()
+	defer tree.mutex.RUnlock()
+
+	return tree.leafs
+}
+
+// Size returns the count of all nodes in the mountain range. This is the size the proofs are verified against
+func (tree *MerkleTree) Size() uint64 {
+	tree.mutex.RLock()
+	defer tree.mutex.RUnlock()
+
+	return uint64(len(tree.nodes))
+}
+
+// Peaks returns the hashes of the peaks from left to right
+func (tree *MerkleTree) Peaks() []string {
+	tree.mutex.RLock()
+	defer tree.mutex.RUnlock()
+
+	peaks := peakPositions(uint64(len(tree.nodes)))
+	peakHashes := make([]string, len(peaks))
+	for i, p := range peaks {
+		peakHashes[i] = tree.nodes[p].Hex()
+	}
+	return peakHashes
+}
+
+// String returns human readable version of the mountain range
+func (tree *MerkleTree) String() string {
+	tree.mutex.RLock()
+	defer tree.mutex.RUnlock()
+
+	b := strings.Builder{}
+	b.WriteString(fmt.Sprintf("Size: %v, Leafs: %v\n", len(tree.nodes), tree.leafs))
+	for _, p := range peakPositions(uint64(len(tree.nodes))) {
+		b.WriteString(fmt.Sprintf("Peak: %v, Height: %v\n%v\n", p, height(p), tree.nodes[p].Hex()))
+	}
+
+	return b.String()
+}
+
+// MarshalJSON Creates JSON version of the needed fields of the tree
+func (tree *MerkleTree) MarshalJSON() ([]byte, error) {
+	res := fmt.Sprintf("{\"root\":\"%v\", \"length\":%v}", tree.Root(), tree.Length())
+	return []byte(res), nil
+}
+
+// NewMerkleTree returns a pointer to an initialized Merkle Mountain Range
+func NewMerkleTree() *MerkleTree {
+	return &MerkleTree{}
+}
@@ -0,0 +1,31 @@
+package mmr_test
+
+import (
+	"fmt"
+	"github.com/LimeChain/merkletree/mmr"
+	"strconv"
+)
+
+func Example() {
+	tree := mmr.NewMerkleTree()
+	for i := 0; i < 10; i++ {
+		tree.Add([]byte("hello" + strconv.Itoa(i)))
+	}
+	prevRoot := tree.Root()
+
+	data := "Merkle Trees Rock"
+	i, h := tree.Add([]byte(data))
+	intermediaryHashes, _ := tree.IntermediaryHashesByIndex(i)
+	exists, _ := mmr.VerifyProof(tree.Root(), tree.Size(), i, h, intermediaryHashes)
+	fmt.Printf("Element Exists: %v\n", exists)
+
+	proof, _ := tree.AncestryProof(10)
+	valid, _ := mmr.VerifyAncestry(prevRoot, tree.Root(), tree.Size(), proof)
+	fmt.Printf("Ancestry Valid: %v\n", valid)
+	fmt.Printf("Peaks: %v\n", len(tree.Peaks()))
+
+	// Output:
+	// Element Exists: true
+	// Ancestry Valid: true
+	// Peaks: 3
+}
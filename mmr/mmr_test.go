@@ -0,0 +1,268 @@
+package mmr
+
+import (
+	"fmt"
+	"github.com/LimeChain/merkletree"
+	"github.com/LimeChain/merkletree/merkletreetest"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"testing"
+)
+
+func leafData(i int) []byte {
+	return []byte(fmt.Sprintf("Leaf %v", i))
+}
+
+// expectedRoot builds every mountain separately out of the leafs and bags the peaks right to left
+func expectedRoot(leafs int) string {
+	var peaks []common.Hash
+	offset := 0
+	for h := 31; h >= 0; h-- {
+		if leafs&(1<<uint(h)) == 0 {
+			continue
+		}
+		level := make([]common.Hash, 1<<uint(h))
+		for i := range level {
+			level[i] = crypto.Keccak256Hash(leafData(offset + i))
+		}
+		for len(level) > 1 {
+			next := make([]common.Hash, len(level)/2)
+			for i := range next {
+				next[i] = crypto.Keccak256Hash(level[2*i][:], level[2*i+1][:])
+			}
+			level = next
+		}
+		peaks = append(peaks, level[0])
+		offset += 1 << uint(h)
+	}
+	if len(peaks) == 0 {
+		return ""
+	}
+	root := peaks[len(peaks)-1]
+	for i := len(peaks) - 2; i >= 0; i-- {
+		root = crypto.Keccak256Hash(root[:], peaks[i][:])
+	}
+	return root.Hex()
+}
+
+func TestNewMerkleTree(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree := NewMerkleTree()
+	et.Assert(tree.Root() == "", "The root was not empty after init")
+	et.Assert(tree.Length() == 0, "The length was not 0 after init")
+	_, isFullMerkleTree := interface{}(tree).(merkletree.FullMerkleTree)
+	et.Assert(isFullMerkleTree, "The tree did not implement the FullMerkleTree interface")
+}
+
+func TestPositions(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+
+	heights := []int{0, 0, 1, 0, 0, 1, 2, 0, 0, 1, 0, 0, 1, 2, 3}
+	for p, h := range heights {
+		et.Assert(height(uint64(p)) == h, "Incorrect height of position", p)
+	}
+
+	positions := []uint64{0, 1, 3, 4, 7, 8, 10, 11, 15}
+	for i, p := range positions {
+		et.Assert(leafPosition(i) == p, "Incorrect position of leaf", i)
+	}
+
+	et.Assert(fmt.Sprint(peakPositions(11)) == "[6 9 10]", "Incorrect peaks of size 11", peakPositions(11))
+	et.Assert(peakPositions(5) == nil, "Peaks were returned for invalid size 5")
+	et.Assert(mmrSize(6) == 10, "Incorrect size for 6 leafs")
+}
+
+// TestReferenceLayout checks the mountain range of 11 leafs drawn in the README of nervosnetwork merkle-mountain-range
+//
+//	          14
+//	       /       \
+//	     6          13
+//	   /   \       /   \
+//	  2     5     9     12     17
+//	 / \   /  \  / \   /  \   /  \
+//	0   1 3   4 7  8 10  11 15  16 18
+func TestReferenceLayout(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree := NewMerkleTree()
+	for i := 0; i < 11; i++ {
+		tree.Add(leafData(i))
+	}
+
+	et.Assert(tree.Size() == 19, "Incorrect size of 11 leafs", tree.Size())
+	et.Assert(fmt.Sprint(peakPositions(19)) == "[14 17 18]", "Incorrect peaks of size 19", peakPositions(19))
+	et.Assert(fmt.Sprint(peakPositions(22)) == "[14 21]", "Incorrect peaks of size 22", peakPositions(22))
+	et.Assert(fmt.Sprint(peakPositions(25)) == "[14 21 24]", "Incorrect peaks of size 25", peakPositions(25))
+	for i, p := range []uint64{0, 1, 3, 4, 7, 8, 10, 11, 15, 16, 18} {
+		et.Assert(leafPosition(i) == p, "Incorrect position of leaf", i)
+	}
+
+	n := tree.nodes
+	et.Assert(n[14] == merge(n[6], n[13]) && n[6] == merge(n[2], n[5]) && n[17] == merge(n[15], n[16]), "Incorrect merges")
+
+	// The peaks right of the proven leaf are bagged right to left into one hash, the ones left of it are kept as they are
+	root := merge(merge(n[18], n[17]), n[14])
+	et.Assert(tree.Root() == root.Hex(), "Incorrect bagging of the peaks")
+
+	proof, _ := tree.IntermediaryHashesByIndex(0)
+	expected := hexes([]common.Hash{n[1], n[5], n[13], merge(n[18], n[17])})
+	et.Assert(fmt.Sprint(proof) == fmt.Sprint(expected), "Incorrect proof of leaf 0", proof)
+
+	proof, _ = tree.IntermediaryHashesByIndex(9)
+	expected = hexes([]common.Hash{n[14], n[15], n[18]})
+	et.Assert(fmt.Sprint(proof) == fmt.Sprint(expected), "Incorrect proof of leaf 9", proof)
+
+	proof, _ = tree.IntermediaryHashesByIndex(10)
+	expected = hexes([]common.Hash{n[14], n[17]})
+	et.Assert(fmt.Sprint(proof) == fmt.Sprint(expected), "Incorrect proof of leaf 10", proof)
+}
+
+func TestAdd(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree := NewMerkleTree()
+
+	for i := 0; i < 33; i++ {
+		index, hash := tree.Add(leafData(i))
+		et.Assert(index == i, "Incorrect index of addition", i)
+		et.Assert(hash == crypto.Keccak256Hash(leafData(i)).Hex(), "The hash of the added leaf was not the keccak256 hash of the data")
+		et.Assert(tree.Root() == expectedRoot(i+1), "Incorrect root after addition", i)
+		et.Assert(tree.Size() == mmrSize(i+1), "Incorrect size after addition", i)
+	}
+
+	et.Assert(len(tree.Peaks()) == 2, "Incorrect count of peaks for 33 leafs")
+}
+
+func TestRawAdd(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree := NewMerkleTree()
+
+	for i := 0; i < 7; i++ {
+		tree.RawAdd(leafData(i))
+	}
+	et.Assert(tree.Root() == "", "The root was bagged before recalculation")
+
+	index, leaf := tree.RawInsert(crypto.Keccak256Hash(leafData(7)).Hex())
+	et.Assert(index == 7 && leaf.Index() == 7, "Incorrect index of the raw inserted leaf", leaf.Index())
+	et.Assert(leaf.(*Node).Position() == 11, "Incorrect position of the raw inserted leaf", leaf.(*Node).Position())
+	tree.Truncate(7)
+
+	root := tree.Recalculate()
+	et.Assert(root == expectedRoot(7), "Incorrect root after recalculation")
+	et.Assert(tree.Root() == root, "The recalculated root was not stored")
+}
+
+func TestIntermediaryHashesByIndex(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree := NewMerkleTree()
+
+	_, err := tree.IntermediaryHashesByIndex(0)
+	et.Assert(err != nil, "Error was not thrown on empty tree")
+	et.Assert(err.Error() == outOfBounds, "Incorrect message was thrown on fetching hashes by out of bounds index")
+
+	for n := 1; n <= 20; n++ {
+		tree.Add(leafData(n - 1))
+		for i := 0; i < n; i++ {
+			hashes, err := tree.IntermediaryHashesByIndex(i)
+			et.Assert(err == nil, "Error was thrown for intermediary hashes")
+
+			exists, err := tree.ValidateExistence(leafData(i), i, hashes)
+			et.Assert(err == nil, "Error was thrown on validation")
+			et.Assert(exists, "Could not validate leaf", i, "in tree of", n)
+
+			leafHash := crypto.Keccak256Hash(leafData(i)).Hex()
+			exists, _ = VerifyProof(tree.Root(), tree.Size(), i, leafHash, hashes)
+			et.Assert(exists, "Could not verify leaf", i, "in tree of", n)
+
+			exists, _ = VerifyProof(tree.Root(), tree.Size(), i, crypto.Keccak256Hash([]byte("Other")).Hex(), hashes)
+			et.Assert(!exists, "Verified wrong leaf", i, "in tree of", n)
+
+			if len(hashes) > 0 {
+				exists, _ = VerifyProof(tree.Root(), tree.Size(), i, leafHash, hashes[1:])
+				et.Assert(!exists, "Verified leaf with incomplete proof", i, "in tree of", n)
+			}
+		}
+	}
+
+	hashes, _ := tree.IntermediaryHashesByIndex(6)
+	exists, err := tree.ValidateExistence(leafData(5), 6, hashes)
+	et.Assert(err == nil, "Error was thrown on validating wrong data")
+	et.Assert(!exists, "Validated wrong data")
+
+	_, err = tree.ValidateExistence(leafData(5), 20, hashes)
+	et.Assert(err != nil, "Error was not thrown on validating out of bounds index")
+}
+
+func TestAncestryProof(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree := NewMerkleTree()
+
+	roots := []string{""}
+	for n := 1; n <= 20; n++ {
+		tree.Add(leafData(n - 1))
+		roots = append(roots, tree.Root())
+		for prev := 1; prev <= n; prev++ {
+			proof, err := tree.AncestryProof(prev)
+			et.Assert(err == nil, "Error was thrown for ancestry proof")
+
+			valid, err := VerifyAncestry(roots[prev], tree.Root(), tree.Size(), proof)
+			et.Assert(err == nil, "Error was thrown on ancestry verification")
+			et.Assert(valid, "Could not verify ancestry of", prev, "in tree of", n)
+
+			if prev < n {
+				valid, _ = VerifyAncestry(roots[prev+1], tree.Root(), tree.Size(), proof)
+				et.Assert(!valid, "Verified ancestry with wrong previous root", prev, n)
+			}
+		}
+	}
+
+	_, err := tree.AncestryProof(21)
+	et.Assert(err != nil, "Error was not thrown for ancestry proof of bigger size")
+}
+
+func TestTruncate(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree := NewMerkleTree()
+	for i := 0; i < 13; i++ {
+		tree.Add(leafData(i))
+	}
+
+	for size := 12; size >= 0; size-- {
+		err := tree.Truncate(size)
+		et.Assert(err == nil, "Error was thrown on truncating to", size)
+		et.Assert(tree.Length() == size, "Incorrect length after truncating to", size)
+		et.Assert(tree.Root() == expectedRoot(size), "Incorrect root after truncating to", size)
+	}
+
+	tree.Add(leafData(0))
+	et.Assert(tree.Root() == expectedRoot(1), "The tree did not grow correctly after truncating")
+
+	err := tree.Truncate(2)
+	et.Assert(err != nil, "Error was not thrown on truncating above the length")
+	et.Assert(err.Error() == invalidSize, "Incorrect message was thrown on truncating above the length")
+}
+
+func TestHashAt(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree := NewMerkleTree()
+	for i := 0; i < 5; i++ {
+		tree.Add(leafData(i))
+	}
+
+	hash, err := tree.HashAt(3)
+	et.Assert(err == nil, "Error was thrown for fetching hash")
+	et.Assert(hash == crypto.Keccak256Hash(leafData(3)).Hex(), "Incorrect hash was returned")
+
+	_, err = tree.HashAt(5)
+	et.Assert(err != nil, "Error was not thrown on index out of bounds")
+}
+
+func TestMarshalJSON(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree := NewMerkleTree()
+	tree.Add(leafData(0))
+	tree.Add(leafData(1))
+
+	expected := fmt.Sprintf(`{"root":"%v", "length":2}`, expectedRoot(2))
+	received, _ := tree.MarshalJSON()
+
+	et.Assert(string(received) == expected, string(received))
+}
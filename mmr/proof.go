@@ -0,0 +1,312 @@
+package mmr
+
+import (
+	"errors"
+	"github.com/ethereum/go-ethereum/common"
+	"math/bits"
+	"sort"
+)
+
+// AncestryProof proves that a mountain range of an earlier size is a prefix of the current one.
+// It carries the peaks of the earlier mountain range and the hashes needed to produce the current root out of them
+type AncestryProof struct {
+	PrevSize  uint64   `json:"prevSize"`
+	PrevPeaks []string `json:"prevPeaks"`
+	Hashes    []string `json:"hashes"`
+}
+
+type queued struct {
+	position uint64
+	height   int
+	hash     common.Hash
+}
+
+// height returns the height of the node at the given position, leafs being at height 0
+func height(position uint64) int {
+	position++
+	allOnes := func(n uint64) bool {
+		return n != 0 && bits.OnesCount64(n) == bits.Len64(n)
+	}
+	for !allOnes(position) {
+		// Jump to the node at the same height in the left-most tree
+		position -= (uint64(1) << uint(bits.Len64(position)-1)) - 1
+	}
+	return bits.Len64(position) - 1
+}
+
+func siblingOffset(height int) uint64 {
+	return (uint64(2) << uint(height)) - 1
+}
+
+// family returns the position of the sibling and the parent of a node and whether the node is a right child
+func family(position uint64, h int) (sibling, parent uint64, isRight bool) {
+	if height(position+1) > h {
+		return position - siblingOffset(h), position + 1, true
+	}
+	return position + siblingOffset(h), position + siblingOffset(h) + 1, false
+}
+
+// mmrSize returns the count of all nodes in a mountain range with the given count of leafs
+func mmrSize(leafs int) uint64 {
+	return 2*uint64(leafs) - uint64(bits.OnesCount64(uint64(leafs)))
+}
+
+// leafPosition returns the position of the leaf with the given index
+func leafPosition(index int) uint64 {
+	return mmrSize(index+1) - uint64(bits.TrailingZeros64(uint64(index+1))) - 1
+}
+
+// peakPositions returns the positions of the peaks from left to right or nil if the size is not a valid mountain range size
+func peakPositions(size uint64) []uint64 {
+	peaks := make([]uint64, 0)
+	offset := uint64(0)
+	for h := 63; h >= 0 && size > 0; h-- {
+		treeSize := (uint64(1) << uint(h+1)) - 1
+		if size >= treeSize {
+			peaks = append(peaks, offset+treeSize-1)
+			offset += treeSize
+			size -= treeSize
+			if size >= treeSize {
+				return nil // Two peaks of the same height should have been merged
+			}
+		}
+	}
+	return peaks
+}
+
+// bagPeaks folds the peaks from right to left into a single root
+func bagPeaks(peaks []common.Hash) (root common.Hash, ok bool) {
+	if len(peaks) == 0 {
+		return common.Hash{}, false
+	}
+	root = peaks[len(peaks)-1]
+	for i := len(peaks) - 2; i >= 0; i-- {
+		root = merge(root, peaks[i])
+	}
+	return root, true
+}
+
+func hexes(hashes []common.Hash) []string {
+	res := make([]string, len(hashes))
+	for i, h := range hashes {
+		res[i] = h.Hex()
+	}
+	return res
+}
+
+func hashes(hexes []string) []common.Hash {
+	res := make([]common.Hash, len(hexes))
+	for i, h := range hexes {
+		res[i] = common.HexToHash(h)
+	}
+	return res
+}
+
+// push inserts the item keeping the queue ordered by height and then by position,
+// so siblings are always next to each other when processed
+func push(queue []queued, item queued) []queued {
+	i := sort.Search(len(queue), func(i int) bool {
+		return queue[i].height > item.height || (queue[i].height == item.height && queue[i].position > item.position)
+	})
+	queue = append(queue, queued{})
+	copy(queue[i+1:], queue[i:])
+	queue[i] = item
+	return queue
+}
+
+// proof returns the hashes needed to produce the root out of the nodes at the given ascending positions
+func (tree *MerkleTree) proof(positions []uint64) []common.Hash {
+	proof := make([]common.Hash, 0)
+	baggingTrack := 0
+	for _, peak := range peakPositions(uint64(len(tree.nodes))) {
+		var under []uint64
+		for len(positions) > 0 && positions[0] <= peak {
+			under = append(under, positions[0])
+			positions = positions[1:]
+		}
+		if len(under) == 0 {
+			baggingTrack++
+			proof = append(proof, tree.nodes[peak])
+			continue
+		}
+		baggingTrack = 0
+		proof = tree.peakProof(proof, under, peak)
+	}
+
+	// The peaks right of the proven nodes are sent bagged as a single hash
+	if baggingTrack > 1 {
+		bagged, _ := bagPeaks(proof[len(proof)-baggingTrack:])
+		proof = append(proof[:len(proof)-baggingTrack], bagged)
+	}
+
+	return proof
+}
+
+func (tree *MerkleTree) peakProof(proof []common.Hash, positions []uint64, peak uint64) []common.Hash {
+	queue := make([]queued, 0, len(positions))
+	for _, p := range positions {
+		queue = push(queue, queued{position: p, height: height(p)})
+	}
+
+	for len(queue) > 0 {
+		item := queue[0]
+		queue = queue[1:]
+		if item.position == peak {
+			continue
+		}
+
+		sibling, parent, _ := family(item.position, item.height)
+		if len(queue) > 0 && queue[0].position == sibling {
+			queue = queue[1:]
+		} else {
+			proof = append(proof, tree.nodes[sibling])
+		}
+		queue = push(queue, queued{position: parent, height: item.height + 1})
+	}
+
+	return proof
+}
+
+// calculateRoot produces the root of a mountain range of the given size out of the known nodes and the proof
+func calculateRoot(items []queued, size uint64, proof []common.Hash) (root common.Hash, ok bool) {
+	peaks := peakPositions(size)
+	if peaks == nil {
+		return common.Hash{}, false
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].position < items[j].position })
+
+	peakHashes := make([]common.Hash, 0, len(peaks))
+	for _, peak := range peaks {
+		var under []queued
+		for len(items) > 0 && items[0].position <= peak {
+			under = append(under, items[0])
+			items = items[1:]
+		}
+		if len(under) == 0 {
+			if len(proof) == 0 {
+				break // The rest of the peaks were bagged
+			}
+			peakHashes = append(peakHashes, proof[0])
+			proof = proof[1:]
+			continue
+		}
+		var peakHash common.Hash
+		peakHash, proof, ok = peakRoot(under, peak, proof)
+		if !ok {
+			return common.Hash{}, false
+		}
+		peakHashes = append(peakHashes, peakHash)
+	}
+
+	if len(items) > 0 || len(proof) > 0 {
+		return common.Hash{}, false
+	}
+
+	return bagPeaks(peakHashes)
+}
+
+func peakRoot(items []queued, peak uint64, proof []common.Hash) (root common.Hash, rest []common.Hash, ok bool) {
+	queue := make([]queued, 0, len(items))
+	for _, item := range items {
+		item.height = height(item.position)
+		queue = push(queue, item)
+	}
+
+	for len(queue) > 0 {
+		item := queue[0]
+		queue = queue[1:]
+		if item.position == peak {
+			return item.hash, proof, len(queue) == 0
+		}
+
+		sibling, parent, isRight := family(item.position, item.height)
+		if parent > peak {
+			return common.Hash{}, nil, false
+		}
+
+		var siblingHash common.Hash
+		if len(queue) > 0 && queue[0].position == sibling {
+			siblingHash = queue[0].hash
+			queue = queue[1:]
+		} else {
+			if len(proof) == 0 {
+				return common.Hash{}, nil, false
+			}
+			siblingHash = proof[0]
+			proof = proof[1:]
+		}
+
+		parentHash := merge(item.hash, siblingHash)
+		if isRight {
+			parentHash = merge(siblingHash, item.hash)
+		}
+		queue = push(queue, queued{parent, item.height + 1, parentHash})
+	}
+
+	return common.Hash{}, nil, false
+}
+
+// VerifyProof validates that the leaf hash is at the given index in a mountain range with the given root and size,
+// using the intermediary hashes returned by IntermediaryHashesByIndex
+func VerifyProof(root string, size uint64, index int, leafHash string, intermediaryHashes []string) (bool, error) {
+	if index < 0 {
+		return false, errors.New(outOfBounds)
+	}
+	position := leafPosition(index)
+	if position >= size {
+		return false, errors.New(outOfBounds)
+	}
+
+	leaf := queued{position: position, hash: common.HexToHash(leafHash)}
+	calculated, ok := calculateRoot([]queued{leaf}, size, hashes(intermediaryHashes))
+
+	return ok && calculated == common.HexToHash(root), nil
+}
+
+// AncestryProof returns proof that the mountain range with the given earlier length is a prefix of the current one
+func (tree *MerkleTree) AncestryProof(prevLength int) (*AncestryProof, error) {
+	tree.mutex.RLock()
+	defer tree.mutex.RUnlock()
+
+	if prevLength < 1 || prevLength > tree.leafs {
+		return nil, errors.New(invalidSize)
+	}
+
+	prevSize := mmrSize(prevLength)
+	prevPeaks := peakPositions(prevSize)
+	peakHashes := make([]common.Hash, len(prevPeaks))
+	for i, p := range prevPeaks {
+		peakHashes[i] = tree.nodes[p]
+	}
+
+	return &AncestryProof{
+		PrevSize:  prevSize,
+		PrevPeaks: hexes(peakHashes),
+		Hashes:    hexes(tree.proof(prevPeaks)),
+	}, nil
+}
+
+// VerifyAncestry validates that the mountain range with root prevRoot is a prefix of the one with the given root and size
+func VerifyAncestry(prevRoot string, root string, size uint64, proof *AncestryProof) (bool, error) {
+	if proof.PrevSize > size {
+		return false, errors.New(invalidSize)
+	}
+	prevPeaks := peakPositions(proof.PrevSize)
+	if prevPeaks == nil || len(prevPeaks) != len(proof.PrevPeaks) {
+		return false, errors.New(corruptedProof)
+	}
+
+	peakHashes := hashes(proof.PrevPeaks)
+	bagged, ok := bagPeaks(peakHashes)
+	if !ok || bagged != common.HexToHash(prevRoot) {
+		return false, nil
+	}
+
+	items := make([]queued, len(prevPeaks))
+	for i, p := range prevPeaks {
+		items[i] = queued{position: p, hash: peakHashes[i]}
+	}
+	calculated, ok := calculateRoot(items, size, hashes(proof.Hashes))
+
+	return ok && calculated == common.HexToHash(root), nil
+}
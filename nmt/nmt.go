@@ -0,0 +1,281 @@
+// Package nmt implements namespaced merkle tree stored in the memory of the system.
+// Leafs are prefixed with a namespace ID and every node carries the minimum and maximum namespace below it,
+// which allows proving that a set of leafs is the complete set of a namespace. The hashing scheme and the
+// tree shape follow the NMT used by Celestia: sha256, RFC 6962 domain separation and splitting of the leafs
+// at the largest power of two
+package nmt
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"strings"
+	"sync"
+)
+
+const (
+	outOfBounds       = "Incorrect index - Index out of bounds"
+	invalidNamespace  = "Incorrect namespace - The namespace size is not positive"
+	shortData         = "Incorrect data - The data is shorter than the namespace"
+	unorderedData     = "Incorrect data - The namespace is smaller than the namespace of the last leaf"
+	wrongNamespaceLen = "Incorrect namespace - The namespace ID does not match the namespace size"
+)
+
+const (
+	leafPrefix = 0
+	nodePrefix = 1
+)
+
+// Node is a single node or leaf in the namespaced merkle tree
+type Node struct {
+	hash          []byte
+	data          []byte
+	index         int
+	namespaceSize int
+}
+
+// Hash returns the hex representation of the namespaced hash (minNamespace || maxNamespace || hash) of the node
+func (node *Node) Hash() string {
+	return hexutil.Encode(node.hash)
+}
+
+// Index returns the index of this node in its level
+func (node *Node) Index() int {
+	return node.index
+}
+
+// MinNamespace returns the smallest namespace ID below this node
+func (node *Node) MinNamespace() []byte {
+	return minNamespace(node.hash, node.namespaceSize)
+}
+
+// MaxNamespace returns the biggest namespace ID below this node
+func (node *Node) MaxNamespace() []byte {
+	return maxNamespace(node.hash, node.namespaceSize)
+}
+
+// String returns the hash of this node. Alias to Hash()
+func (node Node) String() string {
+	return node.Hash()
+}
+
+// MerkleTree is a namespaced merkle tree. Leafs must be added in non-decreasing order of their namespace
+type MerkleTree struct {
+	NamespaceSize int
+	leafs         []*Node
+	root          []byte
+	mutex         sync.RWMutex
+}
+
+func minNamespace(hash []byte, namespaceSize int) []byte {
+	return hash[:namespaceSize]
+}
+
+func maxNamespace(hash []byte, namespaceSize int) []byte {
+	return hash[namespaceSize : 2*namespaceSize]
+}
+
+func hashLeaf(namespacedData []byte, namespaceSize int) []byte {
+	namespace := namespacedData[:namespaceSize]
+	h := sha256.Sum256(append([]byte{leafPrefix}, namespacedData...))
+
+	res := make([]byte, 0, 2*namespaceSize+len(h))
+	res = append(res, namespace...)
+	res = append(res, namespace...)
+	return append(res, h[:]...)
+}
+
+func hashNode(left, right []byte, namespaceSize int) []byte {
+	max := maxNamespace(left, namespaceSize)
+	if bytes.Compare(maxNamespace(right, namespaceSize), max) > 0 {
+		max = maxNamespace(right, namespaceSize)
+	}
+
+	data := make([]byte, 0, 1+len(left)+len(right))
+	data = append(data, nodePrefix)
+	data = append(data, left...)
+	data = append(data, right...)
+	h := sha256.Sum256(data)
+
+	res := make([]byte, 0, 2*namespaceSize+len(h))
+	res = append(res, minNamespace(left, namespaceSize)...)
+	res = append(res, max...)
+	return append(res, h[:]...)
+}
+
+func emptyRoot(namespaceSize int) []byte {
+	h := sha256.Sum256(nil)
+	return append(make([]byte, 2*namespaceSize), h[:]...)
+}
+
+// split returns the size of the left subtree of n leafs - the largest power of two smaller than n
+func split(n int) int {
+	k := 1
+	for k*2 < n {
+		k *= 2
+	}
+	return k
+}
+
+func (tree *MerkleTree) subtreeRoot(lo, hi int) []byte {
+	if hi-lo == 1 {
+		return tree.leafs[lo].hash
+	}
+	k := split(hi - lo)
+	return hashNode(tree.subtreeRoot(lo, lo+k), tree.subtreeRoot(lo+k, hi), tree.NamespaceSize)
+}
+
+func (tree *MerkleTree) getRoot() []byte {
+	if tree.root == nil {
+		if len(tree.leafs) == 0 {
+			tree.root = emptyRoot(tree.NamespaceSize)
+		} else {
+			tree.root = tree.subtreeRoot(0, len(tree.leafs))
+		}
+	}
+	return tree.root
+}
+
+// Push adds the namespaced data on the next available slot in the tree.
+// The first NamespaceSize bytes of the data are its namespace ID and must not be smaller than the namespace of the last leaf
+func (tree *MerkleTree) Push(namespacedData []byte) (index int, hash string, err error) {
+	tree.mutex.Lock()
+	defer tree.mutex.Unlock()
+
+	if len(namespacedData) < tree.NamespaceSize {
+		return -1, "", errors.New(shortData)
+	}
+	if len(tree.leafs) > 0 {
+		last := tree.leafs[len(tree.leafs)-1]
+		if bytes.Compare(namespacedData[:tree.NamespaceSize], last.MaxNamespace()) < 0 {
+			return -1, "", errors.New(unorderedData)
+		}
+	}
+
+	index = len(tree.leafs)
+	data := append([]byte{}, namespacedData...)
+	leaf := &Node{hashLeaf(data, tree.NamespaceSize), data, index, tree.NamespaceSize}
+	tree.leafs = append(tree.leafs, leaf)
+	tree.root = nil
+
+	return index, leaf.Hash(), nil
+}
+
+// Append is Push that makes the tree a merkletree.Appender, so the reason of a failed addition reaches the caller
+func (tree *MerkleTree) Append(data []byte) (index int, hash string, err error) {
+	return tree.Push(data)
+}
+
+// RawAppend is alias to Append. The root is always recalculated lazily on the next request
+func (tree *MerkleTree) RawAppend(data []byte) (index int, hash string, err error) {
+	return tree.Push(data)
+}
+
+// Add adds the namespaced data on the next available slot in the tree.
+// Returns the index it was inserted and the namespaced hash of the new leaf or -1 and empty hash if Push fails.
+// Use Append to get the error
+func (tree *MerkleTree) Add(data []byte) (index int, hash string) {
+	index, hash, err := tree.Push(data)
+	if err != nil {
+		return -1, ""
+	}
+	return index, hash
+}
+
+// RawAdd is alias to Add. The root is always recalculated lazily on the next request
+func (tree *MerkleTree) RawAdd(data []byte) (index int, hash string) {
+	return tree.Add(data)
+}
+
+// IntermediaryHashesByIndex returns the namespaced hashes of the subtrees needed to produce the root from the given index
+func (tree *MerkleTree) IntermediaryHashesByIndex(index int) (intermediaryHashes []string, err error) {
+	tree.mutex.RLock()
+	defer tree.mutex.RUnlock()
+
+	if index < 0 || index >= len(tree.leafs) {
+		return nil, errors.New(outOfBounds)
+	}
+	return hexes(tree.rangeProof(index, index+1)), nil
+}
+
+// ValidateExistence emulates how third party would validate the data. Given original namespaced data, the index it is supposed to be and the intermediaryHashes,
+// the method validates that this is the correct data for that slot
+func (tree *MerkleTree) ValidateExistence(original []byte, index int, intermediaryHashes []string) (result bool, err error) {
+	tree.mutex.Lock()
+	defer tree.mutex.Unlock()
+
+	if index < 0 || index >= len(tree.leafs) {
+		return false, errors.New(outOfBounds)
+	}
+	if len(original) < tree.NamespaceSize {
+		return false, nil
+	}
+
+	nodes, err := unhexes(intermediaryHashes)
+	if err != nil {
+		return false, nil
+	}
+
+	proof := &Proof{Start: index, End: index + 1, Total: len(tree.leafs), nodes: nodes}
+	root, ok := proof.computeRoot([][]byte{hashLeaf(original, tree.NamespaceSize)}, tree.NamespaceSize)
+
+	return ok && bytes.Equal(root, tree.getRoot()), nil
+}
+
+// HashAt returns the namespaced hash of the leaf at given index
+func (tree *MerkleTree) HashAt(index int) (string, error) {
+	tree.mutex.RLock()
+	defer tree.mutex.RUnlock()
+
+	if index < 0 || index >= len(tree.leafs) {
+		return "", errors.New(outOfBounds)
+	}
+	return tree.leafs[index].Hash(), nil
+}
+
+// Root returns the namespaced hash of the root of the tree
+func (tree *MerkleTree) Root() string {
+	tree.mutex.Lock()
+	defer tree.mutex.Unlock()
+
+	return hexutil.Encode(tree.getRoot())
+}
+
+// Length returns the count of the tree leafs
+func (tree *MerkleTree) Length() int {
+	tree.mutex.RLock()
+	defer tree.mutex.RUnlock()
+
+	return len(tree.leafs)
+}
+
+// String returns human readable version of the tree
+func (tree *MerkleTree) String() string {
+	b := strings.Builder{}
+	b.WriteString(fmt.Sprintf("Root: %v, Count: %v\n", tree.Root(), tree.Length()))
+
+	tree.mutex.RLock()
+	defer tree.mutex.RUnlock()
+	for _, leaf := range tree.leafs {
+		b.WriteString(fmt.Sprintf("%v\t", leaf.Hash()))
+	}
+	b.WriteString("\n")
+
+	return b.String()
+}
+
+// MarshalJSON Creates JSON version of the needed fields of the tree
+func (tree *MerkleTree) MarshalJSON() ([]byte, error) {
+	res := fmt.Sprintf("{\"root\":\"%v\", \"length\":%v}", tree.Root(), tree.Length())
+	return []byte(res), nil
+}
+
+// NewMerkleTree returns a pointer to an initialized MerkleTree with namespace IDs of the given size in bytes
+func NewMerkleTree(namespaceSize int) (*MerkleTree, error) {
+	if namespaceSize < 1 {
+		return nil, errors.New(invalidNamespace)
+	}
+	return &MerkleTree{NamespaceSize: namespaceSize}, nil
+}
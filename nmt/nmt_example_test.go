@@ -0,0 +1,26 @@
+package nmt_test
+
+import (
+	"fmt"
+	"github.com/LimeChain/merkletree/nmt"
+)
+
+func Example() {
+	tree, _ := nmt.NewMerkleTree(4)
+	tree.Push([]byte("ns01first blob"))
+	tree.Push([]byte("ns02second blob"))
+	tree.Push([]byte("ns02third blob"))
+	tree.Push([]byte("ns03fourth blob"))
+
+	data, proof, _ := tree.ProveNamespace([]byte("ns02"))
+	fmt.Printf("Leafs: %v\n", len(data))
+	fmt.Printf("Complete: %v\n", nmt.VerifyNamespace(4, tree.Root(), []byte("ns02"), data, proof))
+
+	data, proof, _ = tree.ProveNamespace([]byte("ns00"))
+	fmt.Printf("Absent: %v\n", len(data) == 0 && nmt.VerifyNamespace(4, tree.Root(), []byte("ns00"), data, proof))
+
+	// Output:
+	// Leafs: 2
+	// Complete: true
+	// Absent: true
+}
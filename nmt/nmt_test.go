@@ -0,0 +1,181 @@
+package nmt
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"github.com/LimeChain/merkletree"
+	"github.com/LimeChain/merkletree/merkletreetest"
+	"github.com/LimeChain/merkletree/restapi/baseapi"
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"net/http/httptest"
+	"testing"
+)
+
+func namespaced(namespace byte, data string) []byte {
+	return append([]byte{0, 0, 0, namespace}, []byte(data)...)
+}
+
+func buildTree(t *testing.T) *MerkleTree {
+	tree, _ := NewMerkleTree(4)
+	for _, ns := range []byte{1, 1, 3, 3, 3, 5, 7} {
+		_, _, err := tree.Push(namespaced(ns, fmt.Sprintf("blob %v of %v", tree.Length(), ns)))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return tree
+}
+
+func TestNewMerkleTree(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree, err := NewMerkleTree(8)
+	et.Assert(err == nil, "Error was thrown on valid namespace size")
+
+	h := sha256.Sum256(nil)
+	et.Assert(tree.Root() == fmt.Sprintf("0x%x%x", make([]byte, 16), h), "The root of the empty tree was not the empty root")
+	_, isExternalMerkleTree := interface{}(tree).(merkletree.ExternalMerkleTree)
+	et.Assert(isExternalMerkleTree, "The tree did not implement the ExternalMerkleTree interface")
+
+	_, err = NewMerkleTree(0)
+	et.Assert(err != nil, "Error was not thrown on zero namespace size")
+}
+
+func TestPush(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree, _ := NewMerkleTree(4)
+
+	d1 := namespaced(1, "a")
+	d2 := namespaced(2, "b")
+	d3 := namespaced(2, "c")
+	tree.Push(d1)
+	tree.Push(d2)
+	_, hash, err := tree.Push(d3)
+	et.Assert(err == nil, "Error was thrown on ordered push")
+
+	leaf := func(d []byte) []byte {
+		h := sha256.Sum256(append([]byte{0}, d...))
+		return append(append(append([]byte{}, d[:4]...), d[:4]...), h[:]...)
+	}
+	node := func(l, r []byte) []byte {
+		h := sha256.Sum256(append(append([]byte{1}, l...), r...))
+		return append(append(append([]byte{}, l[:4]...), r[4:8]...), h[:]...)
+	}
+
+	et.Assert(hash == fmt.Sprintf("0x%x", leaf(d3)), "The hash of the leaf was not the namespaced leaf hash")
+	expectedRoot := node(node(leaf(d1), leaf(d2)), leaf(d3))
+	et.Assert(tree.Root() == fmt.Sprintf("0x%x", expectedRoot), "The root was not correctly calculated")
+
+	_, _, err = tree.Push(namespaced(1, "d"))
+	et.Assert(err != nil, "Error was not thrown on unordered push")
+	et.Assert(err.Error() == unorderedData, "Incorrect message was thrown on unordered push")
+
+	index, hash := tree.Add([]byte{0})
+	et.Assert(index == -1 && hash == "", "Short data was added")
+	et.Assert(tree.Length() == 3, "Invalid data changed the length")
+
+	appender, ok := interface{}(tree).(merkletree.Appender)
+	et.Assert(ok, "The tree did not implement the Appender interface")
+	index, hash, err = appender.Append(namespaced(1, "e"))
+	et.Assert(err != nil && err.Error() == unorderedData, "The error of unordered append was not returned")
+	et.Assert(index == -1 && hash == "", "Unordered data was appended")
+	index, _, err = appender.RawAppend(namespaced(3, "e"))
+	et.Assert(err == nil && index == 3, "Ordered data was not appended")
+}
+
+func TestIntermediaryHashesByIndex(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree, _ := NewMerkleTree(4)
+
+	for n := 1; n <= 13; n++ {
+		tree.Add(namespaced(byte(n), "blob"))
+		for i := 0; i < n; i++ {
+			hashes, err := tree.IntermediaryHashesByIndex(i)
+			et.Assert(err == nil, "Error was thrown for intermediary hashes")
+
+			exists, err := tree.ValidateExistence(namespaced(byte(i+1), "blob"), i, hashes)
+			et.Assert(err == nil, "Error was thrown on validation")
+			et.Assert(exists, "Could not validate leaf", i, "in tree of", n)
+
+			exists, _ = tree.ValidateExistence(namespaced(byte(i+1), "other"), i, hashes)
+			et.Assert(!exists, "Validated wrong data", i, "in tree of", n)
+		}
+	}
+
+	_, err := tree.IntermediaryHashesByIndex(13)
+	et.Assert(err != nil, "Error was not thrown on index out of bounds")
+	et.Assert(err.Error() == outOfBounds, "Incorrect message was thrown on index out of bounds")
+}
+
+func TestProveNamespace(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree := buildTree(t)
+	root := tree.Root()
+	ns := func(n byte) []byte { return []byte{0, 0, 0, n} }
+
+	data, proof, err := tree.ProveNamespace(ns(3))
+	et.Assert(err == nil, "Error was thrown on namespace proof")
+	et.Assert(len(data) == 3, "Not all leafs of the namespace were returned")
+	et.Assert(proof.Start == 2 && proof.End == 5, "Incorrect range of the namespace")
+	et.Assert(VerifyNamespace(4, root, ns(3), data, proof), "Could not verify the namespace")
+	et.Assert(!VerifyNamespace(4, root, ns(3), data[:2], proof), "Verified incomplete namespace")
+	et.Assert(!VerifyNamespace(4, root, ns(1), data, proof), "Verified data for wrong namespace")
+
+	partial := &Proof{Start: 2, End: 4, Total: 7, Nodes: hexes(tree.rangeProof(2, 4))}
+	et.Assert(!VerifyNamespace(4, root, ns(3), data[:2], partial), "Verified range missing a leaf of the namespace")
+
+	for _, n := range []byte{1, 5, 7} {
+		data, proof, _ = tree.ProveNamespace(ns(n))
+		et.Assert(len(data) > 0, "No leafs were returned for namespace", n)
+		et.Assert(VerifyNamespace(4, root, ns(n), data, proof), "Could not verify namespace", n)
+	}
+
+	for _, n := range []byte{0, 2, 4, 6, 8} {
+		data, proof, _ = tree.ProveNamespace(ns(n))
+		et.Assert(len(data) == 0, "Leafs were returned for absent namespace", n)
+		et.Assert(VerifyNamespace(4, root, ns(n), data, proof), "Could not verify absence of namespace", n)
+		et.Assert(!VerifyNamespace(4, root, ns(3), data, proof), "Verified absence of present namespace with proof for", n)
+	}
+
+	_, _, err = tree.ProveNamespace([]byte{1})
+	et.Assert(err != nil, "Error was not thrown on namespace of wrong size")
+}
+
+func TestBaseAPI(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree := buildTree(t)
+
+	router := chi.NewRouter()
+	router.Use(render.SetContentType(render.ContentTypeJSON))
+	router.Route("/v1", func(r chi.Router) {
+		treeRouter := chi.NewRouter()
+		treeRouter = baseapi.MerkleTreeStatus(treeRouter, tree)
+		treeRouter = baseapi.MerkleTreeHashes(treeRouter, tree)
+		r.Mount("/api/merkletree", treeRouter)
+	})
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	resp, err := server.Client().Get(server.URL + "/v1/api/merkletree/hashes/2")
+	et.Assert(err == nil, "Error was thrown by the API on Request")
+
+	var r struct {
+		Status bool     `json:"status"`
+		Hashes []string `json:"hashes"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&r)
+	et.Assert(err == nil, "Error was thrown when parsing the response")
+	et.Assert(r.Status, "The status for getting the hashes was false")
+
+	exists, _ := tree.ValidateExistence(namespaced(3, "blob 2 of 3"), 2, r.Hashes)
+	et.Assert(exists, "The returned hashes could not be validated")
+
+	resp, err = server.Client().Get(server.URL + "/v1/api/merkletree")
+	et.Assert(err == nil, "Error was thrown by the API on Request")
+	body := new(bytes.Buffer)
+	body.ReadFrom(resp.Body)
+	expected := fmt.Sprintf(`{"status":true,"tree":{"root":"%v","length":7}}`, tree.Root())
+	et.Assert(bytes.Equal(bytes.TrimSpace(body.Bytes()), []byte(expected)), "The status response was not the expected one", body.String())
+}
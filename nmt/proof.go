@@ -0,0 +1,209 @@
+package nmt
+
+import (
+	"bytes"
+	"errors"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"sort"
+)
+
+// Proof is a range proof for the leafs in [Start, End) out of Total leafs. Nodes are the namespaced hashes of the
+// subtrees outside of the range from left to right. For proofs of absence LeafHash is the namespaced hash of the
+// leaf at Start, which carries the first namespace bigger than the requested one
+type Proof struct {
+	Start    int      `json:"start"`
+	End      int      `json:"end"`
+	Total    int      `json:"total"`
+	Nodes    []string `json:"nodes"`
+	LeafHash string   `json:"leafHash,omitempty"`
+	nodes    [][]byte
+}
+
+func hexes(nodes [][]byte) []string {
+	res := make([]string, len(nodes))
+	for i, n := range nodes {
+		res[i] = hexutil.Encode(n)
+	}
+	return res
+}
+
+func unhexes(hexes []string) ([][]byte, error) {
+	res := make([][]byte, len(hexes))
+	for i, h := range hexes {
+		b, err := hexutil.Decode(h)
+		if err != nil {
+			return nil, err
+		}
+		res[i] = b
+	}
+	return res, nil
+}
+
+// rangeProof returns the roots of the subtrees outside of [start, end) from left to right
+func (tree *MerkleTree) rangeProof(start, end int) [][]byte {
+	nodes := make([][]byte, 0)
+	var collect func(lo, hi int)
+	collect = func(lo, hi int) {
+		if hi <= start || lo >= end {
+			nodes = append(nodes, tree.subtreeRoot(lo, hi))
+			return
+		}
+		if hi-lo == 1 {
+			return
+		}
+		k := split(hi - lo)
+		collect(lo, lo+k)
+		collect(lo+k, hi)
+	}
+	collect(0, len(tree.leafs))
+	return nodes
+}
+
+// computeRoot produces the root out of the hashes of the leafs in the range and the proof nodes.
+// Every proof node is passed to visit together with whether it is left of the range
+func (proof *Proof) computeRoot(leafHashes [][]byte, namespaceSize int, visit ...func(node []byte, left bool) bool) ([]byte, bool) {
+	if proof.Start < 0 || proof.Start >= proof.End || proof.End > proof.Total || len(leafHashes) != proof.End-proof.Start {
+		return nil, false
+	}
+	for _, nodes := range [][][]byte{leafHashes, proof.nodes} {
+		for _, n := range nodes {
+			if len(n) <= 2*namespaceSize {
+				return nil, false
+			}
+		}
+	}
+
+	nodes := proof.nodes
+	ok := true
+	var compute func(lo, hi int) []byte
+	compute = func(lo, hi int) []byte {
+		if hi <= proof.Start || lo >= proof.End {
+			if len(nodes) == 0 {
+				ok = false
+				return nil
+			}
+			node := nodes[0]
+			nodes = nodes[1:]
+			for _, v := range visit {
+				ok = ok && v(node, hi <= proof.Start)
+			}
+			return node
+		}
+		if hi-lo == 1 {
+			return leafHashes[lo-proof.Start]
+		}
+		k := split(hi - lo)
+		left := compute(lo, lo+k)
+		right := compute(lo+k, hi)
+		if !ok {
+			return nil
+		}
+		return hashNode(left, right, namespaceSize)
+	}
+
+	root := compute(0, proof.Total)
+	return root, ok && len(nodes) == 0
+}
+
+// ProveNamespace returns all namespaced data of the given namespace together with a proof that no other leaf
+// carries this namespace. If the namespace is absent the data is empty and the proof is a proof of absence
+func (tree *MerkleTree) ProveNamespace(namespace []byte) (data [][]byte, proof *Proof, err error) {
+	tree.mutex.RLock()
+	defer tree.mutex.RUnlock()
+
+	return tree.proveNamespace(namespace)
+}
+
+// ProveNamespaceWithRoot returns the result of ProveNamespace together with the root it was produced against
+func (tree *MerkleTree) ProveNamespaceWithRoot(namespace []byte) (data [][]byte, proof *Proof, root string, err error) {
+	tree.mutex.Lock()
+	defer tree.mutex.Unlock()
+
+	data, proof, err = tree.proveNamespace(namespace)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	return data, proof, hexutil.Encode(tree.getRoot()), nil
+}
+
+func (tree *MerkleTree) proveNamespace(namespace []byte) (data [][]byte, proof *Proof, err error) {
+	if len(namespace) != tree.NamespaceSize {
+		return nil, nil, errors.New(wrongNamespaceLen)
+	}
+
+	n := len(tree.leafs)
+	start := sort.Search(n, func(i int) bool {
+		return bytes.Compare(tree.leafs[i].MinNamespace(), namespace) >= 0
+	})
+	end := sort.Search(n, func(i int) bool {
+		return bytes.Compare(tree.leafs[i].MinNamespace(), namespace) > 0
+	})
+
+	data = make([][]byte, 0, end-start)
+	proof = &Proof{Start: start, End: end, Total: n, Nodes: make([]string, 0)}
+
+	if start == end {
+		if start == 0 || start == n {
+			// The namespace is outside of the range of the root
+			proof.Start, proof.End = 0, 0
+			return data, proof, nil
+		}
+		proof.End = start + 1
+		proof.LeafHash = tree.leafs[start].Hash()
+	}
+
+	for i := start; i < end; i++ {
+		data = append(data, tree.leafs[i].data)
+	}
+	proof.Nodes = hexes(tree.rangeProof(proof.Start, proof.End))
+
+	return data, proof, nil
+}
+
+// VerifyNamespace validates that the namespaced data is the complete set of leafs of the namespace
+// in the tree with the given root, or that the namespace is absent when the data is empty
+func VerifyNamespace(namespaceSize int, root string, namespace []byte, data [][]byte, proof *Proof) bool {
+	rootHash, err := hexutil.Decode(root)
+	if err != nil || len(rootHash) <= 2*namespaceSize || len(namespace) != namespaceSize {
+		return false
+	}
+	proof.nodes, err = unhexes(proof.Nodes)
+	if err != nil {
+		return false
+	}
+
+	if proof.Total == 0 {
+		return len(data) == 0 && bytes.Equal(rootHash, emptyRoot(namespaceSize))
+	}
+
+	complete := func(node []byte, left bool) bool {
+		if left {
+			return bytes.Compare(maxNamespace(node, namespaceSize), namespace) < 0
+		}
+		return bytes.Compare(minNamespace(node, namespaceSize), namespace) > 0
+	}
+
+	leafHashes := make([][]byte, len(data))
+	for i, d := range data {
+		if len(d) < namespaceSize || !bytes.Equal(d[:namespaceSize], namespace) {
+			return false
+		}
+		leafHashes[i] = hashLeaf(d, namespaceSize)
+	}
+
+	if len(data) == 0 {
+		if proof.LeafHash == "" {
+			// Absence is proven by the range of the root alone
+			return len(proof.nodes) == 0 && (bytes.Compare(namespace, minNamespace(rootHash, namespaceSize)) < 0 ||
+				bytes.Compare(namespace, maxNamespace(rootHash, namespaceSize)) > 0)
+		}
+		leafHash, err := hexutil.Decode(proof.LeafHash)
+		if err != nil || len(leafHash) <= 2*namespaceSize || !complete(leafHash, false) {
+			return false
+		}
+		leafHashes = [][]byte{leafHash}
+	}
+
+	calculated, ok := proof.computeRoot(leafHashes, namespaceSize, complete)
+	return ok && bytes.Equal(calculated, rootHash)
+}
@@ -0,0 +1,166 @@
+// Package patricia implements Merkle Patricia Trie on top of the trie of go-ethereum, kept in the memory of the system.
+// Besides the generic key-value trie it offers a state trie of accounts and their storage, which produces and
+// verifies account and storage proofs in the format of the eth_getProof responses
+package patricia
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/trie"
+	"sync"
+)
+
+const (
+	emptyValue     = "Incorrect value - The value must not be empty"
+	corruptedProof = "Incorrect proof - The proof node is not valid hex"
+)
+
+// EmptyRoot is the root of a trie without any keys
+var EmptyRoot = common.HexToHash("56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421")
+
+// proofList collects the nodes written by trie.Prove in the order they are on the path from the root
+type proofList []string
+
+func (list *proofList) Put(key []byte, value []byte) error {
+	*list = append(*list, hexutil.Encode(value))
+	return nil
+}
+
+func (list *proofList) Delete(key []byte) error {
+	return errors.New("proofList does not support deletion")
+}
+
+// MerkleTree is implementation of merkletree.KeyValueMerkleTree as Merkle Patricia Trie.
+// In secure mode the keys are hashed with keccak256 before they are inserted, the way Ethereum stores state and storage
+type MerkleTree struct {
+	trie   *trie.Trie
+	secure bool
+	mutex  sync.RWMutex
+}
+
+func (tree *MerkleTree) key(key []byte) []byte {
+	if tree.secure {
+		return crypto.Keccak256(key)
+	}
+	return key
+}
+
+// Put sets the value of the key. Use Delete to remove the key as empty values are not stored in the trie
+func (tree *MerkleTree) Put(key []byte, value []byte) error {
+	if len(value) == 0 {
+		return errors.New(emptyValue)
+	}
+
+	tree.mutex.Lock()
+	defer tree.mutex.Unlock()
+
+	return tree.trie.TryUpdate(tree.key(key), value)
+}
+
+// Get returns the value of the key or empty value if the key is not in the trie
+func (tree *MerkleTree) Get(key []byte) ([]byte, error) {
+	tree.mutex.RLock()
+	defer tree.mutex.RUnlock()
+
+	return tree.trie.TryGet(tree.key(key))
+}
+
+// Delete removes the key from the trie
+func (tree *MerkleTree) Delete(key []byte) error {
+	tree.mutex.Lock()
+	defer tree.mutex.Unlock()
+
+	return tree.trie.TryDelete(tree.key(key))
+}
+
+// Prove returns the hex encoded RLP nodes on the path from the root to the key.
+// If the key is not in the trie the proof ends with the node that proves its absence
+func (tree *MerkleTree) Prove(key []byte) (proof []string, err error) {
+	tree.mutex.Lock()
+	defer tree.mutex.Unlock()
+
+	return tree.prove(key)
+}
+
+func (tree *MerkleTree) prove(key []byte) ([]string, error) {
+	proof := make(proofList, 0)
+	if err := tree.trie.Prove(tree.key(key), 0, &proof); err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
+
+// ValidateProof emulates how third party would validate the key. Given the key, its value and the proof,
+// the method validates that this is the value of the key in the trie. Empty value validates that the key is absent
+func (tree *MerkleTree) ValidateProof(key []byte, value []byte, proof []string) (bool, error) {
+	root := tree.Root()
+
+	proven, err := VerifyProof(root, tree.key(key), proof)
+	if err != nil {
+		return false, nil
+	}
+	return bytes.Equal(proven, value), nil
+}
+
+// VerifyProof returns the value of the key proven by the nodes against the root, or empty value if the nodes prove
+// that the key is absent. The key is the one stored in the trie, so it must already be hashed for secure tries
+func VerifyProof(root string, key []byte, proof []string) (value []byte, err error) {
+	rootHash := common.HexToHash(root)
+	if rootHash == EmptyRoot && len(proof) == 0 {
+		return nil, nil
+	}
+
+	db := memorydb.New()
+	for _, node := range proof {
+		encoded, err := hexutil.Decode(node)
+		if err != nil {
+			return nil, errors.New(corruptedProof)
+		}
+		db.Put(crypto.Keccak256(encoded), encoded)
+	}
+
+	value, _, err = trie.VerifyProof(rootHash, key, db)
+	return value, err
+}
+
+// Root returns the hash of the root of the trie
+func (tree *MerkleTree) Root() string {
+	tree.mutex.Lock()
+	defer tree.mutex.Unlock()
+
+	return tree.trie.Hash().Hex()
+}
+
+// String returns human readable version of the trie
+func (tree *MerkleTree) String() string {
+	return fmt.Sprintf("Root: %v, Secure: %v\n", tree.Root(), tree.secure)
+}
+
+// MarshalJSON Creates JSON version of the needed fields of the trie
+func (tree *MerkleTree) MarshalJSON() ([]byte, error) {
+	res := fmt.Sprintf("{\"root\":\"%v\", \"secure\":%v}", tree.Root(), tree.secure)
+	return []byte(res), nil
+}
+
+func newMerkleTree(secure bool) *MerkleTree {
+	t, err := trie.New(common.Hash{}, trie.NewDatabase(memorydb.New()))
+	if err != nil {
+		panic(err) // The empty root is never looked up in the database
+	}
+	return &MerkleTree{trie: t, secure: secure}
+}
+
+// NewMerkleTree returns a pointer to an initialized MerkleTree which stores the keys as they are
+func NewMerkleTree() *MerkleTree {
+	return newMerkleTree(false)
+}
+
+// NewSecureMerkleTree returns a pointer to an initialized MerkleTree which stores the keccak256 hashes of the keys
+func NewSecureMerkleTree() *MerkleTree {
+	return newMerkleTree(true)
+}
@@ -0,0 +1,23 @@
+package patricia_test
+
+import (
+	"fmt"
+	"github.com/LimeChain/merkletree/patricia"
+	"github.com/ethereum/go-ethereum/common"
+	"math/big"
+)
+
+func Example() {
+	state := patricia.NewStateTrie()
+	address := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	state.SetAccount(address, 1, big.NewInt(1000), patricia.EmptyCodeHash)
+	state.SetStorage(address, common.BigToHash(big.NewInt(0)), common.BigToHash(big.NewInt(42)))
+
+	result, _ := state.GetProof(address, []string{common.BigToHash(big.NewInt(0)).Hex()})
+	fmt.Printf("Storage Value: %v\n", result.StorageProof[0].Value)
+	fmt.Printf("Proof Valid: %v\n", patricia.VerifyAccountProof(state.Root(), result))
+
+	// Output:
+	// Storage Value: 0x2a
+	// Proof Valid: true
+}
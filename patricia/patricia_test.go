@@ -0,0 +1,229 @@
+package patricia
+
+import (
+	"encoding/json"
+	"github.com/LimeChain/merkletree/merkletreetest"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/crypto"
+	"io/ioutil"
+	"math/big"
+	"testing"
+)
+
+func TestEmptyRoot(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree := NewMerkleTree()
+	et.Assert(tree.Root() == EmptyRoot.Hex(), "Empty trie root was not correct", tree.Root())
+}
+
+func TestPut(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree := NewMerkleTree()
+	tree.Put([]byte("doe"), []byte("reindeer"))
+	tree.Put([]byte("dog"), []byte("puppy"))
+	tree.Put([]byte("dogglesworth"), []byte("cat"))
+
+	// Known root from the ethereum trie tests
+	expected := "0x8aad789dff2f538bca5d8ea56e8abe10f4c7ba3a5dea95fea4cd6e7c3a1168d3"
+	et.Assert(tree.Root() == expected, "Root was not correct", tree.Root())
+
+	value, err := tree.Get([]byte("dog"))
+	et.Assert(err == nil, "Error was thrown on get")
+	et.Assert(string(value) == "puppy", "Incorrect value was returned", string(value))
+
+	err = tree.Put([]byte("dog"), nil)
+	et.Assert(err != nil, "Error was not thrown on empty value")
+}
+
+func TestDelete(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree := NewMerkleTree()
+	tree.Put([]byte("doe"), []byte("reindeer"))
+	root := tree.Root()
+
+	tree.Put([]byte("dog"), []byte("puppy"))
+	tree.Delete([]byte("dog"))
+	et.Assert(tree.Root() == root, "Root after delete was not correct", tree.Root())
+
+	tree.Delete([]byte("doe"))
+	et.Assert(tree.Root() == EmptyRoot.Hex(), "Root after deleting all keys was not correct", tree.Root())
+}
+
+func TestValidateProof(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	for _, tree := range []*MerkleTree{NewMerkleTree(), NewSecureMerkleTree()} {
+		keys := [][]byte{[]byte("doe"), []byte("dog"), []byte("dogglesworth"), []byte("horse")}
+		for i, key := range keys {
+			tree.Put(key, []byte{byte(i + 1)})
+		}
+
+		for i, key := range keys {
+			proof, err := tree.Prove(key)
+			et.Assert(err == nil, "Error was thrown on proving", string(key))
+
+			ok, err := tree.ValidateProof(key, []byte{byte(i + 1)}, proof)
+			et.Assert(err == nil, "Error was thrown on validating", string(key))
+			et.Assert(ok, "Proof of key did not validate", string(key))
+
+			ok, _ = tree.ValidateProof(key, []byte{byte(i + 2)}, proof)
+			et.Assert(!ok, "Proof of key validated wrong value", string(key))
+		}
+
+		absent := []byte("cat")
+		proof, err := tree.Prove(absent)
+		et.Assert(err == nil, "Error was thrown on proving absent key")
+		ok, err := tree.ValidateProof(absent, nil, proof)
+		et.Assert(err == nil && ok, "Proof of absence did not validate")
+
+		proof, _ = tree.Prove(keys[0])
+		ok, _ = tree.ValidateProof(keys[0], []byte{1}, proof[:len(proof)-1])
+		et.Assert(!ok, "Truncated proof validated")
+	}
+}
+
+func TestVerifyProofEmptyTrie(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree := NewSecureMerkleTree()
+	proof, err := tree.Prove([]byte("dog"))
+	et.Assert(err == nil, "Error was thrown on proving in empty trie")
+
+	value, err := VerifyProof(tree.Root(), crypto.Keccak256([]byte("dog")), proof)
+	et.Assert(err == nil, "Error was thrown on verifying absence in empty trie")
+	et.Assert(len(value) == 0, "Value was returned for absent key", value)
+}
+
+// TestGetProof compares the state trie and its proofs with the state of go-ethereum
+func TestGetProof(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	db, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()))
+	trie := NewStateTrie()
+
+	contract := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	user := common.HexToAddress("0x00000000000000000000000000000000000000bb")
+	absent := common.HexToAddress("0x00000000000000000000000000000000000000cc")
+	code := []byte{0x60, 0x00}
+
+	db.SetNonce(contract, 1)
+	db.SetCode(contract, code)
+	trie.SetAccount(contract, 1, new(big.Int), crypto.Keccak256Hash(code))
+
+	db.SetBalance(user, big.NewInt(1000000000))
+	db.SetNonce(user, 7)
+	trie.SetAccount(user, 7, big.NewInt(1000000000), EmptyCodeHash)
+
+	for i := 0; i < 10; i++ {
+		slot := common.BigToHash(big.NewInt(int64(i)))
+		value := common.BigToHash(big.NewInt(int64(i * 1000)))
+		db.SetState(contract, slot, value)
+		trie.SetStorage(contract, slot, value)
+	}
+
+	root := db.IntermediateRoot(false)
+	et.Assert(trie.Root() == root.Hex(), "State root was not correct", trie.Root())
+
+	slots := []string{common.BigToHash(big.NewInt(3)).Hex(), common.BigToHash(big.NewInt(0)).Hex(), common.BigToHash(big.NewInt(42)).Hex()}
+	result, err := trie.GetProof(contract, slots)
+	et.Assert(err == nil, "Error was thrown on getting the proof")
+
+	expected, _ := db.GetProof(contract)
+	et.Assert(len(result.AccountProof) == len(expected), "Account proof length was not correct", len(result.AccountProof))
+	for i := range expected {
+		et.Assert(result.AccountProof[i] == hexutil.Encode(expected[i]), "Account proof node was not correct", i)
+	}
+	for i, slot := range slots {
+		expected, _ := db.GetStorageProof(contract, common.HexToHash(slot))
+		et.Assert(len(result.StorageProof[i].Proof) == len(expected), "Storage proof length was not correct", slot)
+		for j := range expected {
+			et.Assert(result.StorageProof[i].Proof[j] == hexutil.Encode(expected[j]), "Storage proof node was not correct", j, slot)
+		}
+	}
+	et.Assert(result.StorageProof[0].Value.ToInt().Int64() == 3000, "Storage value was not correct")
+	et.Assert(result.StorageProof[2].Value.ToInt().Sign() == 0, "Value of empty slot was not zero")
+
+	et.Assert(VerifyAccountProof(trie.Root(), result), "Account proof did not validate")
+
+	for _, address := range []common.Address{user, absent} {
+		result, err := trie.GetProof(address, slots[:1])
+		et.Assert(err == nil, "Error was thrown on getting the proof of", address.Hex())
+		et.Assert(VerifyAccountProof(trie.Root(), result), "Account proof did not validate", address.Hex())
+	}
+}
+
+func TestGetProofShortKeys(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	trie := NewStateTrie()
+	contract := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	trie.SetStorage(contract, common.BigToHash(big.NewInt(1)), common.BigToHash(big.NewInt(5)))
+
+	result, err := trie.GetProof(contract, []string{"0x1", "0x01", common.BigToHash(big.NewInt(1)).Hex()})
+	et.Assert(err == nil, "Error was thrown on short storage keys")
+	for _, storage := range result.StorageProof {
+		et.Assert(storage.Value.ToInt().Int64() == 5, "Incorrect value of the key", storage.Key)
+	}
+	et.Assert(result.StorageProof[0].Key == "0x1", "The key was not returned as requested")
+	et.Assert(VerifyAccountProof(trie.Root(), result), "Account proof with short keys did not validate")
+
+	_, err = trie.GetProof(contract, []string{"0x" + common.Bytes2Hex(make([]byte, 33))})
+	et.Assert(err != nil, "Error was not thrown on key longer than 32 bytes")
+	et.Assert(err.Error() == invalidSlot, "Incorrect message was thrown on long key")
+
+	_, err = trie.GetProof(contract, []string{"0xzz"})
+	et.Assert(err != nil, "Error was not thrown on key that is not hex")
+}
+
+// TestVerifyEthGetProof verifies a real eth_getProof response. The response in testdata is of the Goerli SystemConfig
+// proxy at block 8481106, whose state root is stateRoot:
+// cast proof 0xAe851f927Ee40dE99aaBb7461C00f9622ab91d60 0x65a7ed542fb37fe237fdfbdd70b31598523fe5b32879e307bae27a0bd9581c08 --block 8481106
+func TestVerifyEthGetProof(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	const stateRoot = "0x070ef87d6d3a8a132dfb45cbbc86daf545a45f1a0263bd28a304e465327f3557"
+
+	data, err := ioutil.ReadFile("testdata/eth_getProof.json")
+	et.Assert(err == nil, "Error was thrown on reading the response")
+
+	result := &AccountResult{}
+	err = json.Unmarshal(data, result)
+	et.Assert(err == nil, "Error was thrown on parsing the response")
+	et.Assert(VerifyAccountProof(stateRoot, result), "The eth_getProof response did not validate")
+
+	wrongRoot := "0x070ef87d6d3a8a132dfb45cbbc86daf545a45f1a0263bd28a304e465327f3558"
+	et.Assert(!VerifyAccountProof(wrongRoot, result), "The eth_getProof response validated against wrong root")
+
+	json.Unmarshal(data, result)
+	result.StorageProof[0].Value = (*hexutil.Big)(big.NewInt(1))
+	et.Assert(!VerifyAccountProof(stateRoot, result), "The eth_getProof response validated wrong storage value")
+
+	json.Unmarshal(data, result)
+	result.Nonce = 2
+	et.Assert(!VerifyAccountProof(stateRoot, result), "The eth_getProof response validated wrong nonce")
+}
+
+func TestVerifyAccountProofTampered(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	trie := NewStateTrie()
+	user := common.HexToAddress("0x00000000000000000000000000000000000000bb")
+	trie.SetAccount(user, 1, big.NewInt(100), EmptyCodeHash)
+	trie.SetStorage(user, common.Hash{1}, common.Hash{2})
+
+	result, _ := trie.GetProof(user, []string{common.Hash{1}.Hex()})
+
+	// Round trip through JSON as the proof would come from eth_getProof
+	data, _ := json.Marshal(result)
+	tampered := &AccountResult{}
+	json.Unmarshal(data, tampered)
+	et.Assert(VerifyAccountProof(trie.Root(), tampered), "Account proof did not validate after JSON round trip")
+
+	tampered.Balance = (*hexutil.Big)(big.NewInt(101))
+	et.Assert(!VerifyAccountProof(trie.Root(), tampered), "Account proof validated wrong balance")
+
+	json.Unmarshal(data, tampered)
+	tampered.StorageProof[0].Value = (*hexutil.Big)(big.NewInt(3))
+	et.Assert(!VerifyAccountProof(trie.Root(), tampered), "Account proof validated wrong storage value")
+
+	json.Unmarshal(data, tampered)
+	tampered.Nonce = 0
+	et.Assert(!VerifyAccountProof(trie.Root(), tampered), "Account proof validated wrong nonce")
+}
@@ -0,0 +1,281 @@
+package patricia
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"math/big"
+	"strings"
+	"sync"
+)
+
+const (
+	negativeBalance = "Incorrect balance - Balance must not be negative"
+	invalidSlot     = "Incorrect storage key - The key is not a hex of at most 32 bytes"
+)
+
+// EmptyCodeHash is the code hash of accounts without code
+var EmptyCodeHash = crypto.Keccak256Hash(nil)
+
+// Account is the value stored in the state trie for every address, in the order it is RLP encoded
+type Account struct {
+	Nonce    uint64
+	Balance  *big.Int
+	Root     common.Hash // Root of the storage trie
+	CodeHash []byte
+}
+
+// AccountResult is the proof of an account and some of its storage slots as returned by eth_getProof
+type AccountResult struct {
+	Address      common.Address  `json:"address"`
+	AccountProof []string        `json:"accountProof"`
+	Balance      *hexutil.Big    `json:"balance"`
+	CodeHash     common.Hash     `json:"codeHash"`
+	Nonce        hexutil.Uint64  `json:"nonce"`
+	StorageHash  common.Hash     `json:"storageHash"`
+	StorageProof []StorageResult `json:"storageProof"`
+}
+
+// StorageResult is the proof of a single storage slot as returned by eth_getProof
+type StorageResult struct {
+	Key   string       `json:"key"`
+	Value *hexutil.Big `json:"value"`
+	Proof []string     `json:"proof"`
+}
+
+// StateTrie is a secure trie of accounts, each of them with its own secure storage trie, laid out the way Ethereum keeps its state
+type StateTrie struct {
+	accounts *MerkleTree
+	state    map[common.Address]*Account
+	storage  map[common.Address]*MerkleTree
+	mutex    sync.RWMutex
+}
+
+func (state *StateTrie) account(address common.Address) *Account {
+	account, ok := state.state[address]
+	if !ok {
+		account = &Account{Balance: new(big.Int), Root: EmptyRoot, CodeHash: EmptyCodeHash[:]}
+		state.state[address] = account
+		state.storage[address] = NewSecureMerkleTree()
+	}
+	return account
+}
+
+func (state *StateTrie) commit(address common.Address, account *Account) error {
+	encoded, err := rlp.EncodeToBytes(account)
+	if err != nil {
+		return err
+	}
+	return state.accounts.Put(address[:], encoded)
+}
+
+// SetAccount sets the nonce, the balance and the code hash of the account, creating it if needed
+func (state *StateTrie) SetAccount(address common.Address, nonce uint64, balance *big.Int, codeHash common.Hash) error {
+	if balance.Sign() < 0 {
+		return errors.New(negativeBalance)
+	}
+
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+
+	account := state.account(address)
+	account.Nonce = nonce
+	account.Balance = new(big.Int).Set(balance)
+	account.CodeHash = codeHash.Bytes()
+
+	return state.commit(address, account)
+}
+
+// SetStorage sets the value of the storage slot of the account, creating the account if needed.
+// Zero value clears the slot
+func (state *StateTrie) SetStorage(address common.Address, slot common.Hash, value common.Hash) error {
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+
+	account := state.account(address)
+	storage := state.storage[address]
+
+	var err error
+	if value == (common.Hash{}) {
+		err = storage.Delete(slot[:])
+	} else {
+		encoded, _ := rlp.EncodeToBytes(common.TrimLeftZeroes(value[:]))
+		err = storage.Put(slot[:], encoded)
+	}
+	if err != nil {
+		return err
+	}
+
+	account.Root = common.HexToHash(storage.Root())
+	return state.commit(address, account)
+}
+
+// GetProof returns the account together with proofs for it and for the given storage slots.
+// Accounts that are not in the state are proven absent with empty values
+func (state *StateTrie) GetProof(address common.Address, slots []string) (*AccountResult, error) {
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+
+	accountProof, err := state.accounts.prove(address[:])
+	if err != nil {
+		return nil, err
+	}
+
+	result := &AccountResult{
+		Address:      address,
+		AccountProof: accountProof,
+		Balance:      new(hexutil.Big),
+		CodeHash:     EmptyCodeHash,
+		StorageHash:  EmptyRoot,
+		StorageProof: make([]StorageResult, len(slots)),
+	}
+
+	account, ok := state.state[address]
+	if ok {
+		result.Balance = (*hexutil.Big)(new(big.Int).Set(account.Balance))
+		result.CodeHash = common.BytesToHash(account.CodeHash)
+		result.Nonce = hexutil.Uint64(account.Nonce)
+		result.StorageHash = account.Root
+	}
+
+	for i, slot := range slots {
+		key, err := storageKey(slot)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			result.StorageProof[i] = StorageResult{slot, new(hexutil.Big), []string{}}
+			continue
+		}
+
+		storage := state.storage[address]
+		proof, err := storage.prove(key[:])
+		if err != nil {
+			return nil, err
+		}
+		encoded, err := storage.trie.TryGet(storage.key(key[:]))
+		if err != nil {
+			return nil, err
+		}
+		value, err := decodeStorage(encoded)
+		if err != nil {
+			return nil, err
+		}
+		result.StorageProof[i] = StorageResult{slot, (*hexutil.Big)(value), proof}
+	}
+
+	return result, nil
+}
+
+// storageKey decodes the storage key the way eth_getProof does. Keys shorter than 32 bytes, e.g. 0x0, are left padded with zeroes
+func storageKey(slot string) (common.Hash, error) {
+	s := strings.TrimPrefix(strings.TrimPrefix(slot, "0x"), "0X")
+	if len(s)%2 == 1 {
+		s = "0" + s
+	}
+	key, err := hex.DecodeString(s)
+	if err != nil || len(key) > common.HashLength {
+		return common.Hash{}, errors.New(invalidSlot)
+	}
+	return common.BytesToHash(key), nil
+}
+
+func decodeStorage(encoded []byte) (*big.Int, error) {
+	if len(encoded) == 0 {
+		return new(big.Int), nil
+	}
+	_, content, _, err := rlp.Split(encoded)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(content), nil
+}
+
+// Root returns the state root
+func (state *StateTrie) Root() string {
+	return state.accounts.Root()
+}
+
+// String returns human readable version of the state
+func (state *StateTrie) String() string {
+	state.mutex.RLock()
+	defer state.mutex.RUnlock()
+
+	return fmt.Sprintf("Root: %v, Accounts: %v\n", state.accounts.Root(), len(state.state))
+}
+
+// MarshalJSON Creates JSON version of the needed fields of the state
+func (state *StateTrie) MarshalJSON() ([]byte, error) {
+	state.mutex.RLock()
+	defer state.mutex.RUnlock()
+
+	res := fmt.Sprintf("{\"root\":\"%v\", \"accounts\":%v}", state.accounts.Root(), len(state.state))
+	return []byte(res), nil
+}
+
+// VerifyAccountProof emulates how third party would validate an eth_getProof response. It validates the account
+// against the state root and every storage slot against the storage hash of the account
+func VerifyAccountProof(stateRoot string, result *AccountResult) bool {
+	if result.Balance == nil {
+		return false
+	}
+
+	value, err := VerifyProof(stateRoot, crypto.Keccak256(result.Address[:]), result.AccountProof)
+	if err != nil {
+		return false
+	}
+
+	account := Account{
+		Nonce:    uint64(result.Nonce),
+		Balance:  result.Balance.ToInt(),
+		Root:     result.StorageHash,
+		CodeHash: result.CodeHash[:],
+	}
+	if len(value) == 0 {
+		// Absent accounts are reported as empty ones
+		if account.Nonce != 0 || account.Balance.Sign() != 0 || account.Root != EmptyRoot || result.CodeHash != EmptyCodeHash {
+			return false
+		}
+	} else {
+		expected, err := rlp.EncodeToBytes(&account)
+		if err != nil || !bytes.Equal(value, expected) {
+			return false
+		}
+	}
+
+	for _, storage := range result.StorageProof {
+		if !verifyStorageProof(result.StorageHash, storage) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func verifyStorageProof(storageHash common.Hash, storage StorageResult) bool {
+	key, err := storageKey(storage.Key)
+	if err != nil || storage.Value == nil {
+		return false
+	}
+
+	encoded, err := VerifyProof(storageHash.Hex(), crypto.Keccak256(key[:]), storage.Proof)
+	if err != nil {
+		return false
+	}
+	value, err := decodeStorage(encoded)
+	return err == nil && value.Cmp(storage.Value.ToInt()) == 0
+}
+
+// NewStateTrie returns a pointer to an initialized StateTrie without accounts
+func NewStateTrie() *StateTrie {
+	return &StateTrie{
+		accounts: NewSecureMerkleTree(),
+		state:    make(map[common.Address]*Account),
+		storage:  make(map[common.Address]*MerkleTree),
+	}
+}
@@ -0,0 +1,355 @@
+// Package persistent implements merkle tree that saves its leafs in any merkletree.Store and is loaded back from it.
+// If the store is merkletree.NodeStore and the tree is merkletree.PathMerkleTree, the changed intermediary nodes are saved as well.
+// If the store is merkletree.RootRecorder, every new root is recorded in it.
+// If the store is merkletree.SaltStore, the salts of the leafs added with AppendSalted are saved and loaded back as well
+package persistent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/LimeChain/merkletree"
+	"github.com/LimeChain/merkletree/memory"
+	"sync"
+)
+
+const (
+	invalidSize   = "Incorrect size - Size out of bounds"
+	missingLeaf   = "Incorrect store - Missing leaf"
+	duplicateLeaf = "Incorrect store - Duplicate leaf"
+	noTruncate    = "Incorrect tree - The tree can not be truncated"
+	noSaltStore   = "Incorrect store - The store can not save salts"
+	noSalts       = "Incorrect tree - The tree can not keep salts"
+)
+
+// salter is implemented by trees that keep the salts of their leafs, e.g. memory.MerkleTree
+type salter interface {
+	SetSalt(index int, salt []byte) error
+}
+
+// MerkleTree wraps a FullMerkleTree and appends every added leaf to the store
+type MerkleTree struct {
+	merkletree.FullMerkleTree
+	store   merkletree.Store
+	rawFrom int   // The first leaf added without recalculation or -1
+	lastErr error // The failure of the store in the last Recalculate
+	Mutex   sync.Mutex
+}
+
+// Append hashes and inserts data in the tree and appends its hash to the store.
+// If writing to the store fails, the leaf is removed from the tree and the error is returned
+func (tree *MerkleTree) Append(data []byte) (index int, hash string, err error) {
+	tree.Mutex.Lock()
+	defer tree.Mutex.Unlock()
+
+	index, hash = tree.FullMerkleTree.Add(data)
+	if err = tree.addHashToStore(index, hash); err != nil {
+		return -1, "", tree.rollback(index, err)
+	}
+	return index, hash, nil
+}
+
+// RawAppend adds data to the tree without recalculating the tree and appends its hash to the store.
+// If writing to the store fails, the leaf is removed from the tree and the error is returned
+func (tree *MerkleTree) RawAppend(data []byte) (index int, hash string, err error) {
+	tree.Mutex.Lock()
+	defer tree.Mutex.Unlock()
+
+	index, hash = tree.FullMerkleTree.RawAdd(data)
+	if tree.rawFrom < 0 {
+		tree.rawFrom = index
+	}
+	if err = tree.addHashToStore(index, hash); err != nil {
+		return -1, "", tree.rollback(index, err)
+	}
+	return index, hash, nil
+}
+
+// AppendSalted generates random salt and appends keccak256(salt || data) as memory.MerkleTree.AddSalted does, saving the salt
+// in the store after the leaf. If saving the salt fails, the leaf is removed from the store and the tree, so no stored
+// leaf is disclosed with a salt that is not its own. The store must be merkletree.SaltStore and the tree must keep the
+// salts, e.g. memory.MerkleTree, whose Disclose reveals the data
+func (tree *MerkleTree) AppendSalted(data []byte) (index int, hash string, err error) {
+	saltStore, ok := tree.store.(merkletree.SaltStore)
+	if !ok {
+		return -1, "", errors.New(noSaltStore)
+	}
+	salter, ok := tree.FullMerkleTree.(salter)
+	if !ok {
+		return -1, "", errors.New(noSalts)
+	}
+	salt, err := memory.NewSalt()
+	if err != nil {
+		return -1, "", err
+	}
+
+	tree.Mutex.Lock()
+	defer tree.Mutex.Unlock()
+
+	index, hash = tree.FullMerkleTree.Add(append(salt, data...))
+	if err = tree.addHashToStore(index, hash); err != nil {
+		return -1, "", tree.rollback(index, err)
+	}
+	if err = saltStore.SaveSalt(index, salt); err != nil {
+		return -1, "", tree.unstore(index, err)
+	}
+	if err = salter.SetSalt(index, salt); err != nil {
+		return -1, "", tree.unstore(index, err)
+	}
+	return index, hash, nil
+}
+
+// Add is Append that keeps the tree a merkletree.MerkleTree. Returns index -1 and empty hash if writing to the store failed
+func (tree *MerkleTree) Add(data []byte) (index int, hash string) {
+	index, hash, _ = tree.Append(data)
+	return index, hash
+}
+
+// RawAdd is RawAppend that keeps the tree a merkletree.MerkleTree. Returns index -1 and empty hash if writing to the store failed
+func (tree *MerkleTree) RawAdd(data []byte) (index int, hash string) {
+	index, hash, _ = tree.RawAppend(data)
+	return index, hash
+}
+
+// Recalculate is RecalculateContext that keeps the tree a merkletree.MerkleTree. Failures of the store are kept for RecalculateErr
+func (tree *MerkleTree) Recalculate() (root string) {
+	root, err := tree.RecalculateContext(context.Background())
+	tree.Mutex.Lock()
+	tree.lastErr = err
+	tree.Mutex.Unlock()
+	return root
+}
+
+// RecalculateErr returns the failure of the store in the last Recalculate, nil if it succeeded
+func (tree *MerkleTree) RecalculateErr() error {
+	tree.Mutex.Lock()
+	defer tree.Mutex.Unlock()
+
+	return tree.lastErr
+}
+
+// RecalculateContext recreates the tree and saves the intermediary nodes changed by the leafs added with RawAdd.
+// The root is returned even if saving the nodes or recording the root fails. Saving the nodes is retried on the next
+// recalculation. The store is not written if the context is done
+func (tree *MerkleTree) RecalculateContext(ctx context.Context) (root string, err error) {
+	tree.Mutex.Lock()
+	defer tree.Mutex.Unlock()
+
+	root = tree.FullMerkleTree.Recalculate()
+	if tree.rawFrom < 0 {
+		return root, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return root, err
+	}
+	if err := tree.saveNodes(tree.rawFrom, tree.Length()); err != nil {
+		return root, err
+	}
+	tree.rawFrom = -1
+	return root, tree.recordRoot()
+}
+
+// Truncate removes the leafs from the given size onwards. The in-memory tree is only truncated once they are removed from the store.
+// The leafs added with RawAdd are recalculated first, as Truncate expects all levels of the tree to be calculated
+func (tree *MerkleTree) Truncate(size int) error {
+	tree.Mutex.Lock()
+	defer tree.Mutex.Unlock()
+
+	if size < 0 || size > tree.Length() {
+		return errors.New(invalidSize)
+	}
+	truncater, ok := tree.FullMerkleTree.(merkletree.Truncater)
+	if !ok {
+		return errors.New(noTruncate)
+	}
+	if tree.rawFrom >= 0 {
+		tree.FullMerkleTree.Recalculate()
+	}
+	if err := tree.store.TruncateLeaves(size); err != nil {
+		return err
+	}
+	if err := truncater.Truncate(size); err != nil {
+		return err
+	}
+
+	if tree.rawFrom >= size {
+		tree.rawFrom = -1
+	}
+	if size > 0 && tree.rawFrom < 0 {
+		if err := tree.saveNodes(size-1, size); err != nil { // The right edge of the truncated tree
+			return err
+		}
+		return tree.recordRoot()
+	}
+	return nil
+}
+
+// Store returns the store of the tree
+func (tree *MerkleTree) Store() merkletree.Store {
+	return tree.store
+}
+
+func (tree *MerkleTree) addHashToStore(index int, hash string) error {
+	if err := tree.store.AppendLeaves(index, []string{hash}); err != nil {
+		return err
+	}
+	if tree.rawFrom < 0 {
+		err := tree.saveNodes(index, index+1)
+		if err == nil {
+			err = tree.recordRoot()
+		}
+		if err != nil {
+			if truncateErr := tree.store.TruncateLeaves(index); truncateErr != nil {
+				return fmt.Errorf("%v. Removing the leaf from the store failed: %v", err, truncateErr)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// recordRoot records the current root if the store keeps the history of the roots
+func (tree *MerkleTree) recordRoot() error {
+	recorder, ok := tree.store.(merkletree.RootRecorder)
+	if !ok || tree.Length() == 0 {
+		return nil
+	}
+	return recorder.RecordRoot(tree.Length(), tree.FullMerkleTree.Root())
+}
+
+// rollback removes the leaf at the given index, which failed to be written to the store, from the tree
+func (tree *MerkleTree) rollback(index int, err error) error {
+	truncater, ok := tree.FullMerkleTree.(merkletree.Truncater)
+	if !ok {
+		return fmt.Errorf("%v. Rolling back the tree failed: %v", err, noTruncate)
+	}
+	if tree.rawFrom >= 0 {
+		tree.FullMerkleTree.Recalculate() // Truncate expects all levels of the tree to be calculated
+	}
+	if truncateErr := truncater.Truncate(index); truncateErr != nil {
+		return fmt.Errorf("%v. Rolling back the tree failed: %v", err, truncateErr)
+	}
+	if tree.rawFrom >= index {
+		tree.rawFrom = -1
+	}
+	return err
+}
+
+// unstore removes the stored leaf at the given index, whose salt failed to be saved, from the store and the tree.
+// The right edge of the tree is saved again, as the path of the leaf replaced some of its nodes
+func (tree *MerkleTree) unstore(index int, err error) error {
+	if truncateErr := tree.store.TruncateLeaves(index); truncateErr != nil {
+		return fmt.Errorf("%v. Removing the leaf from the store failed: %v", err, truncateErr)
+	}
+	if rollbackErr := tree.rollback(index, err); rollbackErr != err {
+		return rollbackErr
+	}
+	if index > 0 && tree.rawFrom < 0 {
+		if saveErr := tree.saveNodes(index-1, index); saveErr != nil {
+			return fmt.Errorf("%v. Saving the right edge of the tree failed: %v", err, saveErr)
+		}
+	}
+	return err
+}
+
+// saveNodes saves the nodes on the paths of the leafs from the given index up to the given one, each of them once
+func (tree *MerkleTree) saveNodes(from, to int) error {
+	nodeStore, ok := tree.store.(merkletree.NodeStore)
+	if !ok {
+		return nil
+	}
+	pathTree, ok := tree.FullMerkleTree.(merkletree.PathMerkleTree)
+	if !ok {
+		return nil
+	}
+
+	nodes := make([]merkletree.StoredNode, 0)
+	for i := from; i < to; i++ {
+		path, err := pathTree.Path(i)
+		if err != nil {
+			return err
+		}
+		for level, node := range path {
+			if i == from || node.Index() != (i-1)>>uint(level) { // Not saved with the path of the previous leaf
+				nodes = append(nodes, merkletree.StoredNode{Level: level, Index: node.Index(), Hash: node.Hash()})
+			}
+		}
+	}
+
+	return nodeStore.SaveNodes(nodes)
+}
+
+// contextIterator is implemented by stores that can stop the iteration of the leafs when the context is done
+type contextIterator interface {
+	IterateLeavesContext(ctx context.Context, fn func(index int, hash string) error) error
+}
+
+// Load inserts the leafs of the store in the tree and returns it wrapped for saving of the next additions.
+// The tree is expected to be empty
+func Load(tree merkletree.FullMerkleTree, store merkletree.Store) (*MerkleTree, error) {
+	return LoadContext(context.Background(), tree, store)
+}
+
+// LoadContext is Load that stops when the context is done, leaving the tree partially loaded.
+// Fails with the first missing or duplicate index instead of shifting the leafs after it
+func LoadContext(ctx context.Context, tree merkletree.FullMerkleTree, store merkletree.Store) (*MerkleTree, error) {
+	next := 0
+	insert := func(index int, hash string) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if index > next {
+			return fmt.Errorf("%v %v", missingLeaf, next)
+		}
+		if index < next {
+			return fmt.Errorf("%v %v", duplicateLeaf, index)
+		}
+		tree.RawInsert(hash)
+		next++
+		return nil
+	}
+
+	var err error
+	if iterator, ok := store.(contextIterator); ok {
+		err = iterator.IterateLeavesContext(ctx, insert)
+	} else {
+		err = store.IterateLeaves(insert)
+	}
+	if err != nil {
+		return nil, err
+	}
+	tree.Recalculate()
+
+	if err := loadSalts(tree, store, next); err != nil {
+		return nil, err
+	}
+
+	return &MerkleTree{FullMerkleTree: tree, store: store, rawFrom: -1}, nil
+}
+
+// loadSalts keeps the stored salts of the first size leafs with them, if the store saves salts and the tree keeps them.
+// Salts past the last leaf belong to leafs that failed to be stored and are skipped
+func loadSalts(tree merkletree.FullMerkleTree, store merkletree.Store, size int) error {
+	saltStore, ok := store.(merkletree.SaltStore)
+	if !ok {
+		return nil
+	}
+	salter, ok := tree.(salter)
+	if !ok {
+		return nil
+	}
+
+	salts, err := saltStore.LoadSalts()
+	if err != nil {
+		return err
+	}
+	for index, salt := range salts {
+		if index >= size {
+			continue
+		}
+		if err := salter.SetSalt(index, salt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package persistent_test
+
+import (
+	"fmt"
+	"github.com/LimeChain/merkletree/memory"
+	"github.com/LimeChain/merkletree/merkletreetest"
+	"github.com/LimeChain/merkletree/persistent"
+)
+
+func Example() {
+	store := &merkletreetest.MemoryStore{} // Any merkletree.Store, e.g. postgres.Store
+	tree, err := persistent.Load(memory.NewMerkleTree(), store)
+	if err != nil {
+		panic(err)
+	}
+	tree.Add([]byte("Merkle Trees Rock"))
+
+	restarted, _ := persistent.Load(memory.NewMerkleTree(), store)
+	fmt.Println(restarted.Root() == tree.Root())
+
+	// Output:
+	// true
+}
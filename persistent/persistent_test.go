@@ -0,0 +1,354 @@
+package persistent
+
+import (
+	"context"
+	"errors"
+	"github.com/LimeChain/merkletree"
+	"github.com/LimeChain/merkletree/memory"
+	"github.com/LimeChain/merkletree/merkletreetest"
+	"strconv"
+	"testing"
+)
+
+// assertNodesStored checks that the store has exactly the nodes of the in-memory tree
+func assertNodesStored(et *merkletreetest.ExtendedTesting, store *merkletreetest.MemoryStore, tree *memory.MerkleTree) {
+	count := 0
+	for level, nodes := range tree.Nodes {
+		for index, node := range nodes {
+			hash, err := store.LoadNode(level, index)
+			et.Assert(err == nil && hash == node.Hash(), "The node at level "+strconv.Itoa(level)+" and index "+strconv.Itoa(index)+" was not stored")
+			count++
+		}
+	}
+	et.Assert(len(store.Nodes) == count, "The store had nodes that are not part of the tree")
+}
+
+func TestLoad(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	store := &merkletreetest.MemoryStore{}
+
+	tree, err := Load(memory.NewMerkleTree(), store)
+	et.Assert(err == nil, "Error was thrown on loading empty store")
+	et.Assert(tree.Length() == 0, "The tree loaded from empty store was not empty")
+	et.Assert(tree.Store() == store, "The tree did not return its store")
+
+	for i := 0; i < 5; i++ {
+		index, _ := tree.Add([]byte("Leaf" + strconv.Itoa(i)))
+		et.Assert(index == i, "The index of the addition was not correct")
+	}
+	et.Assert(len(store.Leafs) == 5, "The leafs were not appended to the store")
+
+	loaded, err := Load(memory.NewMerkleTree(), store)
+	et.Assert(err == nil, "Error was thrown on loading the store")
+	et.Assert(loaded.Root() == tree.Root(), "The loaded root was not the root of the saved tree")
+	et.Assert(loaded.Length() == 5, "The loaded length was not correct")
+}
+
+func TestAddSavesNodes(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	store := &merkletreetest.MemoryStore{}
+	memoryTree := memory.NewMerkleTree()
+	tree, _ := Load(memoryTree, store)
+
+	for i := 0; i < 7; i++ {
+		tree.Add([]byte("Leaf" + strconv.Itoa(i)))
+		assertNodesStored(et, store, memoryTree)
+	}
+
+	for i := 7; i < 11; i++ {
+		tree.RawAdd([]byte("Leaf" + strconv.Itoa(i)))
+	}
+	tree.Recalculate()
+	et.Assert(len(store.Leafs) == 11, "The raw additions were not appended to the store")
+	assertNodesStored(et, store, memoryTree)
+}
+
+func TestTruncate(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	store := &merkletreetest.MemoryStore{}
+	memoryTree := memory.NewMerkleTree()
+	tree, _ := Load(memoryTree, store)
+
+	for i := 0; i < 9; i++ {
+		tree.Add([]byte("Leaf" + strconv.Itoa(i)))
+	}
+
+	for _, size := range []int{6, 3, 1} {
+		err := tree.Truncate(size)
+		et.Assert(err == nil, "Error was thrown on truncate")
+		et.Assert(len(store.Leafs) == size, "The leafs were not truncated in the store")
+		assertNodesStored(et, store, memoryTree)
+	}
+
+	err := tree.Truncate(2)
+	et.Assert(err != nil && err.Error() == invalidSize, "Error was not thrown on truncating to bigger size")
+
+	tree.Add([]byte("Leaf1"))
+	loaded, _ := Load(memory.NewMerkleTree(), store)
+	et.Assert(loaded.Root() == tree.Root(), "The loaded root was not the root of the truncated tree")
+	assertNodesStored(et, store, memoryTree)
+}
+
+func TestTruncateRawAdditions(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	store := &merkletreetest.MemoryStore{}
+	memoryTree := memory.NewMerkleTree()
+	tree, _ := Load(memoryTree, store)
+
+	tree.Add([]byte("Leaf0"))
+	for i := 1; i < 6; i++ {
+		tree.RawAdd([]byte("Leaf" + strconv.Itoa(i)))
+	}
+	err := tree.Truncate(3)
+	et.Assert(err == nil && len(store.Leafs) == 3 && tree.Length() == 3, "The raw additions were not truncated", err)
+
+	// The remaining raw additions are saved on the next recalculation
+	root, err := tree.RecalculateContext(context.Background())
+	expected := memory.NewMerkleTree()
+	for i := 0; i < 3; i++ {
+		expected.Add([]byte("Leaf" + strconv.Itoa(i)))
+	}
+	et.Assert(err == nil && root == expected.Root(), "The truncated tree was not the tree of the remaining leafs", err)
+	assertNodesStored(et, store, memoryTree)
+	loaded, _ := Load(memory.NewMerkleTree(), store)
+	et.Assert(loaded.Root() == root, "The loaded root was not the root of the truncated tree")
+
+	tree.RawAdd([]byte("Leaf3"))
+	et.Assert(tree.Truncate(1) == nil && len(store.Leafs) == 1, "The leafs were not truncated below the raw additions")
+	assertNodesStored(et, store, memoryTree)
+}
+
+func TestAppendFailure(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	store := &merkletreetest.MemoryStore{}
+	memoryTree := memory.NewMerkleTree()
+	tree, _ := Load(memoryTree, store)
+	_, isAppender := interface{}(tree).(merkletree.Appender)
+	et.Assert(isAppender, "The tree did not implement the Appender interface")
+
+	for i := 0; i < 5; i++ {
+		tree.Add([]byte("Leaf" + strconv.Itoa(i)))
+	}
+	root := tree.Root()
+
+	store.Err = errors.New("connection refused")
+	index, hash, err := tree.Append([]byte("Leaf5"))
+	et.Assert(err == store.Err, "The error of the store was not returned")
+	et.Assert(index == -1 && hash == "", "Index and hash were returned for failed addition")
+	et.Assert(tree.Length() == 5 && tree.Root() == root, "The failed addition was not rolled back")
+
+	index, _ = tree.Add([]byte("Leaf5"))
+	et.Assert(index == -1, "The index of failed Add was not -1")
+	et.Assert(tree.Length() == 5, "The failed Add was not rolled back")
+
+	store.Err = nil
+	tree.RawAdd([]byte("Leaf5"))
+	store.Err = errors.New("connection refused")
+	_, _, err = tree.RawAppend([]byte("Leaf6"))
+	et.Assert(err == store.Err, "The error of the store was not returned for raw addition")
+	et.Assert(tree.Length() == 6, "The failed raw addition was not rolled back")
+
+	store.Err = nil
+	index, _, err = tree.Append([]byte("Leaf6"))
+	et.Assert(err == nil && index == 6, "The addition after the failures did not take the next index")
+	tree.Recalculate() // Saves the nodes of the raw addition
+	assertNodesStored(et, store, memoryTree)
+
+	loaded, _ := Load(memory.NewMerkleTree(), store)
+	et.Assert(loaded.Root() == tree.Root(), "The tree and the store diverged")
+}
+
+func TestLoadContext(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	store := &merkletreetest.MemoryStore{Leafs: []string{"0x01", "0x02"}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	tree, err := LoadContext(ctx, memory.NewMerkleTree(), store)
+	et.Assert(err == nil && tree.Length() == 2, "The tree was not loaded")
+
+	cancel()
+	_, err = LoadContext(ctx, memory.NewMerkleTree(), store)
+	et.Assert(err == context.Canceled, "The load was not stopped on cancelled context")
+}
+
+// indexedStore returns the leafs with the given indexes, like a database with edited rows would
+type indexedStore struct {
+	merkletreetest.MemoryStore
+	indexes []int
+}
+
+func (store *indexedStore) IterateLeaves(fn func(index int, hash string) error) error {
+	for i, index := range store.indexes {
+		if err := fn(index, store.Leafs[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestLoadIndexes(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	leafs := []string{"0x01", "0x02", "0x03"}
+
+	_, err := Load(memory.NewMerkleTree(), &indexedStore{merkletreetest.MemoryStore{Leafs: leafs}, []int{0, 1, 2}})
+	et.Assert(err == nil, "Error was thrown on loading consecutive indexes")
+
+	_, err = Load(memory.NewMerkleTree(), &indexedStore{merkletreetest.MemoryStore{Leafs: leafs}, []int{0, 2, 3}})
+	et.Assert(err != nil && err.Error() == missingLeaf+" 1", "The missing leaf was not detected")
+
+	_, err = Load(memory.NewMerkleTree(), &indexedStore{merkletreetest.MemoryStore{Leafs: leafs}, []int{0, 1, 1}})
+	et.Assert(err != nil && err.Error() == duplicateLeaf+" 1", "The duplicate leaf was not detected")
+}
+
+func TestRecordRoots(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	store := &merkletreetest.MemoryStore{}
+	tree, _ := Load(memory.NewMerkleTree(), store)
+
+	for i := 0; i < 3; i++ {
+		tree.Add([]byte("Leaf" + strconv.Itoa(i)))
+		et.Assert(len(store.Roots) == i+1, "The root of the addition was not recorded")
+	}
+	record, err := store.RootAtSize(2)
+	et.Assert(err == nil && record.Size == 2, "The root at size 2 was not recorded")
+
+	tree.RawAdd([]byte("Leaf3"))
+	tree.RawAdd([]byte("Leaf4"))
+	et.Assert(len(store.Roots) == 3, "Root was recorded for raw addition")
+	root := tree.Recalculate()
+	record, err = store.RootAtSize(5)
+	et.Assert(err == nil && record.Root == root, "The recalculated root was not recorded")
+
+	tree.Truncate(2)
+	record, _ = store.RootAtSize(2)
+	et.Assert(len(store.Roots) == 3 && record.Root == tree.Root(), "The root of the truncated tree was not recorded")
+	_, err = store.RootAtSize(5)
+	et.Assert(err != nil, "The root of the truncated leafs was kept")
+
+	store.Err = errors.New("connection refused")
+	_, _, err = tree.Append([]byte("Leaf2"))
+	et.Assert(err != nil && tree.Length() == 2, "The failed addition was not rolled back")
+}
+
+// failingSalts is a store whose salts can not be saved
+type failingSalts struct {
+	*merkletreetest.MemoryStore
+}
+
+func (store failingSalts) SaveSalt(index int, salt []byte) error {
+	return errors.New("Salts are down")
+}
+
+func TestAppendSalted(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	store := &merkletreetest.MemoryStore{}
+	tree, _ := Load(memory.NewMerkleTree(), store)
+	tree.Add([]byte("First Leaf"))
+
+	index, hash, err := tree.AppendSalted([]byte("1990-01-01"))
+	et.Assert(err == nil && index == 1, "Error was thrown on appending salted data")
+	et.Assert(store.Leafs[1] == hash && len(store.Salts[1]) == memory.SaltSize, "The salt was not saved with the leaf")
+	et.Assert(hash == memory.SaltedHash(store.Salts[1], []byte("1990-01-01")), "The leaf was not the hash of the salt and the data")
+
+	restarted := memory.NewMerkleTree()
+	_, err = Load(restarted, store)
+	et.Assert(err == nil, "Error was thrown on loading the store")
+	d, err := restarted.Disclose(1, []byte("1990-01-01"))
+	et.Assert(err == nil, "The salted leaf could not be disclosed after restart")
+	et.Assert(memory.VerifyDisclosure(tree.Root(), d), "The disclosure after restart was not verified")
+	_, err = restarted.Disclose(0, []byte("First Leaf"))
+	et.Assert(err != nil, "The unsalted leaf was disclosed after restart")
+
+	tree.Truncate(1)
+	et.Assert(len(store.Salts) == 0, "The salt of the truncated leaf was not removed")
+
+	_, _, err = tree.AppendSalted([]byte("1990-01-01"))
+	et.Assert(err == nil, "Error was thrown on appending salted data after truncate")
+	store.Err = errors.New("Store is down")
+	index, hash, err = tree.AppendSalted([]byte("1990-01-02"))
+	et.Assert(err == store.Err && index == -1 && hash == "", "The error of the store was not returned")
+	et.Assert(tree.Length() == 2 && len(store.Salts) == 1, "The failed addition was not rolled back")
+	store.Err = nil
+
+	memoryTree := memory.NewMerkleTree()
+	failing, _ := Load(memoryTree, failingSalts{store})
+	root := failing.Root()
+	index, _, err = failing.AppendSalted([]byte("1990-01-02"))
+	et.Assert(err != nil && index == -1, "The error of saving the salt was not returned")
+	et.Assert(failing.Length() == 2 && len(store.Leafs) == 2 && failing.Root() == root, "The leaf without salt was not removed")
+	et.Assert(store.Roots[len(store.Roots)-1].Size == 2 && store.Roots[len(store.Roots)-1].Root == root, "The root of the leaf without salt was kept")
+	assertNodesStored(et, store, memoryTree)
+
+	plain, _ := Load(memory.NewMerkleTree(), struct{ merkletree.Store }{store})
+	_, _, err = plain.AppendSalted([]byte("1990-01-02"))
+	et.Assert(err != nil && err.Error() == noSaltStore, "Incorrect error was thrown on store without salts")
+}
+
+// failingNodes is a store whose nodes can not be saved and, if truncateErr is set, whose leafs can not be truncated
+type failingNodes struct {
+	*merkletreetest.MemoryStore
+	truncateErr error
+}
+
+func (store failingNodes) SaveNodes(nodes []merkletree.StoredNode) error {
+	return errors.New("Nodes are down")
+}
+
+func (store failingNodes) TruncateLeaves(size int) error {
+	if store.truncateErr != nil {
+		return store.truncateErr
+	}
+	return store.MemoryStore.TruncateLeaves(size)
+}
+
+func TestRecalculateContext(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	store := &merkletreetest.MemoryStore{}
+	memoryTree := memory.NewMerkleTree()
+	tree, _ := Load(memoryTree, store)
+
+	for i := 0; i < 5; i++ {
+		tree.RawAdd([]byte("Leaf" + strconv.Itoa(i)))
+	}
+	store.Err = errors.New("connection refused")
+	root, err := tree.RecalculateContext(context.Background())
+	et.Assert(err == store.Err, "The error of the store was not returned")
+	et.Assert(root == memoryTree.Root(), "The root was not returned with the error")
+
+	store.Err = nil
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = tree.RecalculateContext(ctx)
+	et.Assert(err == context.Canceled, "The error of the context was not returned")
+	et.Assert(len(store.Roots) == 0, "The store was written after the context was done")
+
+	root, err = tree.RecalculateContext(context.Background())
+	et.Assert(err == nil && root == memoryTree.Root(), "Saving the nodes was not retried")
+	assertNodesStored(et, store, memoryTree)
+	et.Assert(len(store.Roots) == 1 && store.Roots[0].Root == root, "The root was not recorded")
+
+	// Recalculate keeps the failure of the store for RecalculateErr
+	tree.RawAdd([]byte("Leaf5"))
+	store.Err = errors.New("connection refused")
+	root = tree.Recalculate()
+	et.Assert(root == memoryTree.Root() && tree.RecalculateErr() == store.Err, "The failure of the store was not kept")
+	store.Err = nil
+	tree.Recalculate()
+	et.Assert(tree.RecalculateErr() == nil, "The failure of the store was kept after a successful recalculation")
+	assertNodesStored(et, store, memoryTree)
+}
+
+func TestAppendCompensationFailure(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	memoryStore := &merkletreetest.MemoryStore{}
+	tree, _ := Load(memory.NewMerkleTree(), failingNodes{memoryStore, nil})
+
+	index, _, err := tree.Append([]byte("Leaf0"))
+	et.Assert(err != nil && err.Error() == "Nodes are down", "The error of saving the nodes was not returned")
+	et.Assert(index == -1 && tree.Length() == 0 && len(memoryStore.Leafs) == 0, "The leaf was not removed")
+
+	tree, _ = Load(memory.NewMerkleTree(), failingNodes{memoryStore, errors.New("Leafs are down")})
+	_, _, err = tree.Append([]byte("Leaf0"))
+	et.Assert(err != nil && err.Error() == "Nodes are down. Removing the leaf from the store failed: Leafs are down",
+		"Both errors were not returned", err)
+}
@@ -0,0 +1,141 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/LimeChain/merkletree"
+	"github.com/LimeChain/merkletree/persistent"
+)
+
+// IntegrityError is returned when the stored leafs do not produce the root recorded for them, e.g. because a row in
+// hashes was edited or deleted. The divergent leaf is one of the leafs from Index to Size-1. It is exact when the root
+// before Size is recorded, as with every root recorded, or when the leafs end at Index
+type IntegrityError struct {
+	Size     int    // The size of the recorded root
+	Expected string // The recorded root
+	Actual   string // The root of the stored leafs at that size, empty if there are less leafs or the tree can not tell
+	Index    int    // The first leaf that can be divergent or -1 if the tree can not tell
+}
+
+func (err *IntegrityError) Error() string {
+	switch {
+	case err.Index < 0:
+		return fmt.Sprintf("Incorrect store - The stored leafs can not be compared with the recorded root, the tree can not tell its root at size %v. Expected root %v",
+			err.Size, err.Expected)
+	case err.Actual == "":
+		return fmt.Sprintf("Incorrect store - The stored leafs end at leaf %v. Expected root %v at size %v",
+			err.Index, err.Expected, err.Size)
+	case err.Index == err.Size-1:
+		return fmt.Sprintf("Incorrect store - The stored leafs diverge from the recorded root at leaf %v. Expected root %v at size %v, got %v",
+			err.Index, err.Expected, err.Size, err.Actual)
+	}
+	return fmt.Sprintf("Incorrect store - The stored leafs diverge from the recorded root at one of the leafs %v to %v. Expected root %v at size %v, got %v",
+		err.Index, err.Size-1, err.Expected, err.Size, err.Actual)
+}
+
+// rootAtSize returns the root the tree had at the given size
+func rootAtSize(tree merkletree.MerkleTree, size int) (string, error) {
+	if size == tree.Length() {
+		return tree.Root(), nil
+	}
+	if prefixTree, ok := tree.(merkletree.PrefixMerkleTree); ok {
+		return prefixTree.RootAt(size)
+	}
+	return "", nil
+}
+
+// verifyRoots compares the tree with the last recorded root. If they differ, the roots recorded before it are compared as
+// well and the divergent leaf is after the last matching root. Roots before the length of the tree can only be compared
+// if the tree is merkletree.PrefixMerkleTree, otherwise they fail the verification with Index -1
+func verifyRoots(ctx context.Context, db *sql.DB, name string, tree merkletree.MerkleTree, verify func(size int, root string, signature []byte) bool) error {
+	latest, err := rootAt(ctx, db, LatestRootQuery, name)
+	if err != nil {
+		if err.Error() == noRoot {
+			return nil
+		}
+		return err
+	}
+	if err := verifySignature(latest, verify); err != nil {
+		return err
+	}
+	if latest.Size > tree.Length() {
+		return &IntegrityError{Size: latest.Size, Expected: latest.Root, Index: tree.Length()}
+	}
+	actual, err := rootAtSize(tree, latest.Size)
+	if err != nil {
+		return err
+	}
+	if actual == "" {
+		return &IntegrityError{Size: latest.Size, Expected: latest.Root, Index: -1}
+	}
+	if actual == latest.Root {
+		return nil
+	}
+
+	rows, err := db.QueryContext(ctx, RootsQuery, name, latest.Size)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	matched := 0
+	for rows.Next() {
+		var record merkletree.RootRecord
+		var root, signature []byte
+		if err := rows.Scan(&record.Size, &root, &record.CreatedAt, &signature); err != nil {
+			return err
+		}
+		record.Root = nodeHash(root)
+		treeRoot, err := rootAtSize(tree, record.Size)
+		if err != nil || treeRoot == "" {
+			return &IntegrityError{Size: latest.Size, Expected: latest.Root, Actual: actual, Index: -1}
+		}
+		if treeRoot != record.Root {
+			return &IntegrityError{Size: record.Size, Expected: record.Root, Actual: treeRoot, Index: matched}
+		}
+		matched = record.Size
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return &IntegrityError{Size: latest.Size, Expected: latest.Root, Actual: actual, Index: matched}
+}
+
+func verifySignature(record merkletree.RootRecord, verify func(size int, root string, signature []byte) bool) error {
+	if verify == nil {
+		return nil
+	}
+	signature, err := hashBytes(record.Signature)
+	if err != nil || !verify(record.Size, record.Root, signature) {
+		return fmt.Errorf("%v %v", invalidSignature, record.Size)
+	}
+	return nil
+}
+
+// Verify reads the stored leafs of the tree with the name in the options into the empty tree and compares them with the
+// recorded roots, the way LoadMerkleTreeContext does on load. Meant for periodic checks of the database while the
+// loaded tree keeps serving. Returns *IntegrityError if the leafs diverge from the roots
+func Verify(ctx context.Context, tree merkletree.FullMerkleTree, opts Options) error {
+	name := opts.Tree
+	if name == "" {
+		name = DefaultTree
+	}
+	if !validTreeName(name) {
+		return errors.New(invalidTreeName)
+	}
+
+	db, owned, err := openDB(opts)
+	if err != nil {
+		return fmt.Errorf("Could not connect to the database: %v", err)
+	}
+	if owned {
+		defer db.Close()
+	}
+
+	if _, err := persistent.LoadContext(ctx, tree, NewNamedStore(db, name)); err != nil {
+		return fmt.Errorf("Could not load the stored hashes: %v", err)
+	}
+	return verifyRoots(ctx, db, name, tree, opts.VerifyRoot)
+}
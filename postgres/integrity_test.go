@@ -0,0 +1,143 @@
+package postgres
+
+import (
+	"context"
+	"github.com/LimeChain/merkletree"
+	"github.com/LimeChain/merkletree/memory"
+	"github.com/LimeChain/merkletree/merkletreetest"
+	"github.com/ethereum/go-ethereum/common"
+	"strings"
+	"testing"
+	"time"
+)
+
+// withoutPrefix hides RootAt of the tree, so it can only tell the root at its length
+type withoutPrefix struct {
+	merkletree.FullMerkleTree
+}
+
+func TestVerify(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	db, _, cleanup := testDB(et)
+	defer cleanup()
+	ctx := context.Background()
+
+	create := func(opts Options, count int) {
+		tree, err := LoadMerkleTreeContext(ctx, memory.NewMerkleTree(), opts)
+		if err != nil {
+			et.Fatal(err)
+		}
+		addLeafs(et, tree, count)
+	}
+	change := func(name string, index int) {
+		_, err := db.Exec("UPDATE hashes SET hash = $1 WHERE tree = $2 AND leaf_index = $3", common.Hash{1}.Bytes(), name, index)
+		if err != nil {
+			et.Fatal(err)
+		}
+	}
+	verify := func(tree merkletree.FullMerkleTree, opts Options) *IntegrityError {
+		err := Verify(ctx, tree, opts)
+		integrityErr, ok := err.(*IntegrityError)
+		if err != nil && !ok {
+			et.Fatal("The error was not *IntegrityError", err)
+		}
+		return integrityErr
+	}
+
+	// Every root recorded tells the divergent leaf
+	all := Options{DB: db, Tree: "all"}
+	create(all, 5)
+	et.Assert(Verify(ctx, memory.NewMerkleTree(), all) == nil, "Error was thrown on verifying the stored tree")
+	change("all", 2)
+	err := verify(memory.NewMerkleTree(), all)
+	et.Assert(err != nil && err.Index == 2 && err.Size == 3, "The divergent leaf was not found", err)
+	et.Assert(err != nil && strings.Contains(err.Error(), "at leaf 2."), "Incorrect message of the divergent leaf", err)
+	_, loadErr := LoadMerkleTreeContext(ctx, memory.NewMerkleTree(), all)
+	_, ok := loadErr.(*IntegrityError)
+	et.Assert(ok, "The divergent tree was loaded", loadErr)
+
+	// Between checkpoints the divergent leaf is one of the leafs after the last matching root
+	checkpoints := Options{DB: db, Tree: "checkpoints", RootCheckpoint: 2}
+	create(checkpoints, 5)
+	et.Assert(Verify(ctx, memory.NewMerkleTree(), checkpoints) == nil, "Error was thrown on verifying the tree with checkpoints")
+	change("checkpoints", 2)
+	err = verify(memory.NewMerkleTree(), checkpoints)
+	et.Assert(err != nil && err.Index == 2 && err.Size == 4, "The leafs after the last matching root were not returned", err)
+	et.Assert(err != nil && strings.Contains(err.Error(), "one of the leafs 2 to 3"), "Incorrect message of the divergent leafs", err)
+
+	// The tree without RootAt can not verify the checkpoint before its length
+	err = verify(withoutPrefix{memory.NewMerkleTree()}, checkpoints)
+	et.Assert(err != nil && err.Index == -1 && err.Actual == "", "The root the tree can not tell was not an error", err)
+
+	// Missing leafs end the tree before the recorded root
+	if _, err := db.Exec("DELETE FROM hashes WHERE tree = $1 AND leaf_index = 4", "all"); err != nil {
+		et.Fatal(err)
+	}
+	err = verify(memory.NewMerkleTree(), all)
+	et.Assert(err != nil && err.Index == 4 && err.Size == 5 && err.Actual == "", "The missing leaf was not found", err)
+	et.Assert(err != nil && strings.Contains(err.Error(), "end at leaf 4"), "Incorrect message of the missing leaf", err)
+
+	// Signatures of the last root are checked
+	signed := Options{DB: db, Tree: "signed", SignRoot: func(size int, root string) ([]byte, error) {
+		return []byte(root), nil
+	}}
+	create(signed, 3)
+	signed.VerifyRoot = func(size int, root string, signature []byte) bool {
+		return string(signature) == root
+	}
+	et.Assert(Verify(ctx, memory.NewMerkleTree(), signed) == nil, "Error was thrown on verifying the signed tree")
+	signed.VerifyRoot = func(size int, root string, signature []byte) bool {
+		return false
+	}
+	signatureErr := Verify(ctx, memory.NewMerkleTree(), signed)
+	et.Assert(signatureErr != nil && strings.HasPrefix(signatureErr.Error(), invalidSignature), "Incorrect error was thrown on invalid signature", signatureErr)
+
+	et.Assert(Verify(ctx, memory.NewMerkleTree(), Options{DB: db, Tree: "empty"}) == nil, "Error was thrown on tree without roots")
+	et.Assert(Verify(ctx, memory.NewMerkleTree(), Options{DB: db, Tree: strings.Repeat("a", 256)}).Error() == invalidTreeName, "Incorrect error was thrown on invalid name")
+}
+
+func TestTruncateAndReload(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	db, _, cleanup := testDB(et)
+	defer cleanup()
+	ctx := context.Background()
+
+	// Truncating to empty tree, to a size between the checkpoints and to a checkpoint leaves no root past the last leaf
+	for _, truncate := range []struct {
+		opts Options
+		size int
+	}{
+		{Options{DB: db, Tree: "empty"}, 0},
+		{Options{DB: db, Tree: "between", RootCheckpoint: 2}, 3},
+		{Options{DB: db, Tree: "checkpoint", RootCheckpoint: 2}, 2},
+	} {
+		tree, err := LoadMerkleTreeContext(ctx, memory.NewMerkleTree(), truncate.opts)
+		if err != nil {
+			et.Fatal(err)
+		}
+		addLeafs(et, tree, 5)
+		et.Assert(tree.Truncate(truncate.size) == nil, "Error was thrown on truncate", truncate.opts.Tree)
+		record, err := tree.RootAtTime(time.Now().Add(time.Minute))
+		et.Assert(err != nil || record.Size <= truncate.size, "The root past the last leaf was kept", truncate.opts.Tree, record.Size)
+
+		loaded, err := LoadMerkleTreeContext(ctx, memory.NewMerkleTree(), truncate.opts)
+		et.Assert(err == nil, "Error was thrown on loading the truncated tree", truncate.opts.Tree, err)
+		et.Assert(err == nil && loaded.Length() == truncate.size && loaded.Root() == tree.Root(), "The truncated tree was not loaded", truncate.opts.Tree)
+
+		addLeafs(et, tree, 2)
+		loaded, err = LoadMerkleTreeContext(ctx, memory.NewMerkleTree(), truncate.opts)
+		et.Assert(err == nil && loaded.Root() == tree.Root(), "The tree grown after truncate was not loaded", truncate.opts.Tree, err)
+	}
+
+	// The node tree deletes the roots of the truncated leafs as well
+	nodeTree, err := LoadNodeTreeContext(ctx, Options{DB: db, Tree: "nodes"})
+	if err != nil {
+		et.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		nodeTree.Add([]byte("Leaf"))
+	}
+	et.Assert(nodeTree.Truncate(0) == nil, "Error was thrown on truncating the node tree")
+	_, err = LoadMerkleTreeContext(ctx, memory.NewMerkleTree(), Options{DB: db, Tree: "nodes"})
+	et.Assert(err == nil, "Error was thrown on loading the truncated node tree", err)
+}
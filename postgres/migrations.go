@@ -0,0 +1,140 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// migrations are the versions of the schema, each of them upgrading the one before it. Version N is migrations[N-1].
+// Released migrations must never be changed, new versions are appended at the end
+var migrations = []string{
+	// 1. The hashes table of the first releases
+	`CREATE TABLE IF NOT EXISTS hashes(id SERIAL PRIMARY KEY,hash VARCHAR(66) NOT NULL);`,
+
+	// 2. Explicit leaf indexes, numbering the existing rows in the order of their id
+	`ALTER TABLE hashes ADD COLUMN leaf_index BIGINT CHECK (leaf_index >= 0);
+UPDATE hashes SET leaf_index = numbered.leaf_index FROM (SELECT id, ROW_NUMBER() OVER (ORDER BY id) - 1 AS leaf_index FROM hashes) AS numbered WHERE hashes.id = numbered.id;
+ALTER TABLE hashes ALTER COLUMN leaf_index SET NOT NULL;
+ALTER TABLE hashes ADD CONSTRAINT hashes_leaf_index_key UNIQUE (leaf_index);`,
+
+	// 3. Named trees, moving the existing rows to the default tree
+	`ALTER TABLE hashes ADD COLUMN tree VARCHAR(255) NOT NULL DEFAULT 'default';
+ALTER TABLE hashes DROP CONSTRAINT hashes_leaf_index_key;
+ALTER TABLE hashes ADD CONSTRAINT hashes_tree_leaf_index_key UNIQUE (tree, leaf_index);
+CREATE TABLE IF NOT EXISTS trees(name VARCHAR(255) PRIMARY KEY,created_at TIMESTAMPTZ NOT NULL DEFAULT now());
+INSERT INTO trees (name) SELECT DISTINCT tree FROM hashes ON CONFLICT DO NOTHING;`,
+
+	// 4. Hashes as bytes instead of hex strings
+	`ALTER TABLE hashes ALTER COLUMN hash TYPE BYTEA USING decode(CASE WHEN hash LIKE '0x%' THEN substring(hash FROM 3) ELSE hash END, 'hex');`,
+
+	// 5. Intermediary nodes by level and index. The leafs stay in hashes
+	`CREATE TABLE IF NOT EXISTS nodes(tree VARCHAR(255) NOT NULL,level INTEGER NOT NULL CHECK (level > 0),node_index BIGINT NOT NULL CHECK (node_index >= 0),hash BYTEA NOT NULL,PRIMARY KEY (tree, level, node_index));`,
+
+	// 6. The history of the roots
+	`CREATE TABLE IF NOT EXISTS roots(id BIGSERIAL PRIMARY KEY,tree VARCHAR(255) NOT NULL,size BIGINT NOT NULL CHECK (size > 0),root BYTEA NOT NULL,created_at TIMESTAMPTZ NOT NULL DEFAULT now(),signature BYTEA);
+CREATE INDEX IF NOT EXISTS roots_tree_size_idx ON roots (tree, size);
+CREATE INDEX IF NOT EXISTS roots_tree_created_at_idx ON roots (tree, created_at);`,
+
+	// 7. The salts of the salted leafs
+	`CREATE TABLE IF NOT EXISTS salts(tree VARCHAR(255) NOT NULL,leaf_index BIGINT NOT NULL CHECK (leaf_index >= 0),salt BYTEA NOT NULL,PRIMARY KEY (tree, leaf_index));`,
+}
+
+// The schema versions are kept in schema_migrations. The migrations run in a single transaction holding the advisory
+// lock of the migrations, so concurrently starting processes migrate the database only once
+const (
+	CreateMigrationsIfNotExists = "CREATE TABLE IF NOT EXISTS schema_migrations(version INTEGER PRIMARY KEY,applied_at TIMESTAMPTZ NOT NULL DEFAULT now());"
+	SchemaVersionQuery          = "SELECT COALESCE(MAX(version), 0) FROM schema_migrations"
+	InsertVersionQuery          = "INSERT INTO schema_migrations (version) VALUES ($1)"
+	MigrationsLockQuery         = "SELECT pg_advisory_xact_lock($1)"
+	HasTableQuery               = "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = $1"
+	HasColumnQuery              = "SELECT COUNT(*) FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = $1 AND column_name = $2"
+)
+
+// migrationsLock is the key of the advisory lock held while migrating
+const migrationsLock = 0x6d65726b6c65 // "merkle"
+
+// SchemaVersion is the latest version of the schema known to this package
+func SchemaVersion() int {
+	return len(migrations)
+}
+
+// Migrate upgrades the schema of the database to the latest version. Databases created before the versions were
+// recorded are recognized by their tables. Fails without changes if the database has a newer version than the known ones
+func Migrate(ctx context.Context, db *sql.DB) error {
+	dbTx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := migrate(ctx, dbTx); err != nil {
+		dbTx.Rollback()
+		return err
+	}
+	return dbTx.Commit()
+}
+
+func migrate(ctx context.Context, dbTx *sql.Tx) error {
+	if _, err := dbTx.ExecContext(ctx, MigrationsLockQuery, migrationsLock); err != nil {
+		return err
+	}
+	if _, err := dbTx.ExecContext(ctx, CreateMigrationsIfNotExists); err != nil {
+		return err
+	}
+
+	var version int
+	if err := dbTx.QueryRowContext(ctx, SchemaVersionQuery).Scan(&version); err != nil {
+		return err
+	}
+	if version > len(migrations) {
+		return fmt.Errorf("Incorrect schema - The database schema version %v is newer than the latest known version %v", version, len(migrations))
+	}
+
+	applied := version
+	if version == 0 {
+		inferred, err := unversionedSchema(ctx, dbTx)
+		if err != nil {
+			return err
+		}
+		applied = inferred
+	}
+
+	for v := version + 1; v <= len(migrations); v++ {
+		if v > applied {
+			if _, err := dbTx.ExecContext(ctx, migrations[v-1]); err != nil {
+				return fmt.Errorf("Migration to version %v failed: %v", v, err)
+			}
+		}
+		if _, err := dbTx.ExecContext(ctx, InsertVersionQuery, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unversionedSchema returns the version of a schema created before the versions were recorded
+func unversionedSchema(ctx context.Context, dbTx *sql.Tx) (int, error) {
+	exists := func(query string, args ...interface{}) (bool, error) {
+		var count int
+		err := dbTx.QueryRowContext(ctx, query, args...).Scan(&count)
+		return count > 0, err
+	}
+
+	checks := []struct {
+		query string
+		args  []interface{}
+	}{
+		{HasTableQuery, []interface{}{"hashes"}},
+		{HasColumnQuery, []interface{}{"hashes", "leaf_index"}},
+		{HasColumnQuery, []interface{}{"hashes", "tree"}},
+	}
+	for i, check := range checks {
+		ok, err := exists(check.query, check.args...)
+		if err != nil {
+			return 0, err
+		}
+		if !ok {
+			return i, nil
+		}
+	}
+	return len(checks), nil
+}
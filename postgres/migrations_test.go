@@ -0,0 +1,128 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"github.com/LimeChain/merkletree/memory"
+	"github.com/LimeChain/merkletree/merkletreetest"
+	"github.com/ethereum/go-ethereum/common"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// legacyHashes are the hex strings stored by the first releases, with and without 0x prefix
+var legacyHashes = []string{common.Hash{1}.Hex(), common.Hash{2}.Hex()[2:], common.Hash{3}.Hex()}
+
+// assertVersions checks that all versions are recorded in schema_migrations
+func assertVersions(et *merkletreetest.ExtendedTesting, db *sql.DB) {
+	var count, version int
+	err := db.QueryRow("SELECT COUNT(*), MAX(version) FROM schema_migrations").Scan(&count, &version)
+	et.Assert(err == nil && count == SchemaVersion() && version == SchemaVersion(), "The versions were not recorded", count, version)
+}
+
+// assertLegacyTree checks that the default tree has the legacy hashes in the order they were stored
+func assertLegacyTree(et *merkletreetest.ExtendedTesting, db *sql.DB) {
+	expected := memory.NewMerkleTree()
+	for _, hash := range legacyHashes {
+		expected.RawInsert(hash)
+	}
+	expected.Recalculate()
+
+	tree, err := LoadMerkleTreeContext(context.Background(), memory.NewMerkleTree(), Options{DB: db})
+	if err != nil {
+		et.Fatal(err)
+	}
+	et.Assert(tree.Length() == len(legacyHashes) && tree.Root() == expected.Root(), "The migrated tree was not the legacy tree")
+
+	var hash []byte
+	err = db.QueryRow("SELECT hash FROM hashes WHERE tree = $1 AND leaf_index = 1", DefaultTree).Scan(&hash)
+	et.Assert(err == nil && common.BytesToHash(hash) == common.Hash{2}, "The hash without prefix was not converted to bytes")
+	var name string
+	err = db.QueryRow(ListTreesQuery).Scan(&name)
+	et.Assert(err == nil && name == DefaultTree, "The legacy rows were not registered as the default tree", name)
+}
+
+func TestMigrate(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	db, _, cleanup := testDB(et)
+	defer cleanup()
+	ctx := context.Background()
+
+	// Processes starting together migrate the database once
+	var wg sync.WaitGroup
+	errs := make([]error, 3)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = Migrate(ctx, db)
+		}(i)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		et.Assert(err == nil, "Error was thrown on concurrent migration", i, err)
+	}
+	assertVersions(et, db)
+
+	et.Assert(Migrate(ctx, db) == nil, "Error was thrown on migrating the latest schema")
+	assertVersions(et, db)
+
+	for _, table := range []string{"hashes", "trees", "nodes", "roots", "salts"} {
+		var count int
+		db.QueryRow(HasTableQuery, table).Scan(&count)
+		et.Assert(count == 1, "The table was not created", table)
+	}
+
+	_, err := db.Exec(InsertVersionQuery, SchemaVersion()+1)
+	if err != nil {
+		et.Fatal(err)
+	}
+	err = Migrate(ctx, db)
+	et.Assert(err != nil && strings.HasPrefix(err.Error(), "Incorrect schema"), "Incorrect error was thrown on newer schema", err)
+}
+
+func TestMigrateFromVersion1(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	db, _, cleanup := testDB(et)
+	defer cleanup()
+
+	if _, err := db.Exec(CreateIfNotExists); err != nil {
+		et.Fatal(err)
+	}
+	for _, hash := range legacyHashes {
+		if _, err := db.Exec("INSERT INTO hashes (hash) VALUES ($1)", hash); err != nil {
+			et.Fatal(err)
+		}
+	}
+
+	et.Assert(Migrate(context.Background(), db) == nil, "Error was thrown on migrating the unversioned schema")
+	assertVersions(et, db)
+
+	var dataType string
+	db.QueryRow("SELECT data_type FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = 'hashes' AND column_name = 'hash'").Scan(&dataType)
+	et.Assert(dataType == "bytea", "The hashes were not converted from VARCHAR to BYTEA", dataType)
+	assertLegacyTree(et, db)
+}
+
+func TestMigrateFromVersion2(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	db, _, cleanup := testDB(et)
+	defer cleanup()
+
+	for _, migration := range migrations[:2] {
+		if _, err := db.Exec(migration); err != nil {
+			et.Fatal(err)
+		}
+	}
+	// The ids are not in the order of the leafs, the explicit indexes are kept
+	for i := len(legacyHashes) - 1; i >= 0; i-- {
+		if _, err := db.Exec("INSERT INTO hashes (hash, leaf_index) VALUES ($1, $2)", legacyHashes[i], i); err != nil {
+			et.Fatal(err)
+		}
+	}
+
+	et.Assert(Migrate(context.Background(), db) == nil, "Error was thrown on migrating the unversioned schema")
+	assertVersions(et, db)
+	assertLegacyTree(et, db)
+}
@@ -0,0 +1,297 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/LimeChain/merkletree"
+	"time"
+)
+
+// Mode decides how a PostgresMerkleTree shares the database with the trees of other processes
+type Mode int
+
+const (
+	// Shared trees all write to the database. Only the check that every leaf follows the one before it protects them
+	// from each other, so their roots diverge once another process adds a leaf
+	Shared Mode = iota
+	// Writer holds the advisory lock of the tree until Close, so it is the only writer among the processes in Writer mode.
+	// Loading fails if another process holds the lock
+	Writer
+	// Follower never writes. Before serving proofs, roots and leafs it applies the rows written by the writer
+	Follower
+)
+
+// The lock of the writer is the advisory lock with the key of the class and the hash of the name of the tree
+const (
+	LockTreeQuery   = "SELECT pg_try_advisory_lock($1, hashtext($2))"
+	UnlockTreeQuery = "SELECT pg_advisory_unlock($1, hashtext($2))"
+	SyncQuery       = "SELECT leaf_index, hash FROM hashes WHERE tree = $1 AND leaf_index >= $2 ORDER BY leaf_index"
+)
+
+// treeLockClass is the first key of the advisory locks of the writers
+const treeLockClass = 0x6d6b6c // "mkl"
+
+// lock takes the lock of the writer on a connection of its own, as the advisory locks belong to the connection
+func (tree *PostgresMerkleTree) lock(ctx context.Context) error {
+	conn, err := tree.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	var locked bool
+	if err := conn.QueryRowContext(ctx, LockTreeQuery, treeLockClass, tree.name).Scan(&locked); err != nil {
+		conn.Close()
+		return err
+	}
+	if !locked {
+		conn.Close()
+		return fmt.Errorf("%v: %v", treeLocked, tree.name)
+	}
+	tree.lockConn = conn
+	return nil
+}
+
+// unlock releases the lock of the writer, if the tree holds it, before returning its connection to the pool
+func (tree *PostgresMerkleTree) unlock() error {
+	if tree.lockConn == nil {
+		return nil
+	}
+	defer func() {
+		tree.lockConn.Close()
+		tree.lockConn = nil
+	}()
+	_, err := tree.lockConn.ExecContext(context.Background(), UnlockTreeQuery, treeLockClass, tree.name)
+	return err
+}
+
+// Mode returns the mode the tree was loaded in
+func (tree *PostgresMerkleTree) Mode() Mode {
+	return tree.mode
+}
+
+// Sync applies the leafs stored by other processes since the last sync. If the last applied leaf was changed, e.g.
+// because the writer truncated the tree, all leafs are loaded again. Returns the count of the applied leafs
+func (tree *PostgresMerkleTree) Sync(ctx context.Context) (applied int, err error) {
+	tree.Mutex.Lock()
+	defer tree.Mutex.Unlock()
+
+	return tree.sync(ctx)
+}
+
+// SyncErr returns the error of the last sync of the follower, nil if it succeeded. While the syncs fail Root and Length
+// keep returning the tree of the last successful sync, so health checks of the follower should report this error
+func (tree *PostgresMerkleTree) SyncErr() error {
+	tree.Mutex.Lock()
+	defer tree.Mutex.Unlock()
+
+	return tree.syncErr
+}
+
+// sync applies the new leafs and keeps the error for SyncErr
+func (tree *PostgresMerkleTree) sync(ctx context.Context) (applied int, err error) {
+	applied, err = tree.applyLeafs(ctx)
+	tree.syncErr = err
+	if err == nil {
+		tree.lastSync = time.Now()
+	}
+	return applied, err
+}
+
+// applyLeafs reads the leafs before changing the tree, so a failed sync leaves the tree of the last successful one
+func (tree *PostgresMerkleTree) applyLeafs(ctx context.Context) (applied int, err error) {
+	inner := tree.MerkleTree.FullMerkleTree // Written directly, as followers do not write to the store
+	length := inner.Length()
+	from := length - 1 // The last applied leaf is read again to detect changes
+	if from < 0 {
+		from = 0
+	}
+
+	hashes, err := tree.readLeafs(ctx, from)
+	if err != nil {
+		return 0, err
+	}
+
+	if length > 0 {
+		last, err := inner.HashAt(length - 1)
+		if err != nil {
+			return 0, err
+		}
+		if len(hashes) == 0 || hashes[0] != last {
+			truncater, ok := inner.(merkletree.Truncater)
+			if !ok {
+				return 0, errors.New(noTruncate)
+			}
+			if hashes, err = tree.readLeafs(ctx, 0); err != nil {
+				return 0, err
+			}
+			if err := truncater.Truncate(0); err != nil {
+				return 0, err
+			}
+		} else {
+			hashes = hashes[1:]
+		}
+	}
+
+	for _, hash := range hashes {
+		inner.RawInsert(hash)
+	}
+	if len(hashes) > 0 {
+		inner.Recalculate()
+	}
+	return len(hashes), nil
+}
+
+// readLeafs returns the stored hashes of the leafs from the given index onwards
+func (tree *PostgresMerkleTree) readLeafs(ctx context.Context, from int) ([]string, error) {
+	rows, err := tree.db.QueryContext(ctx, SyncQuery, tree.name, from)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hashes := make([]string, 0)
+	for rows.Next() {
+		var index int
+		var hash []byte
+		if err := rows.Scan(&index, &hash); err != nil {
+			return nil, err
+		}
+		if index != from+len(hashes) {
+			return nil, fmt.Errorf("%v at level 0 and index %v", missingNode, from+len(hashes))
+		}
+		hashes = append(hashes, nodeHash(hash))
+	}
+	return hashes, rows.Err()
+}
+
+// follow syncs the follower unless it synced less than the sync interval ago. Called with the lock held, so the sync
+// and the read after it see the same tree
+func (tree *PostgresMerkleTree) follow() error {
+	if tree.syncInterval > 0 && time.Since(tree.lastSync) < tree.syncInterval {
+		return nil
+	}
+	_, err := tree.sync(context.Background())
+	return err
+}
+
+// read runs the read on the in-memory tree. A follower holds its lock across the sync and the read, so concurrent
+// requests never read a tree in the middle of a sync. A failed sync fails the read
+func (tree *PostgresMerkleTree) read(read func(inner merkletree.FullMerkleTree) error) error {
+	if tree.mode != Follower {
+		return read(tree.MerkleTree.FullMerkleTree)
+	}
+	tree.Mutex.Lock()
+	defer tree.Mutex.Unlock()
+
+	if err := tree.follow(); err != nil {
+		return err
+	}
+	return read(tree.MerkleTree.FullMerkleTree)
+}
+
+// Append writes the data unless the tree is a follower
+func (tree *PostgresMerkleTree) Append(data []byte) (index int, hash string, err error) {
+	if tree.mode == Follower {
+		return -1, "", errors.New(readOnly)
+	}
+	return tree.MerkleTree.Append(data)
+}
+
+// RawAppend writes the data without recalculation unless the tree is a follower
+func (tree *PostgresMerkleTree) RawAppend(data []byte) (index int, hash string, err error) {
+	if tree.mode == Follower {
+		return -1, "", errors.New(readOnly)
+	}
+	return tree.MerkleTree.RawAppend(data)
+}
+
+// AppendSalted writes the salted data and its salt unless the tree is a follower
+func (tree *PostgresMerkleTree) AppendSalted(data []byte) (index int, hash string, err error) {
+	if tree.mode == Follower {
+		return -1, "", errors.New(readOnly)
+	}
+	return tree.MerkleTree.AppendSalted(data)
+}
+
+// Add is Append that keeps the tree a merkletree.MerkleTree. Returns index -1 and empty hash if the addition failed
+func (tree *PostgresMerkleTree) Add(data []byte) (index int, hash string) {
+	index, hash, _ = tree.Append(data)
+	return index, hash
+}
+
+// RawAdd is RawAppend that keeps the tree a merkletree.MerkleTree. Returns index -1 and empty hash if the addition failed
+func (tree *PostgresMerkleTree) RawAdd(data []byte) (index int, hash string) {
+	index, hash, _ = tree.RawAppend(data)
+	return index, hash
+}
+
+// Truncate removes the leafs from the given size onwards unless the tree is a follower
+func (tree *PostgresMerkleTree) Truncate(size int) error {
+	if tree.mode == Follower {
+		return errors.New(readOnly)
+	}
+	return tree.MerkleTree.Truncate(size)
+}
+
+// IntermediaryHashesByIndex returns all hashes needed to produce the root from the given index.
+// A follower syncs before reading them
+func (tree *PostgresMerkleTree) IntermediaryHashesByIndex(index int) (intermediaryHashes []string, err error) {
+	err = tree.read(func(inner merkletree.FullMerkleTree) (err error) {
+		intermediaryHashes, err = inner.IntermediaryHashesByIndex(index)
+		return err
+	})
+	return intermediaryHashes, err
+}
+
+// ValidateExistence validates the data at the given index with the intermediary hashes. A follower syncs before validating
+func (tree *PostgresMerkleTree) ValidateExistence(original []byte, index int, intermediaryHashes []string) (exists bool, err error) {
+	err = tree.read(func(inner merkletree.FullMerkleTree) (err error) {
+		exists, err = inner.ValidateExistence(original, index, intermediaryHashes)
+		return err
+	})
+	return exists, err
+}
+
+// HashAt returns the hash at given index. A follower syncs before reading it
+func (tree *PostgresMerkleTree) HashAt(index int) (hash string, err error) {
+	err = tree.read(func(inner merkletree.FullMerkleTree) (err error) {
+		hash, err = inner.HashAt(index)
+		return err
+	})
+	return hash, err
+}
+
+// Root returns the hash of the root of the tree. A follower syncs before reading it and returns the root of the last
+// successful sync if the sync fails, see SyncErr
+func (tree *PostgresMerkleTree) Root() string {
+	if tree.mode != Follower {
+		return tree.MerkleTree.Root()
+	}
+	tree.Mutex.Lock()
+	defer tree.Mutex.Unlock()
+
+	tree.follow() // The error is kept for SyncErr
+	return tree.MerkleTree.FullMerkleTree.Root()
+}
+
+// Length returns the count of the tree leafs. A follower syncs before reading it and returns the length of the last
+// successful sync if the sync fails, see SyncErr
+func (tree *PostgresMerkleTree) Length() int {
+	if tree.mode != Follower {
+		return tree.MerkleTree.Length()
+	}
+	tree.Mutex.Lock()
+	defer tree.Mutex.Unlock()
+
+	tree.follow() // The error is kept for SyncErr
+	return tree.MerkleTree.FullMerkleTree.Length()
+}
+
+// MarshalJSON Creates JSON version of the needed fields of the tree. A follower syncs before creating it
+func (tree *PostgresMerkleTree) MarshalJSON() (json []byte, err error) {
+	err = tree.read(func(inner merkletree.FullMerkleTree) (err error) {
+		json, err = inner.MarshalJSON()
+		return err
+	})
+	return json, err
+}
@@ -0,0 +1,175 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/LimeChain/merkletree/memory"
+	"github.com/LimeChain/merkletree/merkletreetest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestWriterLock(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	db, _, cleanup := testDB(et)
+	defer cleanup()
+	ctx := context.Background()
+
+	opts := Options{DB: db, Tree: "locked", Mode: Writer}
+	writer, err := LoadMerkleTreeContext(ctx, memory.NewMerkleTree(), opts)
+	if err != nil {
+		et.Fatal(err)
+	}
+	addLeafs(et, writer, 2)
+
+	_, err = LoadMerkleTreeContext(ctx, memory.NewMerkleTree(), opts)
+	et.Assert(err != nil && strings.HasPrefix(err.Error(), treeLocked), "Incorrect error was thrown on the second writer", err)
+	other, err := LoadMerkleTreeContext(ctx, memory.NewMerkleTree(), Options{DB: db, Tree: "other", Mode: Writer})
+	et.Assert(err == nil, "The writer of another tree was locked out", err)
+	follower, err := LoadMerkleTreeContext(ctx, memory.NewMerkleTree(), Options{DB: db, Tree: "locked", Mode: Follower})
+	et.Assert(err == nil && follower.Length() == 2, "The follower was locked out", err)
+	shared, err := LoadMerkleTreeContext(ctx, memory.NewMerkleTree(), Options{DB: db, Tree: "locked"})
+	et.Assert(err == nil && shared.Length() == 2, "The shared tree was locked out", err)
+	et.Assert(other.Close() == nil && follower.Close() == nil && shared.Close() == nil, "Error was thrown on closing the trees")
+
+	et.Assert(writer.Close() == nil, "Error was thrown on closing the writer")
+	next, err := LoadMerkleTreeContext(ctx, memory.NewMerkleTree(), opts)
+	et.Assert(err == nil && next.Length() == 2 && next.Root() == writer.Root(), "The writer was not loaded once the lock was released", err)
+	next.Close()
+}
+
+func TestFollower(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	db, connStr, cleanup := testDB(et)
+	defer cleanup()
+	ctx := context.Background()
+
+	writer, err := LoadMerkleTreeContext(ctx, memory.NewMerkleTree(), Options{DB: db, Tree: "followed", Mode: Writer})
+	if err != nil {
+		et.Fatal(err)
+	}
+	defer writer.Close()
+	addLeafs(et, writer, 3)
+
+	follower, err := LoadMerkleTreeContext(ctx, memory.NewMerkleTree(), Options{DB: db, Tree: "followed", Mode: Follower})
+	if err != nil {
+		et.Fatal(err)
+	}
+	et.Assert(follower.Root() == writer.Root() && follower.SyncErr() == nil, "The follower did not load the tree of the writer")
+	_, _, err = follower.Append([]byte("Leaf"))
+	et.Assert(err != nil && err.Error() == readOnly, "Incorrect error was thrown on appending to the follower", err)
+	err = follower.Truncate(0)
+	et.Assert(err != nil && err.Error() == readOnly, "Incorrect error was thrown on truncating the follower", err)
+
+	// Reads apply the new leafs of the writer
+	addLeafs(et, writer, 2)
+	hash, err := follower.HashAt(4)
+	expected, _ := writer.HashAt(4)
+	et.Assert(err == nil && hash == expected && follower.Root() == writer.Root(), "The follower did not apply the new leafs", err)
+
+	// The follower catches up after the writer truncates and replaces the leafs it applied
+	et.Assert(writer.Truncate(2) == nil, "Error was thrown on truncating the writer")
+	writer.Add([]byte("Other Leaf2"))
+	writer.Add([]byte("Other Leaf3"))
+	applied, err := follower.Sync(ctx)
+	et.Assert(err == nil && applied == 4 && follower.Length() == 4, "The follower did not load the truncated tree again", applied, err)
+	et.Assert(follower.Root() == writer.Root(), "The root of the follower was not the root of the writer")
+	for i := 0; i < 4; i++ {
+		proof, _ := follower.IntermediaryHashesByIndex(i)
+		expected, _ := writer.IntermediaryHashesByIndex(i)
+		same := len(proof) == len(expected)
+		for j := 0; same && j < len(proof); j++ {
+			same = proof[j] == expected[j]
+		}
+		et.Assert(same, "The proof of the follower was not the proof of the writer", i)
+	}
+	applied, err = follower.Sync(ctx)
+	et.Assert(err == nil && applied == 0, "Leafs were applied without changes", applied, err)
+
+	// The follower serves the root of its last sync while the database is unreachable
+	followerDB, err := sql.Open("postgres", connStr)
+	if err != nil {
+		et.Fatal(err)
+	}
+	unreachable, err := LoadMerkleTreeContext(ctx, memory.NewMerkleTree(), Options{DB: followerDB, Tree: "followed", Mode: Follower})
+	if err != nil {
+		et.Fatal(err)
+	}
+	synced, length := unreachable.Root(), unreachable.Length()
+	followerDB.Close()
+	et.Assert(writer.Truncate(1) == nil, "Error was thrown on truncating the writer")
+	addLeafs(et, writer, 1)
+	et.Assert(unreachable.Root() == synced, "The follower did not serve the root of its last sync")
+	et.Assert(unreachable.Length() == length, "The follower did not keep the tree of its last sync")
+	et.Assert(unreachable.SyncErr() != nil, "The error of the sync was not kept")
+	_, err = unreachable.HashAt(4)
+	et.Assert(err != nil, "The follower served a leaf it did not sync")
+	_, err = unreachable.Sync(ctx)
+	et.Assert(err != nil, "Error was not thrown on syncing without the database")
+}
+
+func TestFollowerConcurrentReads(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	db, _, cleanup := testDB(et)
+	defer cleanup()
+	ctx := context.Background()
+
+	writer, err := LoadMerkleTreeContext(ctx, memory.NewMerkleTree(), Options{DB: db, Tree: "concurrent", Mode: Writer})
+	if err != nil {
+		et.Fatal(err)
+	}
+	defer writer.Close()
+	addLeafs(et, writer, 4)
+	follower, err := LoadMerkleTreeContext(ctx, memory.NewMerkleTree(), Options{DB: db, Tree: "concurrent", Mode: Follower})
+	if err != nil {
+		et.Fatal(err)
+	}
+
+	// The writer keeps replacing the leafs after the second one, so every sync of the follower loads the tree again.
+	// The first two leafs are always there for the readers
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 20; i++ {
+			writer.Truncate(2)
+			writer.Add([]byte(fmt.Sprintf("Leaf2 %v", i)))
+			writer.Add([]byte(fmt.Sprintf("Leaf3 %v", i)))
+		}
+	}()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for reader := 0; reader < 8; reader++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				proof, err := follower.IntermediaryHashesByIndex(1)
+				if err == nil {
+					_, err = follower.HashAt(0)
+				}
+				if err == nil && len(proof) == 0 {
+					err = errors.New("The proof of the follower was empty")
+				}
+				if err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		et.Assert(false, "The follower was read in the middle of a sync", err)
+	}
+	et.Assert(follower.Root() == writer.Root() && follower.Length() == writer.Length(), "The follower did not catch up with the writer")
+}
@@ -0,0 +1,590 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/LimeChain/merkletree"
+	"github.com/LimeChain/merkletree/memory"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/lib/pq"
+	"math/bits"
+	"sync"
+)
+
+// pageSize is the count of nodes of a level read at once while recalculating the level above it
+const pageSize = 1024
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// node is merkletree.Node read from the database
+type node struct {
+	hash  string
+	index int
+}
+
+func (n *node) Hash() string {
+	return n.hash
+}
+
+func (n *node) Index() int {
+	return n.index
+}
+
+func (n *node) String() string {
+	return n.hash
+}
+
+// NodeTree is implementation of merkletree.FullMerkleTree kept entirely in Postgres. The leafs are in the hashes table
+// and the intermediary nodes in the nodes table, so only the length and the root are held in memory.
+// Proofs are read with a single indexed query and every addition updates the right edge of the tree in the SQL transaction of the leaf.
+// The nodes are the ones of memory.MerkleTree, so both trees have the same roots and proofs. Every new root is recorded
+// in the transaction that changed it
+type NodeTree struct {
+	db      *sql.DB
+	name    string
+	ownsDB  bool
+	length  int
+	root    string
+	rawFrom int   // The first leaf added without recalculation or -1
+	lastErr error // The failure of the database in the last Recalculate
+	roots   rootPolicy
+	Mutex   sync.RWMutex
+}
+
+// levelsOf returns the count of levels of a tree with the given count of leafs
+func levelsOf(size int) int {
+	if size == 0 {
+		return 0
+	}
+	return bits.Len(uint(size-1)) + 1
+}
+
+// nodeHash returns the hex of the stored hash the way memory.MerkleTree shows it
+func nodeHash(hash []byte) string {
+	if hash == nil {
+		return ""
+	}
+	return common.BytesToHash(hash).Hex()
+}
+
+func parentHash(left, right []byte) []byte {
+	return crypto.Keccak256(common.BytesToHash(left).Bytes(), common.BytesToHash(right).Bytes())
+}
+
+// saveNodes stores the nodes above the leafs, replacing the ones stored at the same level and index
+func saveNodes(ctx context.Context, exec execer, tree string, nodes []merkletree.StoredNode) error {
+	levels := make([]int64, 0, len(nodes))
+	indexes := make([]int64, 0, len(nodes))
+	hashes := make([][]byte, 0, len(nodes))
+	for _, n := range nodes {
+		if n.Level == 0 { // The leafs are stored in hashes
+			continue
+		}
+		b, err := hashBytes(n.Hash)
+		if err != nil {
+			return err
+		}
+		levels = append(levels, int64(n.Level))
+		indexes = append(indexes, int64(n.Index))
+		hashes = append(hashes, b)
+	}
+	if len(levels) == 0 {
+		return nil
+	}
+	_, err := exec.ExecContext(ctx, SaveNodesQuery, tree, pq.Array(levels), pq.Array(indexes), pq.Array(hashes))
+	return err
+}
+
+// loadNodes returns the stored hashes of the nodes at the given levels and indexes. The missing nodes are not in the result
+func loadNodes(ctx context.Context, q queryer, tree string, positions [][2]int) (map[[2]int][]byte, error) {
+	levels := make([]int64, len(positions))
+	indexes := make([]int64, len(positions))
+	for i, position := range positions {
+		levels[i] = int64(position[0])
+		indexes[i] = int64(position[1])
+	}
+
+	rows, err := q.QueryContext(ctx, SelectNodesQuery, tree, pq.Array(levels), pq.Array(indexes))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	nodes := make(map[[2]int][]byte, len(positions))
+	for rows.Next() {
+		var level, index int
+		var hash []byte
+		if err := rows.Scan(&level, &index, &hash); err != nil {
+			return nil, err
+		}
+		nodes[[2]int{level, index}] = hash
+	}
+	return nodes, rows.Err()
+}
+
+// loadPage returns count consecutive nodes of the level starting at the given index. Fails if any of them is missing
+func loadPage(ctx context.Context, q queryer, tree string, level int, start int, count int) ([][]byte, error) {
+	var rows *sql.Rows
+	var err error
+	if level == 0 {
+		rows, err = q.QueryContext(ctx, LeavesPageQuery, tree, start, count)
+	} else {
+		rows, err = q.QueryContext(ctx, NodesPageQuery, tree, level, start, count)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hashes := make([][]byte, 0, count)
+	for rows.Next() {
+		var index int
+		var hash []byte
+		if err := rows.Scan(&index, &hash); err != nil {
+			return nil, err
+		}
+		if index != start+len(hashes) {
+			break
+		}
+		hashes = append(hashes, hash)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(hashes) != count {
+		return nil, fmt.Errorf("%v at level %v and index %v", missingNode, level, start+len(hashes))
+	}
+	return hashes, nil
+}
+
+// updateNodes recalculates the nodes on the paths of the leafs from the given index up to the given size, level by level,
+// and returns the root. Only pageSize nodes of a level are read at once. Adding a leaf recalculates just the right edge
+func updateNodes(ctx context.Context, dbTx *sql.Tx, tree string, from int, size int) (root []byte, err error) {
+	levels := levelsOf(size)
+	if levels == 0 {
+		return nil, nil
+	}
+	if levels == 1 {
+		leafs, err := loadPage(ctx, dbTx, tree, 0, 0, 1)
+		if err != nil {
+			return nil, err
+		}
+		return leafs[0], nil
+	}
+
+	for level := 0; level < levels-1; level++ {
+		last := (size - 1) >> uint(level)
+		for start := (from >> uint(level)) &^ 1; start <= last; { // Starting from the left half of the pair
+			count := last - start + 1
+			if count > pageSize {
+				count = pageSize
+			}
+			children, err := loadPage(ctx, dbTx, tree, level, start, count)
+			if err != nil {
+				return nil, err
+			}
+
+			parents := make([]merkletree.StoredNode, 0, (count+1)/2)
+			for i := 0; i < count; i += 2 {
+				right := children[i]
+				if i+1 < count {
+					right = children[i+1]
+				} // Otherwise the last node of the level is hashed with itself
+				root = parentHash(children[i], right)
+				parents = append(parents, merkletree.StoredNode{Level: level + 1, Index: (start + i) / 2, Hash: nodeHash(root)})
+			}
+			if err := saveNodes(ctx, dbTx, tree, parents); err != nil {
+				return nil, err
+			}
+			start += count
+		}
+	}
+	return root, nil
+}
+
+// Add hashes and inserts data as the next leaf and updates the right edge of the tree.
+// Returns index -1 and empty hash if writing to the database failed
+func (tree *NodeTree) Add(data []byte) (index int, hash string) {
+	index, hash, _ = tree.Append(data)
+	return index, hash
+}
+
+// RawAdd hashes and inserts data as the next leaf without calculating the nodes above it.
+// Returns index -1 and empty hash if writing to the database failed
+func (tree *NodeTree) RawAdd(data []byte) (index int, hash string) {
+	index, hash, _ = tree.RawAppend(data)
+	return index, hash
+}
+
+// Append is Add that returns the error of the database. The failed addition is rolled back
+func (tree *NodeTree) Append(data []byte) (index int, hash string, err error) {
+	hash = crypto.Keccak256Hash(data).Hex()
+	index, err = tree.insert(hash, false)
+	if err != nil {
+		return -1, "", err
+	}
+	return index, hash, nil
+}
+
+// RawAppend is RawAdd that returns the error of the database. The failed addition is rolled back
+func (tree *NodeTree) RawAppend(data []byte) (index int, hash string, err error) {
+	hash = crypto.Keccak256Hash(data).Hex()
+	index, err = tree.insert(hash, true)
+	if err != nil {
+		return -1, "", err
+	}
+	return index, hash, nil
+}
+
+// Insert inserts the hash as the next leaf and updates the right edge of the tree.
+// Returns the index it was inserted at or -1 if writing to the database failed
+func (tree *NodeTree) Insert(hash string) (index int) {
+	index, _ = tree.insert(hash, false)
+	return index
+}
+
+// RawInsert inserts the hash as the next leaf without calculating the nodes above it.
+// Returns the index of the leaf and the leaf or -1 and nil if writing to the database failed
+func (tree *NodeTree) RawInsert(hash string) (index int, leaf merkletree.Node) {
+	index, err := tree.insert(hash, true)
+	if err != nil {
+		return -1, nil
+	}
+	return index, &node{hash, index}
+}
+
+func (tree *NodeTree) insert(hash string, raw bool) (index int, err error) {
+	tree.Mutex.Lock()
+	defer tree.Mutex.Unlock()
+
+	ctx := context.Background()
+	index = tree.length
+	dbTx, err := tree.db.BeginTx(ctx, nil)
+	if err != nil {
+		return -1, err
+	}
+	if err = insertLeaf(dbTx.Exec, tree.name, index, hash); err != nil {
+		dbTx.Rollback()
+		return -1, err
+	}
+
+	var root []byte
+	if !raw {
+		from := index
+		if tree.rawFrom >= 0 {
+			from = tree.rawFrom
+		}
+		root, err = updateNodes(ctx, dbTx, tree.name, from, index+1)
+		if err == nil {
+			err = tree.roots.record(ctx, dbTx, tree.name, index+1, nodeHash(root))
+		}
+		if err != nil {
+			dbTx.Rollback()
+			return -1, err
+		}
+	}
+	if err = dbTx.Commit(); err != nil {
+		return -1, err
+	}
+
+	tree.length++
+	if raw {
+		if tree.rawFrom < 0 {
+			tree.rawFrom = index
+		}
+	} else {
+		tree.root = nodeHash(root)
+		tree.rawFrom = -1
+	}
+	return index, nil
+}
+
+// Recalculate is RecalculateContext that keeps the tree a merkletree.MerkleTree. The error of the database is kept for RecalculateErr
+func (tree *NodeTree) Recalculate() (root string) {
+	root, err := tree.RecalculateContext(context.Background())
+	tree.Mutex.Lock()
+	tree.lastErr = err
+	tree.Mutex.Unlock()
+	return root
+}
+
+// RecalculateErr returns the error of the database in the last Recalculate, nil if it succeeded
+func (tree *NodeTree) RecalculateErr() error {
+	tree.Mutex.RLock()
+	defer tree.Mutex.RUnlock()
+
+	return tree.lastErr
+}
+
+// RecalculateContext calculates the nodes above the leafs added with RawAdd and returns the new root.
+// If writing to the database fails, the last calculated root is returned with the error and the recalculation is
+// retried on the next one
+func (tree *NodeTree) RecalculateContext(ctx context.Context) (root string, err error) {
+	tree.Mutex.Lock()
+	defer tree.Mutex.Unlock()
+
+	if tree.rawFrom < 0 {
+		return tree.root, nil
+	}
+	root, err = tree.rebuild(ctx, tree.rawFrom, tree.length)
+	if err != nil {
+		return tree.root, err
+	}
+	tree.root = root
+	tree.rawFrom = -1
+	return root, nil
+}
+
+// rebuild recalculates the nodes of the leafs from the given index up to the given size in a single SQL transaction
+func (tree *NodeTree) rebuild(ctx context.Context, from int, size int) (root string, err error) {
+	dbTx, err := tree.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	b, err := updateNodes(ctx, dbTx, tree.name, from, size)
+	if err == nil {
+		err = tree.roots.record(ctx, dbTx, tree.name, size, nodeHash(b))
+	}
+	if err != nil {
+		dbTx.Rollback()
+		return "", err
+	}
+	return nodeHash(b), dbTx.Commit()
+}
+
+// Truncate removes the leafs from the given size onwards together with the nodes above them and recalculates the right edge
+func (tree *NodeTree) Truncate(size int) error {
+	tree.Mutex.Lock()
+	defer tree.Mutex.Unlock()
+
+	if size < 0 || size > tree.length {
+		return errors.New(invalidSize)
+	}
+
+	ctx := context.Background()
+	dbTx, err := tree.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	root, err := truncateNodes(ctx, dbTx, tree.name, size, tree.rawFrom)
+	if err == nil {
+		err = tree.roots.record(ctx, dbTx, tree.name, size, nodeHash(root))
+	}
+	if err != nil {
+		dbTx.Rollback()
+		return err
+	}
+	if err := dbTx.Commit(); err != nil {
+		return err
+	}
+
+	tree.length = size
+	tree.root = nodeHash(root)
+	tree.rawFrom = -1
+	return nil
+}
+
+func truncateNodes(ctx context.Context, dbTx *sql.Tx, tree string, size int, rawFrom int) (root []byte, err error) {
+	if _, err := dbTx.ExecContext(ctx, TruncateQuery, tree, size); err != nil {
+		return nil, err
+	}
+	if _, err := dbTx.ExecContext(ctx, TruncateSaltsQuery, tree, size); err != nil {
+		return nil, err
+	}
+	if _, err := dbTx.ExecContext(ctx, TruncateRootsQuery, tree, size); err != nil {
+		return nil, err
+	}
+	if _, err := dbTx.ExecContext(ctx, TruncateNodesQuery, tree, levelsOf(size), size); err != nil {
+		return nil, err
+	}
+	from := size - 1
+	if rawFrom >= 0 && rawFrom < from {
+		from = rawFrom
+	}
+	if from < 0 {
+		from = 0
+	}
+	return updateNodes(ctx, dbTx, tree, from, size)
+}
+
+// IntermediaryHashesByIndex returns all hashes needed to produce the root from the given index, read with a single query
+func (tree *NodeTree) IntermediaryHashesByIndex(index int) (intermediaryHashes []string, err error) {
+	tree.Mutex.RLock()
+	defer tree.Mutex.RUnlock()
+
+	if index < 0 || index >= tree.length {
+		return nil, errors.New(outOfBounds)
+	}
+
+	positions := make([][2]int, 0)
+	for level := 0; level < levelsOf(tree.length)-1; level++ {
+		i := index >> uint(level)
+		sibling := i ^ 1
+		if sibling > (tree.length-1)>>uint(level) { // The last node of the level is its own sibling
+			sibling = i
+		}
+		positions = append(positions, [2]int{level, sibling})
+	}
+
+	nodes, err := loadNodes(context.Background(), tree.db, tree.name, positions)
+	if err != nil {
+		return nil, err
+	}
+	intermediaryHashes = make([]string, len(positions))
+	for i, position := range positions {
+		hash, ok := nodes[position]
+		if !ok {
+			return nil, fmt.Errorf("%v at level %v and index %v", missingNode, position[0], position[1])
+		}
+		intermediaryHashes[i] = nodeHash(hash)
+	}
+	return intermediaryHashes, nil
+}
+
+// ValidateExistence checks that the hash of the original data is the leaf at the given index and that the intermediary
+// hashes produce the root of the tree from it
+func (tree *NodeTree) ValidateExistence(original []byte, index int, intermediaryHashes []string) (bool, error) {
+	leafHash, err := tree.HashAt(index)
+	if err != nil {
+		return false, err
+	}
+	if crypto.Keccak256Hash(original).Hex() != leafHash {
+		return false, nil
+	}
+	return memory.Verify(tree.Root(), index, leafHash, intermediaryHashes), nil
+}
+
+// HashAt returns the hash of the leaf at given index
+func (tree *NodeTree) HashAt(index int) (string, error) {
+	if index < 0 || index >= tree.Length() {
+		return "", errors.New(outOfBounds)
+	}
+	position := [2]int{0, index}
+	nodes, err := loadNodes(context.Background(), tree.db, tree.name, [][2]int{position})
+	if err != nil {
+		return "", err
+	}
+	hash, ok := nodes[position]
+	if !ok {
+		return "", fmt.Errorf("%v at level 0 and index %v", missingNode, index)
+	}
+	return nodeHash(hash), nil
+}
+
+// Root returns the hash of the root of the tree
+func (tree *NodeTree) Root() string {
+	tree.Mutex.RLock()
+	defer tree.Mutex.RUnlock()
+	return tree.root
+}
+
+// Length returns the count of the tree leafs
+func (tree *NodeTree) Length() int {
+	tree.Mutex.RLock()
+	defer tree.Mutex.RUnlock()
+	return tree.length
+}
+
+// Name returns the name the tree is stored under
+func (tree *NodeTree) Name() string {
+	return tree.name
+}
+
+// String returns human readable version of the tree
+func (tree *NodeTree) String() string {
+	return fmt.Sprintf("Tree: %v, Length: %v, Root: %v\n", tree.name, tree.Length(), tree.Root())
+}
+
+// MarshalJSON Creates JSON version of the needed fields of the tree
+func (tree *NodeTree) MarshalJSON() ([]byte, error) {
+	res := fmt.Sprintf("{\"root\":\"%v\", \"length\":%v}", tree.Root(), tree.Length())
+	return []byte(res), nil
+}
+
+// Close closes the connection pool if it was opened by LoadNodeTreeContext
+func (tree *NodeTree) Close() error {
+	if !tree.ownsDB {
+		return nil
+	}
+	return tree.db.Close()
+}
+
+// LoadNodeTreeContext connects to the database, migrates it and opens the tree with the name in the options without
+// reading its leafs. If nodes on the right edge are missing, e.g. for trees saved before the nodes were stored,
+// all nodes are calculated once from the leafs
+func LoadNodeTreeContext(ctx context.Context, opts Options) (*NodeTree, error) {
+	name := opts.Tree
+	if name == "" {
+		name = DefaultTree
+	}
+	if !validTreeName(name) {
+		return nil, errors.New(invalidTreeName)
+	}
+
+	db, owned, err := openDB(opts)
+	if err != nil {
+		return nil, fmt.Errorf("Could not connect to the database: %v", err)
+	}
+
+	tree, err := prepareAndOpen(ctx, db, name, newRootPolicy(opts))
+	if err != nil {
+		if owned {
+			db.Close()
+		}
+		return nil, err
+	}
+	tree.ownsDB = owned
+
+	return tree, nil
+}
+
+func prepareAndOpen(ctx context.Context, db *sql.DB, name string, policy rootPolicy) (*NodeTree, error) {
+	if err := prepareDB(ctx, db); err != nil {
+		return nil, err
+	}
+	if _, err := db.ExecContext(ctx, RegisterTreeQuery, name); err != nil {
+		return nil, fmt.Errorf("Could not register the tree in the db: %v", err)
+	}
+	tree, err := openNodeTree(ctx, db, name, policy)
+	if err != nil {
+		return nil, fmt.Errorf("Could not load the stored nodes: %v", err)
+	}
+	return tree, nil
+}
+
+func openNodeTree(ctx context.Context, db *sql.DB, name string, policy rootPolicy) (*NodeTree, error) {
+	tree := &NodeTree{db: db, name: name, rawFrom: -1, roots: policy}
+	if err := db.QueryRowContext(ctx, LengthQuery, name).Scan(&tree.length); err != nil {
+		return nil, err
+	}
+
+	levels := levelsOf(tree.length)
+	rightEdge := make([][2]int, levels)
+	for level := range rightEdge {
+		rightEdge[level] = [2]int{level, (tree.length - 1) >> uint(level)}
+	}
+	nodes, err := loadNodes(ctx, db, name, rightEdge)
+	if err != nil {
+		return nil, err
+	}
+	if len(nodes) == len(rightEdge) {
+		if levels > 0 {
+			tree.root = nodeHash(nodes[rightEdge[levels-1]])
+		}
+		return tree, nil
+	}
+
+	if tree.root, err = tree.rebuild(ctx, 0, tree.length); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
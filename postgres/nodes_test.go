@@ -0,0 +1,103 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"github.com/LimeChain/merkletree/memory"
+	"github.com/LimeChain/merkletree/merkletreetest"
+	"testing"
+)
+
+// assertSameTree checks that the node tree has the length, the root and the proofs of the memory tree
+func assertSameTree(et *merkletreetest.ExtendedTesting, tree *NodeTree, expected *memory.MerkleTree, label string) {
+	et.Assert(tree.Length() == expected.Length(), "The length was not correct", label, tree.Length())
+	et.Assert(tree.Root() == expected.Root(), "The root was not the root of memory.MerkleTree", label, tree.Root())
+	for i := 0; i < expected.Length(); i++ {
+		hashes, err := tree.IntermediaryHashesByIndex(i)
+		want, _ := expected.IntermediaryHashesByIndex(i)
+		same := err == nil && len(hashes) == len(want)
+		for j := 0; same && j < len(want); j++ {
+			same = hashes[j] == want[j]
+		}
+		et.Assert(same, "The proof was not the proof of memory.MerkleTree", label, i, err)
+	}
+}
+
+func TestNodeTree(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	db, _, cleanup := testDB(et)
+	defer cleanup()
+	ctx := context.Background()
+
+	tree, err := LoadNodeTreeContext(ctx, Options{DB: db, Tree: "added"})
+	if err != nil {
+		et.Fatal(err)
+	}
+	expected := memory.NewMerkleTree()
+	for size := 1; size <= 33; size++ {
+		data := []byte(fmt.Sprintf("Leaf%v", size-1))
+		index, hash, err := tree.Append(data)
+		expected.Add(data)
+		et.Assert(err == nil && index == size-1 && hash == expected.Nodes[0][size-1].Hash(), "The leaf was not appended", size, err)
+		assertSameTree(et, tree, expected, fmt.Sprintf("added %v", size))
+	}
+
+	loaded, err := LoadNodeTreeContext(ctx, Options{DB: db, Tree: "added"})
+	et.Assert(err == nil, "Error was thrown on loading the tree", err)
+	assertSameTree(et, loaded, expected, "loaded")
+
+	for size := 32; size >= 0; size-- {
+		et.Assert(tree.Truncate(size) == nil, "Error was thrown on truncate", size)
+		expected.Truncate(size)
+		assertSameTree(et, tree, expected, fmt.Sprintf("truncated %v", size))
+	}
+	et.Assert(tree.Truncate(1) != nil, "Error was not thrown on truncating past the length")
+}
+
+func TestNodeTreeRecalculate(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	db, _, cleanup := testDB(et)
+	defer cleanup()
+	ctx := context.Background()
+
+	for size := 1; size <= 33; size++ {
+		tree, err := LoadNodeTreeContext(ctx, Options{DB: db, Tree: fmt.Sprintf("raw%v", size)})
+		if err != nil {
+			et.Fatal(err)
+		}
+		expected := memory.NewMerkleTree()
+		for i := 0; i < size; i++ {
+			data := []byte(fmt.Sprintf("Leaf%v", i))
+			if i%3 == 0 { // Mixed with additions that calculate the nodes
+				tree.Add(data)
+			} else {
+				tree.RawAdd(data)
+			}
+			expected.Add(data)
+		}
+		root := tree.Recalculate()
+		err = tree.RecalculateErr()
+		et.Assert(err == nil && root == expected.Root(), "The recalculated root was not correct", size, err)
+		assertSameTree(et, tree, expected, fmt.Sprintf("raw %v", size))
+
+		if size%8 == 1 {
+			tree.RawAdd([]byte("Leaf"))
+			expected.Add([]byte("Leaf"))
+			et.Assert(tree.Truncate(size-1) == nil, "Error was thrown on truncating raw additions", size)
+			expected.Truncate(size - 1)
+			assertSameTree(et, tree, expected, fmt.Sprintf("raw truncated %v", size))
+		}
+	}
+
+	tree, _ := LoadNodeTreeContext(ctx, Options{DB: db, Tree: "canceled"})
+	tree.Add([]byte("Leaf0"))
+	root := tree.Root()
+	tree.RawAdd([]byte("Leaf1"))
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	failed, err := tree.RecalculateContext(canceled)
+	et.Assert(err != nil && failed == root, "The last root was not returned with the error", err)
+	et.Assert(tree.Root() == root, "The root changed on failed recalculation")
+	recalculated, err := tree.RecalculateContext(ctx)
+	et.Assert(err == nil && recalculated != root && tree.Root() == recalculated, "The recalculation was not retried", err)
+}
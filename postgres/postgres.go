@@ -1,96 +1,278 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"github.com/LimeChain/merkletree"
+	"github.com/LimeChain/merkletree/persistent"
 	_ "github.com/lib/pq"
-	"sync"
+	"strings"
+	"time"
 )
 
+// Every tree is stored under its name in the tree column and the index of every leaf is kept in leaf_index.
+// A leaf is inserted only right after the leaf before it, so the stored indexes never have gaps
 const (
-	InsertQuery       = "INSERT INTO hashes (hash) VALUES ($1)"
-	SelectQuery       = "SELECT hash FROM hashes ORDER BY id"
-	CreateQuery       = "CREATE TABLE hashes(id SERIAL PRIMARY KEY,hash VARCHAR(66) NOT NULL);"
+	InsertQuery   = "INSERT INTO hashes (tree, leaf_index, hash) SELECT $1::VARCHAR, $2::BIGINT, $3::BYTEA WHERE $2::BIGINT = 0 OR EXISTS (SELECT 1 FROM hashes WHERE tree = $1::VARCHAR AND leaf_index = $2::BIGINT - 1)"
+	SelectQuery   = "SELECT leaf_index, hash FROM hashes WHERE tree = $1 ORDER BY leaf_index"
+	TruncateQuery = "DELETE FROM hashes WHERE tree = $1 AND leaf_index >= $2"
+	// Deprecated: The tables are created and upgraded by Migrate
+	CreateQuery = "CREATE TABLE hashes(id SERIAL PRIMARY KEY,hash VARCHAR(66) NOT NULL);"
+	// Deprecated: The tables are created and upgraded by Migrate
 	CreateIfNotExists = "CREATE TABLE IF NOT EXISTS hashes(id SERIAL PRIMARY KEY,hash VARCHAR(66) NOT NULL);"
 )
 
-type PostgresMerkleTree struct {
-	merkletree.FullMerkleTree
-	db    *sql.DB
-	mutex sync.Mutex
+// The registry of the trees
+const (
+	RegisterTreeQuery = "INSERT INTO trees (name) VALUES ($1) ON CONFLICT DO NOTHING"
+	ListTreesQuery    = "SELECT name FROM trees ORDER BY name"
+	TreeExistsQuery   = "SELECT COUNT(*) FROM trees WHERE name = $1"
+	DeleteTreeQuery   = "DELETE FROM trees WHERE name = $1"
+)
+
+// The intermediary nodes of the trees. Level 0 of the nodes is read from the leafs in hashes.
+// The nodes past the last leaf or above the root are deleted on truncation
+const (
+	SaveNodesQuery     = "INSERT INTO nodes (tree, level, node_index, hash) SELECT $1, level, node_index, hash FROM unnest($2::INTEGER[], $3::BIGINT[], $4::BYTEA[]) AS n(level, node_index, hash) ON CONFLICT (tree, level, node_index) DO UPDATE SET hash = EXCLUDED.hash"
+	SelectNodesQuery   = "SELECT n.level, n.node_index, n.hash FROM nodes n JOIN unnest($2::INTEGER[], $3::BIGINT[]) AS w(level, node_index) ON n.level = w.level AND n.node_index = w.node_index WHERE n.tree = $1 UNION ALL SELECT 0, h.leaf_index, h.hash FROM hashes h JOIN unnest($2::INTEGER[], $3::BIGINT[]) AS w(level, node_index) ON w.level = 0 AND h.leaf_index = w.node_index WHERE h.tree = $1"
+	LeavesPageQuery    = "SELECT leaf_index, hash FROM hashes WHERE tree = $1 AND leaf_index >= $2 ORDER BY leaf_index LIMIT $3"
+	NodesPageQuery     = "SELECT node_index, hash FROM nodes WHERE tree = $1 AND level = $2 AND node_index >= $3 ORDER BY node_index LIMIT $4"
+	LengthQuery        = "SELECT COALESCE(MAX(leaf_index) + 1, 0) FROM hashes WHERE tree = $1"
+	TruncateNodesQuery = "DELETE FROM nodes WHERE tree = $1 AND (level >= $2 OR node_index > ($3::BIGINT - 1) >> level)"
+)
+
+// The history of the roots. Several roots can be recorded at the same size if the tree was truncated, the last one wins
+const (
+	InsertRootQuery = "INSERT INTO roots (tree, size, root, signature) VALUES ($1, $2, $3, $4)"
+	RootAtSizeQuery = "SELECT size, root, created_at, signature FROM roots WHERE tree = $1 AND size = $2 ORDER BY id DESC LIMIT 1"
+	RootAtTimeQuery = "SELECT size, root, created_at, signature FROM roots WHERE tree = $1 AND created_at <= $2 ORDER BY created_at DESC, id DESC LIMIT 1"
+	LatestRootQuery = "SELECT size, root, created_at, signature FROM roots WHERE tree = $1 ORDER BY id DESC LIMIT 1"
+	RootsQuery      = "SELECT DISTINCT ON (size) size, root, created_at, signature FROM roots WHERE tree = $1 AND size <= $2 ORDER BY size, id DESC"
+	// TruncateRootsQuery deletes the roots of the truncated leafs, so the last recorded root is never past the last leaf
+	TruncateRootsQuery = "DELETE FROM roots WHERE tree = $1 AND size > $2"
+)
+
+// The salts of the salted leafs. A salt is saved before its leaf, so a salt past the last leaf is replaced by the next one
+const (
+	SaveSaltQuery      = "INSERT INTO salts (tree, leaf_index, salt) VALUES ($1, $2, $3) ON CONFLICT (tree, leaf_index) DO UPDATE SET salt = EXCLUDED.salt"
+	SelectSaltsQuery   = "SELECT leaf_index, salt FROM salts WHERE tree = $1"
+	TruncateSaltsQuery = "DELETE FROM salts WHERE tree = $1 AND leaf_index >= $2"
+)
+
+// DefaultTree is the name of the tree when no name is given. The rows stored before trees had names belong to it
+const DefaultTree = "default"
+
+const (
+	notTransactional  = "The underlying tree does not support transactions"
+	transactionClosed = "Transaction already committed or rolled back"
+	indexNotNext      = "Incorrect index - The leaf index does not follow the last stored leaf"
+	invalidTreeName   = "Incorrect tree - The name must be between 1 and 255 characters"
+	treeExists        = "Incorrect tree - The tree already exists"
+	treeNotFound      = "Incorrect tree - The tree does not exist"
+	invalidHash       = "Incorrect hash - Expected hex string"
+	missingNode       = "Incorrect store - Missing node"
+	outOfBounds       = "Incorrect index - Index out of bounds"
+	invalidSize       = "Incorrect size - Size out of bounds"
+	noRoot            = "Incorrect root - No root was recorded"
+	invalidSignature  = "Incorrect root - Invalid signature of the root at size"
+	treeLocked        = "Incorrect mode - Another process is the writer of the tree"
+	readOnly          = "Incorrect mode - The tree is a follower and can not be written"
+	noTruncate        = "Incorrect tree - The tree can not be truncated"
+)
+
+// Options configure the database of LoadMerkleTreeContext. An existing DB is used as it is, otherwise a connection pool is
+// opened with ConnStr. The pool settings are applied when they are not zero.
+// Every new root is recorded in the roots table, or only the roots at the sizes that are multiples of RootCheckpoint if it is set.
+// On load the stored leafs are verified against the recorded roots and VerifyRoot checks their signatures if it is set.
+// Verifying a root recorded before the length of the tree, as with RootCheckpoint or raw additions, needs a
+// merkletree.PrefixMerkleTree such as memory.MerkleTree.
+// Mode decides how the tree shares the database with other processes, see Mode
+type Options struct {
+	DB              *sql.DB
+	ConnStr         string
+	Tree            string // The name of the tree, DefaultTree if empty
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	RootCheckpoint  int
+	SignRoot        func(size int, root string) (signature []byte, err error) // Signs the recorded roots if set
+	VerifyRoot      func(size int, root string, signature []byte) bool
+	Mode            Mode
+	SyncInterval    time.Duration // The least time between the syncs of a follower, every read syncs if zero
 }
 
-func (tree *PostgresMerkleTree) Add(data []byte) (index int, hash string) {
-	tree.mutex.Lock()
-	index, hash = tree.FullMerkleTree.Add(data)
-	tree.addHashToDB(hash)
-	tree.mutex.Unlock()
-	return index, hash
+// PostgresMerkleTree is a persistent.MerkleTree saving its leafs in the hashes table of a Postgres database
+type PostgresMerkleTree struct {
+	*persistent.MerkleTree
+	db           *sql.DB
+	name         string
+	ownsDB       bool
+	mode         Mode
+	lockConn     *sql.Conn // The connection holding the lock of the writer
+	syncInterval time.Duration
+	lastSync     time.Time
+	syncErr      error // The error of the last sync, nil if it succeeded
 }
 
-func (tree *PostgresMerkleTree) RawAdd(data []byte) (index int, hash string) {
-	tree.mutex.Lock()
-	index, hash = tree.FullMerkleTree.RawAdd(data)
-	tree.addHashToDB(hash)
-	tree.mutex.Unlock()
-	return index, hash
+// Name returns the name the tree is stored under
+func (tree *PostgresMerkleTree) Name() string {
+	return tree.name
 }
 
-func (tree *PostgresMerkleTree) addHashToDB(hash string) {
-	_, err := tree.db.Exec(InsertQuery, hash)
-	if err != nil {
-		fmt.Println(err.Error())
+// Close releases the lock of the writer and closes the connection pool if it was opened by LoadMerkleTreeContext
+func (tree *PostgresMerkleTree) Close() error {
+	if err := tree.unlock(); err != nil {
+		return err
+	}
+	if !tree.ownsDB {
+		return nil
 	}
+	return tree.db.Close()
 }
 
-func connectToDb(connStr string) *sql.DB {
-	db, err := sql.Open("postgres", connStr)
-	if err != nil {
-		panic("Could not connect to the database.\n Original error: " + err.Error())
+func openDB(opts Options) (db *sql.DB, owned bool, err error) {
+	db = opts.DB
+	if db == nil {
+		db, err = sql.Open("postgres", opts.ConnStr)
+		if err != nil {
+			return nil, false, err
+		}
+		owned = true
 	}
-	return db
-}
 
-func createHashesTable(db *sql.DB) {
-	_, err := db.Exec(CreateIfNotExists)
-	if err != nil {
-		panic("Could not create the table in the db.\n Original error: " + err.Error())
+	if opts.MaxOpenConns != 0 {
+		db.SetMaxOpenConns(opts.MaxOpenConns)
+	}
+	if opts.MaxIdleConns != 0 {
+		db.SetMaxIdleConns(opts.MaxIdleConns)
 	}
+	if opts.ConnMaxLifetime != 0 {
+		db.SetConnMaxLifetime(opts.ConnMaxLifetime)
+	}
+	return db, owned, nil
 }
 
-func getAndInsertStoredHashes(db *sql.DB, tree merkletree.InternalMerkleTree) {
-	rows, err := db.Query(SelectQuery)
+// LoadMerkleTreeContext connects to the database, verifies the connection, creates the tables if needed
+// and inserts the stored hashes of the tree with the name in the options in the tree. The initial load is stopped when
+// the context is done, leaving the tree partially loaded. The tree is expected to be empty.
+// Fails with *IntegrityError if the loaded tree does not have the last recorded root
+func LoadMerkleTreeContext(ctx context.Context, tree merkletree.FullMerkleTree, opts Options) (*PostgresMerkleTree, error) {
+	name := opts.Tree
+	if name == "" {
+		name = DefaultTree
+	}
+	if !validTreeName(name) {
+		return nil, errors.New(invalidTreeName)
+	}
+
+	db, owned, err := openDB(opts)
 	if err != nil {
-		panic("Could not query the stored hashes.\n Original error: " + err.Error())
+		return nil, fmt.Errorf("Could not connect to the database: %v", err)
 	}
 
-	for rows.Next() {
-		var hash string
-		err = rows.Scan(&hash)
-		if err != nil {
-			panic("Could not scan the stored hashes.\n Original error: " + err.Error())
+	postgresTree, err := prepareAndLoad(ctx, db, name, tree, opts)
+	if err != nil {
+		if owned {
+			db.Close()
 		}
-		tree.RawInsert(hash)
+		return nil, err
 	}
+	postgresTree.ownsDB = owned
 
-	tree.Recalculate()
+	return postgresTree, nil
 }
 
-// LoadMerkleTree takes an implementation of Merkle tree and postgre connection string
-// Augments the tree with db saving
-// returns a pointer to an initialized PostgresMerkleTree
-func LoadMerkleTree(tree merkletree.FullMerkleTree, connStr string) *PostgresMerkleTree {
+func prepareAndLoad(ctx context.Context, db *sql.DB, name string, tree merkletree.FullMerkleTree, opts Options) (*PostgresMerkleTree, error) {
+	if err := prepareDB(ctx, db); err != nil {
+		return nil, err
+	}
+	if _, err := db.ExecContext(ctx, RegisterTreeQuery, name); err != nil {
+		return nil, fmt.Errorf("Could not register the tree in the db: %v", err)
+	}
+	return loadTree(ctx, db, name, tree, opts)
+}
 
-	db := connectToDb(connStr)
+// prepareDB verifies the connection and migrates the schema to the latest version
+func prepareDB(ctx context.Context, db *sql.DB) error {
+	if err := db.PingContext(ctx); err != nil {
+		return fmt.Errorf("Could not connect to the database: %v", err)
+	}
+	if err := Migrate(ctx, db); err != nil {
+		return fmt.Errorf("Could not migrate the database: %v", err)
+	}
+	return nil
+}
 
-	createHashesTable(db)
+// loadTree loads the tree in the mode of the options. The writer takes its lock before loading, so it loads the last leafs
+func loadTree(ctx context.Context, db *sql.DB, name string, tree merkletree.FullMerkleTree, opts Options) (*PostgresMerkleTree, error) {
+	postgresTree := &PostgresMerkleTree{db: db, name: name, mode: opts.Mode, syncInterval: opts.SyncInterval}
+	if opts.Mode == Writer {
+		if err := postgresTree.lock(ctx); err != nil {
+			return nil, err
+		}
+	}
 
-	getAndInsertStoredHashes(db, tree)
+	store := NewNamedStore(db, name)
+	store.roots = newRootPolicy(opts)
+	persistentTree, err := persistent.LoadContext(ctx, tree, store)
+	if err == nil {
+		err = verifyRoots(ctx, db, name, tree, opts.VerifyRoot)
+	} else {
+		err = fmt.Errorf("Could not load the stored hashes: %v", err)
+	}
+	if err != nil {
+		postgresTree.unlock()
+		return nil, err
+	}
+
+	postgresTree.MerkleTree = persistentTree
+	postgresTree.lastSync = time.Now()
+	return postgresTree, nil
+}
 
-	postgresMemoryTree := PostgresMerkleTree{}
-	postgresMemoryTree.db = db
-	postgresMemoryTree.FullMerkleTree = tree
+func validTreeName(name string) bool {
+	return len(name) > 0 && len(name) <= 255
+}
 
-	return &postgresMemoryTree
+// insertLeaf inserts the hash as the leaf at the given index. Fails if the index is taken or the leaf before it is missing
+func insertLeaf(exec func(query string, args ...interface{}) (sql.Result, error), tree string, index int, hash string) error {
+	b, err := hashBytes(hash)
+	if err != nil {
+		return err
+	}
+	res, err := exec(InsertQuery, tree, index, b)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected != 1 {
+		return fmt.Errorf("%v: %v", indexNotNext, index)
+	}
+	return nil
+}
+
+// hashBytes decodes the hex hash, with or without 0x prefix, to the bytes it is stored as
+func hashBytes(hash string) ([]byte, error) {
+	b, err := hex.DecodeString(strings.TrimPrefix(hash, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", invalidHash, hash)
+	}
+	return b, nil
+}
+
+// LoadMerkleTree takes an implementation of Merkle tree and postgre connection string
+// Augments the tree with db saving
+// returns a pointer to an initialized PostgresMerkleTree. Panics if the tree can not be loaded, use LoadMerkleTreeContext to handle the error
+func LoadMerkleTree(tree merkletree.FullMerkleTree, connStr string) *PostgresMerkleTree {
+	postgresTree, err := LoadMerkleTreeContext(context.Background(), tree, Options{ConnStr: connStr})
+	if err != nil {
+		panic(err.Error())
+	}
+	return postgresTree
 }
@@ -1,8 +1,16 @@
 package postgres_test
 
 import (
+	"context"
+	"database/sql"
+	"github.com/LimeChain/merkletree"
 	"github.com/LimeChain/merkletree/memory"
 	"github.com/LimeChain/merkletree/postgres"
+	"github.com/LimeChain/merkletree/restapi/baseapi"
+	"github.com/go-chi/chi"
+	"log"
+	"net/http"
+	"time"
 )
 
 func Example() {
@@ -11,3 +19,155 @@ func Example() {
 	data := "Merkle Trees Rock"
 	tree.Add([]byte(data))
 }
+
+func ExamplePostgresMerkleTree_Begin() {
+	connStr := "user=merkle dbname=merrymerkle port=54321 sslmode=disable"
+	tree := postgres.LoadMerkleTree(memory.NewMerkleTree(), connStr)
+	tx, _ := tree.Begin()
+	tx.Add([]byte("Merkle Trees"))
+	tx.Add([]byte("Rock"))
+	tx.Commit()
+}
+
+func ExamplePostgresMerkleTree_Append() {
+	connStr := "user=merkle dbname=merrymerkle port=54321 sslmode=disable"
+	tree := postgres.LoadMerkleTree(memory.NewMerkleTree(), connStr)
+	_, _, err := tree.Append([]byte("Merkle Trees Rock"))
+	if err != nil {
+		log.Println(err) // The leaf was neither saved nor added to the tree
+	}
+}
+
+func ExampleLoadMerkleTreeContext() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	opts := postgres.Options{
+		ConnStr:      "user=merkle dbname=merrymerkle port=54321 sslmode=disable",
+		MaxOpenConns: 10,
+	}
+	tree, err := postgres.LoadMerkleTreeContext(ctx, memory.NewMerkleTree(), opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer tree.Close()
+	tree.Add([]byte("Merkle Trees Rock"))
+}
+
+func ExampleRegistry() {
+	newTree := func() merkletree.FullMerkleTree {
+		return memory.NewMerkleTree()
+	}
+	opts := postgres.Options{ConnStr: "user=merkle dbname=merrymerkle port=54321 sslmode=disable"}
+	registry, err := postgres.NewRegistry(context.Background(), newTree, opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer registry.Close()
+
+	router := chi.NewRouter()
+	router.Route("/v1", func(r chi.Router) {
+		treeRouter := chi.NewRouter()
+		treeRouter = baseapi.MerkleTrees(treeRouter, registry)
+		r.Mount("/api/merkletree", treeRouter)
+	})
+	log.Fatal(http.ListenAndServe(":8080", router))
+}
+
+func ExampleMigrate() {
+	db, err := sql.Open("postgres", "user=merkle dbname=merrymerkle port=54321 sslmode=disable")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	// Upgrades the schema ahead of a deployment. LoadMerkleTreeContext and NewRegistry migrate on their own as well
+	if err := postgres.Migrate(context.Background(), db); err != nil {
+		log.Fatal(err) // e.g. the database was migrated by a newer release
+	}
+}
+
+func ExampleLoadNodeTreeContext() {
+	opts := postgres.Options{ConnStr: "user=merkle dbname=merrymerkle port=54321 sslmode=disable"}
+	tree, err := postgres.LoadNodeTreeContext(context.Background(), opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer tree.Close()
+
+	index, _ := tree.Add([]byte("Merkle Trees Rock")) // Only the right edge of the tree is written
+	proof, _ := tree.IntermediaryHashesByIndex(index) // Read with a single query, no leafs are held in memory
+	log.Println(tree.Root(), proof)
+}
+
+func ExamplePostgresMerkleTree_RootAtTime() {
+	opts := postgres.Options{
+		ConnStr:        "user=merkle dbname=merrymerkle port=54321 sslmode=disable",
+		RootCheckpoint: 100, // Records the roots at sizes 100, 200, 300...
+	}
+	tree, err := postgres.LoadMerkleTreeContext(context.Background(), memory.NewMerkleTree(), opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer tree.Close()
+
+	yesterday := time.Now().AddDate(0, 0, -1)
+	record, err := tree.RootAtTime(time.Date(yesterday.Year(), yesterday.Month(), yesterday.Day(), 14, 0, 0, 0, time.Local))
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Println(record.Size, record.Root)
+
+	router := chi.NewRouter()
+	router.Route("/v1", func(r chi.Router) {
+		treeRouter := chi.NewRouter()
+		treeRouter = baseapi.MerkleTreeRoots(treeRouter, tree)
+		r.Mount("/api/merkletree", treeRouter)
+	})
+	log.Fatal(http.ListenAndServe(":8080", router))
+}
+
+func ExampleVerify() {
+	opts := postgres.Options{ConnStr: "user=merkle dbname=merrymerkle port=54321 sslmode=disable"}
+	for range time.Tick(time.Hour) {
+		err := postgres.Verify(context.Background(), memory.NewMerkleTree(), opts)
+		if integrityErr, ok := err.(*postgres.IntegrityError); ok {
+			log.Fatalf("The hashes were changed from leaf %v to %v: %v", integrityErr.Index, integrityErr.Size-1, err)
+		}
+		if err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+func ExampleMode() {
+	connStr := "user=merkle dbname=merrymerkle port=54321 sslmode=disable"
+
+	// The single instance adding leafs. A second writer fails to load while this one is running
+	writer, err := postgres.LoadMerkleTreeContext(context.Background(), memory.NewMerkleTree(), postgres.Options{ConnStr: connStr, Mode: postgres.Writer})
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer writer.Close()
+
+	// Any number of instances serving proofs, applying the new leafs of the writer at most once a second
+	follower, err := postgres.LoadMerkleTreeContext(context.Background(), memory.NewMerkleTree(), postgres.Options{
+		ConnStr:      connStr,
+		Mode:         postgres.Follower,
+		SyncInterval: time.Second,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer follower.Close()
+
+	index, _ := writer.Add([]byte("Merkle Trees Rock"))
+	follower.Sync(context.Background())
+	proof, _ := follower.IntermediaryHashesByIndex(index)
+	log.Println(follower.Root() == writer.Root(), proof)
+
+	// The follower keeps serving the root of its last sync while the database is unreachable
+	if err := follower.SyncErr(); err != nil {
+		log.Println("The follower is behind the writer:", err)
+	}
+}
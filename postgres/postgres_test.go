@@ -0,0 +1,152 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/LimeChain/merkletree/memory"
+	"github.com/LimeChain/merkletree/merkletreetest"
+	"github.com/ethereum/go-ethereum/common"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testDB opens the database of the PG environment variable, e.g. PG="user=merkle dbname=merkletest sslmode=disable",
+// in a new schema, so every test starts from an empty database. Returns the pool, the connection string of the schema and
+// the cleanup that drops the schema. The test is skipped if PG is not set
+func testDB(et *merkletreetest.ExtendedTesting) (db *sql.DB, connStr string, cleanup func()) {
+	dsn := os.Getenv("PG")
+	if dsn == "" {
+		et.Skip("PG is not set")
+	}
+
+	admin, err := sql.Open("postgres", dsn)
+	if err != nil {
+		et.Fatal(err)
+	}
+	schema := "merkletree_test_" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	if _, err := admin.Exec("CREATE SCHEMA " + schema); err != nil {
+		admin.Close()
+		et.Fatal(err)
+	}
+
+	connStr = dsn + " search_path=" + schema
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		separator := "?"
+		if strings.Contains(dsn, "?") {
+			separator = "&"
+		}
+		connStr = dsn + separator + "search_path=" + schema
+	}
+	db, err = sql.Open("postgres", connStr)
+	if err != nil {
+		admin.Close()
+		et.Fatal(err)
+	}
+
+	return db, connStr, func() {
+		db.Close()
+		admin.Exec("DROP SCHEMA " + schema + " CASCADE")
+		admin.Close()
+	}
+}
+
+// addLeafs adds count leafs to the tree, numbered from the current length of the tree
+func addLeafs(et *merkletreetest.ExtendedTesting, tree *PostgresMerkleTree, count int) {
+	for i := 0; i < count; i++ {
+		_, _, err := tree.Append([]byte(fmt.Sprintf("Leaf%v", tree.Length())))
+		if err != nil {
+			et.Fatal(err)
+		}
+	}
+}
+
+func TestLoadMerkleTreeContext(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	db, connStr, cleanup := testDB(et)
+	defer cleanup()
+	ctx := context.Background()
+
+	tree, err := LoadMerkleTreeContext(ctx, memory.NewMerkleTree(), Options{DB: db})
+	if err != nil {
+		et.Fatal(err)
+	}
+	et.Assert(tree.Length() == 0 && tree.Name() == DefaultTree, "The tree of the empty database was not the empty default tree")
+	addLeafs(et, tree, 5)
+	root := tree.Root()
+	et.Assert(tree.Close() == nil, "Error was thrown on closing the tree")
+	et.Assert(db.Ping() == nil, "Closing the tree closed the pool it did not open")
+
+	loaded, err := LoadMerkleTreeContext(ctx, memory.NewMerkleTree(), Options{ConnStr: connStr, MaxOpenConns: 2, MaxIdleConns: 1, ConnMaxLifetime: time.Minute})
+	et.Assert(err == nil, "Error was thrown on loading with connection string", err)
+	et.Assert(loaded.Length() == 5 && loaded.Root() == root, "The loaded tree was not the saved tree")
+	et.Assert(loaded.db.Stats().MaxOpenConnections == 2, "The pool settings were not applied")
+	et.Assert(loaded.Close() == nil, "Error was thrown on closing the tree")
+	et.Assert(loaded.db.Ping() != nil, "Closing the tree did not close the pool it opened")
+
+	named, err := LoadMerkleTreeContext(ctx, memory.NewMerkleTree(), Options{DB: db, Tree: "named"})
+	et.Assert(err == nil && named.Length() == 0, "The tree of another name was not empty")
+
+	_, err = LoadMerkleTreeContext(ctx, memory.NewMerkleTree(), Options{DB: db, Tree: strings.Repeat("a", 256)})
+	et.Assert(err != nil && err.Error() == invalidTreeName, "Incorrect error was thrown on too long name")
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = LoadMerkleTreeContext(canceled, memory.NewMerkleTree(), Options{DB: db})
+	et.Assert(err != nil, "Error was not thrown on canceled context")
+
+	// The deadline stops the load of a longer tree as well
+	addLeafs(et, named, 200)
+	timeout, cancel := context.WithTimeout(ctx, time.Microsecond)
+	defer cancel()
+	time.Sleep(time.Millisecond)
+	_, err = LoadMerkleTreeContext(timeout, memory.NewMerkleTree(), Options{DB: db, Tree: "named"})
+	et.Assert(err != nil, "Error was not thrown on expired context")
+
+	_, err = LoadMerkleTreeContext(ctx, memory.NewMerkleTree(), Options{ConnStr: "host=127.0.0.1 port=1 sslmode=disable connect_timeout=1"})
+	et.Assert(err != nil && strings.HasPrefix(err.Error(), "Could not connect to the database"), "Incorrect error was thrown on unreachable database", err)
+}
+
+func TestLoadIndexes(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	db, _, cleanup := testDB(et)
+	defer cleanup()
+	ctx := context.Background()
+
+	tree, err := LoadMerkleTreeContext(ctx, memory.NewMerkleTree(), Options{DB: db, Tree: "indexes"})
+	if err != nil {
+		et.Fatal(err)
+	}
+	addLeafs(et, tree, 4)
+
+	store := NewNamedStore(db, "indexes")
+	err = store.AppendLeaves(6, []string{common.Hash{1}.Hex()})
+	et.Assert(err != nil && strings.HasPrefix(err.Error(), indexNotNext), "Incorrect error was thrown on leaf after a gap", err)
+	err = store.AppendLeaves(2, []string{common.Hash{1}.Hex()})
+	et.Assert(err != nil, "Error was not thrown on taken index")
+	err = store.AppendLeaves(4, []string{common.Hash{1}.Hex(), common.Hash{2}.Hex(), "0xzz"})
+	et.Assert(err != nil && strings.HasPrefix(err.Error(), invalidHash), "Incorrect error was thrown on invalid hash")
+	leafs, _ := store.LoadLeaves()
+	et.Assert(len(leafs) == 4, "The leafs of the failed append were stored", len(leafs))
+
+	_, err = db.Exec("DELETE FROM hashes WHERE tree = $1 AND leaf_index = 1", "indexes")
+	if err != nil {
+		et.Fatal(err)
+	}
+	_, err = LoadMerkleTreeContext(ctx, memory.NewMerkleTree(), Options{DB: db, Tree: "indexes"})
+	et.Assert(err != nil && strings.HasSuffix(err.Error(), "Missing leaf 1"), "Incorrect error was thrown on gap", err)
+
+	// The unique constraint keeps duplicates out of migrated databases, so it is dropped to store one
+	_, err = db.Exec("ALTER TABLE hashes DROP CONSTRAINT hashes_tree_leaf_index_key")
+	if err == nil {
+		_, err = db.Exec("INSERT INTO hashes (tree, leaf_index, hash) SELECT tree, 1, hash FROM hashes WHERE tree = $1 AND leaf_index IN (0, 2)", "indexes")
+	}
+	if err != nil {
+		et.Fatal(err)
+	}
+	_, err = LoadMerkleTreeContext(ctx, memory.NewMerkleTree(), Options{DB: db, Tree: "indexes"})
+	et.Assert(err != nil && strings.HasSuffix(err.Error(), "Duplicate leaf 1"), "Incorrect error was thrown on duplicate", err)
+}
@@ -0,0 +1,157 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/LimeChain/merkletree"
+	"sync"
+)
+
+// Registry is implementation of merkletree.TreeRegistry keeping many named trees in one database.
+// The trees are loaded on first use with the trees returned by NewTree and share the connection pool of the registry
+type Registry struct {
+	NewTree func() merkletree.FullMerkleTree
+	db      *sql.DB
+	ownsDB  bool
+	opts    Options
+	trees   map[string]*PostgresMerkleTree
+	mutex   sync.Mutex
+}
+
+// Trees returns the names of all trees in the database
+func (registry *Registry) Trees() ([]string, error) {
+	rows, err := registry.db.Query(ListTreesQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := make([]string, 0)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+// Create registers new empty tree with the given name and returns it
+func (registry *Registry) Create(name string) (merkletree.ExternalMerkleTree, error) {
+	tree, err := registry.CreateContext(context.Background(), name)
+	if err != nil {
+		return nil, err // Not the nil *PostgresMerkleTree, which is a non-nil merkletree.ExternalMerkleTree
+	}
+	return tree, nil
+}
+
+// CreateContext is Create with context. If the new tree fails to load, e.g. because another writer holds its lock,
+// it is registered no more
+func (registry *Registry) CreateContext(ctx context.Context, name string) (*PostgresMerkleTree, error) {
+	if !validTreeName(name) {
+		return nil, errors.New(invalidTreeName)
+	}
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	res, err := registry.db.ExecContext(ctx, RegisterTreeQuery, name)
+	if err != nil {
+		return nil, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if affected != 1 {
+		return nil, fmt.Errorf("%v: %v", treeExists, name)
+	}
+
+	tree, err := registry.load(ctx, name)
+	if err != nil {
+		// The context may be done, so the row is deleted without it
+		if _, deleteErr := registry.db.ExecContext(context.Background(), DeleteTreeQuery, name); deleteErr != nil {
+			return nil, fmt.Errorf("%v. Unregistering the tree failed: %v", err, deleteErr)
+		}
+		return nil, err
+	}
+	return tree, nil
+}
+
+// Tree returns the tree with the given name, loading it from the database on first use
+func (registry *Registry) Tree(name string) (merkletree.ExternalMerkleTree, error) {
+	tree, err := registry.TreeContext(context.Background(), name)
+	if err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// TreeContext is Tree that stops the initial load of the tree when the context is done
+func (registry *Registry) TreeContext(ctx context.Context, name string) (*PostgresMerkleTree, error) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	if tree, ok := registry.trees[name]; ok {
+		return tree, nil
+	}
+
+	var count int
+	if err := registry.db.QueryRowContext(ctx, TreeExistsQuery, name).Scan(&count); err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, fmt.Errorf("%v: %v", treeNotFound, name)
+	}
+	return registry.load(ctx, name)
+}
+
+func (registry *Registry) load(ctx context.Context, name string) (*PostgresMerkleTree, error) {
+	tree, err := loadTree(ctx, registry.db, name, registry.NewTree(), registry.opts)
+	if err != nil {
+		return nil, err
+	}
+	registry.trees[name] = tree
+	return tree, nil
+}
+
+// Close releases the locks of the loaded trees and closes the connection pool if it was opened by NewRegistry
+func (registry *Registry) Close() error {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	for _, tree := range registry.trees {
+		if err := tree.unlock(); err != nil {
+			return err
+		}
+	}
+	if !registry.ownsDB {
+		return nil
+	}
+	return registry.db.Close()
+}
+
+// NewRegistry connects to the database, verifies the connection and creates the tables if needed.
+// The name of the tree in the options is ignored, the options of the roots and the mode apply to all trees
+func NewRegistry(ctx context.Context, newTree func() merkletree.FullMerkleTree, opts Options) (*Registry, error) {
+	db, owned, err := openDB(opts)
+	if err != nil {
+		return nil, fmt.Errorf("Could not connect to the database: %v", err)
+	}
+	if err := prepareDB(ctx, db); err != nil {
+		if owned {
+			db.Close()
+		}
+		return nil, err
+	}
+
+	return &Registry{
+		NewTree: newTree,
+		db:      db,
+		ownsDB:  owned,
+		opts:    opts,
+		trees:   make(map[string]*PostgresMerkleTree),
+	}, nil
+}
@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"context"
+	"github.com/LimeChain/merkletree"
+	"github.com/LimeChain/merkletree/memory"
+	"github.com/LimeChain/merkletree/merkletreetest"
+	"strings"
+	"testing"
+)
+
+func newMemoryTree() merkletree.FullMerkleTree {
+	return memory.NewMerkleTree()
+}
+
+func TestRegistry(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	db, _, cleanup := testDB(et)
+	defer cleanup()
+	ctx := context.Background()
+
+	registry, err := NewRegistry(ctx, newMemoryTree, Options{DB: db})
+	if err != nil {
+		et.Fatal(err)
+	}
+	names, err := registry.Trees()
+	et.Assert(err == nil && len(names) == 0, "The registry of the empty database had trees", names)
+
+	a, err := registry.CreateContext(ctx, "a")
+	et.Assert(err == nil && a.Length() == 0 && a.Name() == "a", "The tree was not created")
+	_, err = registry.Create("b")
+	et.Assert(err == nil, "Error was thrown on creating second tree")
+	existing, err := registry.Create("a")
+	et.Assert(err != nil && strings.HasPrefix(err.Error(), treeExists), "Incorrect error was thrown on existing tree", err)
+	et.Assert(existing == nil, "The tree of the failed creation was not nil")
+	_, err = registry.Create("")
+	et.Assert(err != nil && err.Error() == invalidTreeName, "Incorrect error was thrown on empty name")
+
+	names, _ = registry.Trees()
+	et.Assert(len(names) == 2 && names[0] == "a" && names[1] == "b", "The trees were not listed by name", names)
+
+	addLeafs(et, a, 3)
+	same, err := registry.TreeContext(ctx, "a")
+	et.Assert(err == nil && same == a, "The loaded tree was not returned")
+	b, _ := registry.TreeContext(ctx, "b")
+	et.Assert(b.Length() == 0, "The leafs were added to another tree")
+	missing, err := registry.Tree("c")
+	et.Assert(err != nil && strings.HasPrefix(err.Error(), treeNotFound), "Incorrect error was thrown on missing tree", err)
+	et.Assert(missing == nil, "The missing tree was not nil")
+	et.Assert(registry.Close() == nil, "Error was thrown on closing the registry")
+
+	restarted, _ := NewRegistry(ctx, newMemoryTree, Options{DB: db})
+	loaded, err := restarted.TreeContext(ctx, "a")
+	et.Assert(err == nil && loaded.Length() == 3 && loaded.Root() == a.Root(), "The tree was not loaded after restart")
+	restarted.Close()
+
+	// A tree whose writer lock is held elsewhere fails to load and is not left registered
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		et.Fatal(err)
+	}
+	defer conn.Close()
+	var locked bool
+	if err := conn.QueryRowContext(ctx, LockTreeQuery, treeLockClass, "locked").Scan(&locked); err != nil || !locked {
+		et.Fatal("The lock of the tree was not taken", err)
+	}
+
+	writers, _ := NewRegistry(ctx, newMemoryTree, Options{DB: db, Mode: Writer})
+	defer writers.Close()
+	_, err = writers.CreateContext(ctx, "locked")
+	et.Assert(err != nil && strings.HasPrefix(err.Error(), treeLocked), "Incorrect error was thrown on locked tree", err)
+	names, _ = writers.Trees()
+	et.Assert(len(names) == 2, "The tree that failed to load was left registered", names)
+
+	conn.ExecContext(ctx, UnlockTreeQuery, treeLockClass, "locked")
+	_, err = writers.CreateContext(ctx, "locked")
+	et.Assert(err == nil, "The tree was not created once the lock was released", err)
+}
+
+func TestRegistryFailureIsNil(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	var registry merkletree.TreeRegistry = &Registry{}
+
+	tree, err := registry.Create("")
+	et.Assert(err != nil && tree == nil, "The tree of the failed creation was not nil", err)
+}
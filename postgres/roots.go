@@ -0,0 +1,78 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/LimeChain/merkletree"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"time"
+)
+
+// rootPolicy decides which roots are recorded and signs them
+type rootPolicy struct {
+	checkpoint int
+	sign       func(size int, root string) ([]byte, error)
+	verify     func(size int, root string, signature []byte) bool
+}
+
+func newRootPolicy(opts Options) rootPolicy {
+	return rootPolicy{opts.RootCheckpoint, opts.SignRoot, opts.VerifyRoot}
+}
+
+// record inserts the root at the given size unless the size is not a checkpoint
+func (policy rootPolicy) record(ctx context.Context, exec execer, tree string, size int, root string) error {
+	if size == 0 || (policy.checkpoint > 1 && size%policy.checkpoint != 0) {
+		return nil
+	}
+	b, err := hashBytes(root)
+	if err != nil {
+		return err
+	}
+	var signature []byte
+	if policy.sign != nil {
+		if signature, err = policy.sign(size, root); err != nil {
+			return err
+		}
+	}
+	_, err = exec.ExecContext(ctx, InsertRootQuery, tree, size, b, signature)
+	return err
+}
+
+// rootAt returns the single root selected by the query
+func rootAt(ctx context.Context, db *sql.DB, query string, args ...interface{}) (merkletree.RootRecord, error) {
+	var record merkletree.RootRecord
+	var root, signature []byte
+	err := db.QueryRowContext(ctx, query, args...).Scan(&record.Size, &root, &record.CreatedAt, &signature)
+	if err == sql.ErrNoRows {
+		return record, errors.New(noRoot)
+	}
+	if err != nil {
+		return record, err
+	}
+	record.Root = nodeHash(root)
+	if len(signature) > 0 {
+		record.Signature = hexutil.Encode(signature)
+	}
+	return record, nil
+}
+
+// RootAtSize returns the last root recorded at the given size
+func (tree *PostgresMerkleTree) RootAtSize(size int) (merkletree.RootRecord, error) {
+	return rootAt(context.Background(), tree.db, RootAtSizeQuery, tree.name, size)
+}
+
+// RootAtTime returns the last root recorded at or before the given time
+func (tree *PostgresMerkleTree) RootAtTime(t time.Time) (merkletree.RootRecord, error) {
+	return rootAt(context.Background(), tree.db, RootAtTimeQuery, tree.name, t)
+}
+
+// RootAtSize returns the last root recorded at the given size
+func (tree *NodeTree) RootAtSize(size int) (merkletree.RootRecord, error) {
+	return rootAt(context.Background(), tree.db, RootAtSizeQuery, tree.name, size)
+}
+
+// RootAtTime returns the last root recorded at or before the given time
+func (tree *NodeTree) RootAtTime(t time.Time) (merkletree.RootRecord, error) {
+	return rootAt(context.Background(), tree.db, RootAtTimeQuery, tree.name, t)
+}
@@ -0,0 +1,124 @@
+package postgres
+
+import (
+	"context"
+	"github.com/LimeChain/merkletree"
+	"github.com/LimeChain/merkletree/memory"
+	"github.com/LimeChain/merkletree/merkletreetest"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestRootHistory(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	db, _, cleanup := testDB(et)
+	defer cleanup()
+	ctx := context.Background()
+
+	memoryTree := memory.NewMerkleTree()
+	tree, err := LoadMerkleTreeContext(ctx, memoryTree, Options{DB: db, Tree: "history"})
+	if err != nil {
+		et.Fatal(err)
+	}
+	_, err = tree.RootAtTime(time.Now())
+	et.Assert(err != nil && err.Error() == noRoot, "Incorrect error was thrown on empty history", err)
+
+	start := time.Now()
+	addLeafs(et, tree, 3)
+	tree.RawAdd([]byte("Leaf3"))
+	tree.RawAdd([]byte("Leaf4"))
+	tree.Recalculate()
+	for size := 1; size <= 5; size++ {
+		expected, _ := memoryTree.RootAt(size)
+		record, err := tree.RootAtSize(size)
+		if size == 4 { // Added without recalculation
+			et.Assert(err != nil, "Root of raw addition was recorded")
+			continue
+		}
+		et.Assert(err == nil && record.Size == size && record.Root == expected, "The recorded root was not correct", size, err)
+		et.Assert(!record.CreatedAt.Before(start.Add(-time.Minute)), "The time of the root was not recorded", size)
+	}
+
+	record, err := tree.RootAtTime(time.Now().Add(time.Minute))
+	et.Assert(err == nil && record.Size == 5 && record.Root == tree.Root(), "The root at the current time was not the last root", err)
+	_, err = tree.RootAtTime(start.Add(-time.Hour))
+	et.Assert(err != nil && err.Error() == noRoot, "Root was returned before the first addition", err)
+
+	// After truncation the last root recorded at a size wins
+	rootAt3, _ := memoryTree.RootAt(3)
+	truncatedRoot := tree.Root()
+	et.Assert(tree.Truncate(3) == nil, "Error was thrown on truncate")
+	record, err = tree.RootAtSize(3)
+	et.Assert(err == nil && record.Root == rootAt3, "The root after truncate was not correct", err)
+	record, _ = tree.RootAtTime(time.Now().Add(time.Minute))
+	et.Assert(record.Size == 3, "The last root was not the root after truncate", record.Size)
+	tree.Add([]byte("Other Leaf3"))
+	tree.Add([]byte("Other Leaf4"))
+	record, _ = tree.RootAtSize(5)
+	et.Assert(record.Root == tree.Root() && record.Root != truncatedRoot, "The last root recorded at the size did not win")
+
+	nodeTree, err := LoadNodeTreeContext(ctx, Options{DB: db, Tree: "history"})
+	if err != nil {
+		et.Fatal(err)
+	}
+	record, err = nodeTree.RootAtSize(5)
+	et.Assert(err == nil && record.Root == tree.Root(), "The node tree did not read the same history", err)
+}
+
+func TestRootCheckpointAndSignature(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	db, _, cleanup := testDB(et)
+	defer cleanup()
+	ctx := context.Background()
+
+	sign := func(size int, root string) ([]byte, error) {
+		return []byte(strconv.Itoa(size) + root), nil
+	}
+	verify := func(size int, root string, signature []byte) bool {
+		return string(signature) == strconv.Itoa(size)+root
+	}
+	signed := func(record merkletree.RootRecord) bool {
+		signature, err := hexutil.Decode(record.Signature)
+		return err == nil && verify(record.Size, record.Root, signature)
+	}
+	opts := Options{DB: db, Tree: "checkpoints", RootCheckpoint: 2, SignRoot: sign, VerifyRoot: verify}
+
+	tree, err := LoadMerkleTreeContext(ctx, memory.NewMerkleTree(), opts)
+	if err != nil {
+		et.Fatal(err)
+	}
+	addLeafs(et, tree, 5)
+	for size := 1; size <= 5; size++ {
+		record, err := tree.RootAtSize(size)
+		if size%2 == 1 {
+			et.Assert(err != nil, "Root between the checkpoints was recorded", size)
+			continue
+		}
+		et.Assert(err == nil && signed(record), "The root at the checkpoint was not signed", size, err)
+	}
+
+	nodeTree, _ := LoadNodeTreeContext(ctx, opts)
+	nodeTree.Add([]byte("Leaf5"))
+	record, err := nodeTree.RootAtSize(6)
+	et.Assert(err == nil && record.Root == nodeTree.Root() && signed(record), "The node tree did not sign the checkpoint", err)
+
+	// Every root is signed once the checkpoint is unset, the root that fails to be signed fails the addition
+	failing := opts
+	failing.RootCheckpoint = 0
+	failing.SignRoot = func(size int, root string) ([]byte, error) {
+		return nil, context.DeadlineExceeded
+	}
+	failingTree, err := LoadMerkleTreeContext(ctx, memory.NewMerkleTree(), failing)
+	if err != nil {
+		et.Fatal(err)
+	}
+	_, _, err = failingTree.Append([]byte("Leaf6"))
+	et.Assert(err == context.DeadlineExceeded, "The error of signing was not returned", err)
+	et.Assert(failingTree.Length() == 6, "The leaf whose root could not be signed was not rolled back")
+	leafs, _ := NewNamedStore(db, "checkpoints").LoadLeaves()
+	et.Assert(len(leafs) == 6, "The leaf whose root could not be signed was stored", len(leafs))
+	_, err = failingTree.RootAtSize(7)
+	et.Assert(err != nil, "The root that could not be signed was recorded")
+}
@@ -0,0 +1,170 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/LimeChain/merkletree"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"time"
+)
+
+// Store is implementation of merkletree.NodeStore keeping the leafs of a single tree with their indexes in the hashes table,
+// the intermediary nodes in the nodes table and the salts of the salted leafs in the salts table.
+// The hashes are stored as bytes and are loaded as 0x prefixed hex
+type Store struct {
+	db    *sql.DB
+	tree  string
+	roots rootPolicy
+}
+
+// AppendLeaves inserts the hashes in a single transaction as the leafs from the given index onwards.
+// Fails if the start is not right after the last stored leaf
+func (store *Store) AppendLeaves(start int, hashes []string) error {
+	dbTx, err := store.db.Begin()
+	if err != nil {
+		return err
+	}
+	for i, hash := range hashes {
+		err = insertLeaf(dbTx.Exec, store.tree, start+i, hash)
+		if err != nil {
+			dbTx.Rollback()
+			return err
+		}
+	}
+	return dbTx.Commit()
+}
+
+// TruncateLeaves deletes the rows from the given size onwards with their salts, their roots and the nodes that are no longer
+// part of the tree
+func (store *Store) TruncateLeaves(size int) error {
+	dbTx, err := store.db.Begin()
+	if err != nil {
+		return err
+	}
+	_, err = dbTx.Exec(TruncateQuery, store.tree, size)
+	if err == nil {
+		_, err = dbTx.Exec(TruncateSaltsQuery, store.tree, size)
+	}
+	if err == nil {
+		_, err = dbTx.Exec(TruncateRootsQuery, store.tree, size)
+	}
+	if err == nil {
+		_, err = dbTx.Exec(TruncateNodesQuery, store.tree, levelsOf(size), size)
+	}
+	if err != nil {
+		dbTx.Rollback()
+		return err
+	}
+	return dbTx.Commit()
+}
+
+// SaveNodes stores the intermediary nodes, replacing the ones stored at the same level and index.
+// The leafs are already stored by AppendLeaves and are skipped
+func (store *Store) SaveNodes(nodes []merkletree.StoredNode) error {
+	return saveNodes(context.Background(), store.db, store.tree, nodes)
+}
+
+// LoadNode returns the hash of the node at the given level and index
+func (store *Store) LoadNode(level int, index int) (string, error) {
+	position := [2]int{level, index}
+	nodes, err := loadNodes(context.Background(), store.db, store.tree, [][2]int{position})
+	if err != nil {
+		return "", err
+	}
+	hash, ok := nodes[position]
+	if !ok {
+		return "", fmt.Errorf("%v at level %v and index %v", missingNode, level, index)
+	}
+	return hexutil.Encode(hash), nil
+}
+
+// LoadLeaves returns the hashes of all rows
+func (store *Store) LoadLeaves() ([]string, error) {
+	hashes := make([]string, 0)
+	err := store.IterateLeaves(func(index int, hash string) error {
+		hashes = append(hashes, hash)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return hashes, nil
+}
+
+// IterateLeaves scans the rows one by one in the order of their leaf_index and calls fn with every index and hash
+func (store *Store) IterateLeaves(fn func(index int, hash string) error) error {
+	return store.IterateLeavesContext(context.Background(), fn)
+}
+
+// IterateLeavesContext is IterateLeaves that stops the query when the context is done
+func (store *Store) IterateLeavesContext(ctx context.Context, fn func(index int, hash string) error) error {
+	rows, err := store.db.QueryContext(ctx, SelectQuery, store.tree)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var index int
+		var hash []byte
+		if err := rows.Scan(&index, &hash); err != nil {
+			return err
+		}
+		if err := fn(index, hexutil.Encode(hash)); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// SaveSalt stores the salt of the leaf at the given index, replacing the one stored at the same index
+func (store *Store) SaveSalt(index int, salt []byte) error {
+	_, err := store.db.Exec(SaveSaltQuery, store.tree, index, salt)
+	return err
+}
+
+// LoadSalts returns the stored salts by the index of their leafs
+func (store *Store) LoadSalts() (map[int][]byte, error) {
+	rows, err := store.db.Query(SelectSaltsQuery, store.tree)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	salts := make(map[int][]byte)
+	for rows.Next() {
+		var index int
+		var salt []byte
+		if err := rows.Scan(&index, &salt); err != nil {
+			return nil, err
+		}
+		salts[index] = salt
+	}
+	return salts, rows.Err()
+}
+
+// RecordRoot records the root of the tree at the given size in the roots table
+func (store *Store) RecordRoot(size int, root string) error {
+	return store.roots.record(context.Background(), store.db, store.tree, size, root)
+}
+
+// RootAtSize returns the last root recorded at the given size
+func (store *Store) RootAtSize(size int) (merkletree.RootRecord, error) {
+	return rootAt(context.Background(), store.db, RootAtSizeQuery, store.tree, size)
+}
+
+// RootAtTime returns the last root recorded at or before the given time
+func (store *Store) RootAtTime(t time.Time) (merkletree.RootRecord, error) {
+	return rootAt(context.Background(), store.db, RootAtTimeQuery, store.tree, t)
+}
+
+// NewStore returns a Store of the default tree in the database
+func NewStore(db *sql.DB) *Store {
+	return NewNamedStore(db, DefaultTree)
+}
+
+// NewNamedStore returns a Store of the tree with the given name in the database
+func NewNamedStore(db *sql.DB, tree string) *Store {
+	return &Store{db: db, tree: tree}
+}
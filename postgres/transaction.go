@@ -0,0 +1,146 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/LimeChain/merkletree"
+)
+
+// Transaction is implementation of merkletree.Transaction backed by a single SQL transaction.
+// The additions are published in the in-memory tree on Commit and are removed from it again if the SQL transaction fails
+type Transaction struct {
+	tree   *PostgresMerkleTree
+	inner  merkletree.Transaction
+	dbTx   *sql.Tx
+	start  int
+	err    error
+	closed bool
+}
+
+// Begin starts a transaction. The tree is locked for other writers until Commit or Rollback
+func (tree *PostgresMerkleTree) Begin() (merkletree.Transaction, error) {
+	if tree.mode == Follower {
+		return nil, errors.New(readOnly)
+	}
+	transactional, ok := tree.FullMerkleTree.(merkletree.TransactionalMerkleTree)
+	if !ok {
+		return nil, errors.New(notTransactional)
+	}
+	if _, ok := transactional.(merkletree.Truncater); !ok { // Needed to undo the commit of the tree
+		return nil, errors.New(notTransactional)
+	}
+
+	tree.Mutex.Lock()
+
+	dbTx, err := tree.db.Begin()
+	if err != nil {
+		tree.Mutex.Unlock()
+		return nil, err
+	}
+
+	inner, err := transactional.Begin()
+	if err != nil {
+		dbTx.Rollback()
+		tree.Mutex.Unlock()
+		return nil, err
+	}
+
+	return &Transaction{tree: tree, inner: inner, dbTx: dbTx, start: transactional.Length()}, nil
+}
+
+// Add hashes the data and writes it in the SQL transaction
+// Returns the index it will be inserted at and the hash of the data, or -1 and empty hash if the transaction is closed
+func (tx *Transaction) Add(data []byte) (index int, hash string) {
+	if tx.closed {
+		return -1, ""
+	}
+	index, hash = tx.inner.Add(data)
+	tx.addHashToDB(index, hash)
+	return index, hash
+}
+
+// Insert writes the hash in the SQL transaction
+// Returns the index it will be inserted at, or -1 if the transaction is closed
+func (tx *Transaction) Insert(hash string) (index int) {
+	if tx.closed {
+		return -1
+	}
+	index = tx.inner.Insert(hash)
+	tx.addHashToDB(index, hash)
+	return index
+}
+
+func (tx *Transaction) addHashToDB(index int, hash string) {
+	if tx.err != nil {
+		return
+	}
+	tx.err = insertLeaf(tx.dbTx.Exec, tx.tree.name, index, hash)
+}
+
+// Commit publishes the additions in the in-memory tree, saves the changed nodes and records the new root in the SQL
+// transaction and commits it. If any of the writes failed, everything is rolled back and the first error is returned
+func (tx *Transaction) Commit() (root string, err error) {
+	if tx.closed {
+		return "", errors.New(transactionClosed)
+	}
+	defer tx.close()
+
+	if tx.err != nil {
+		tx.dbTx.Rollback()
+		tx.inner.Rollback()
+		return "", tx.err
+	}
+
+	root, err = tx.inner.Commit()
+	if err != nil {
+		tx.dbTx.Rollback()
+		return "", err
+	}
+
+	ctx := context.Background()
+	length := tx.tree.FullMerkleTree.Length()
+	err = tx.saveNodes(ctx, length)
+	if err == nil {
+		err = tx.tree.Store().(*Store).roots.record(ctx, tx.dbTx, tx.tree.name, length, root)
+	}
+	if err == nil {
+		err = tx.dbTx.Commit()
+	} else {
+		tx.dbTx.Rollback()
+	}
+	if err != nil {
+		if truncateErr := tx.tree.FullMerkleTree.(merkletree.Truncater).Truncate(tx.start); truncateErr != nil {
+			return "", fmt.Errorf("%v. Rolling back the tree failed: %v", err, truncateErr)
+		}
+		return "", err
+	}
+	return root, nil
+}
+
+// saveNodes calculates the nodes on the paths of the added leafs in the SQL transaction. As outside of transactions,
+// the nodes are saved only for trees that expose their paths
+func (tx *Transaction) saveNodes(ctx context.Context, length int) error {
+	if _, ok := tx.tree.FullMerkleTree.(merkletree.PathMerkleTree); !ok || length == tx.start {
+		return nil
+	}
+	_, err := updateNodes(ctx, tx.dbTx, tx.tree.name, tx.start, length)
+	return err
+}
+
+// Rollback discards all additions both from the SQL transaction and from the tree
+func (tx *Transaction) Rollback() error {
+	if tx.closed {
+		return errors.New(transactionClosed)
+	}
+	defer tx.close()
+
+	tx.inner.Rollback()
+	return tx.dbTx.Rollback()
+}
+
+func (tx *Transaction) close() {
+	tx.closed = true
+	tx.tree.Mutex.Unlock()
+}
@@ -0,0 +1,59 @@
+package postgres
+
+import (
+	"context"
+	"github.com/LimeChain/merkletree/memory"
+	"github.com/LimeChain/merkletree/merkletreetest"
+	"github.com/ethereum/go-ethereum/common"
+	"testing"
+)
+
+func TestTransaction(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	db, _, cleanup := testDB(et)
+	defer cleanup()
+	ctx := context.Background()
+
+	memoryTree := memory.NewMerkleTree()
+	tree, err := LoadMerkleTreeContext(ctx, memoryTree, Options{DB: db, Tree: "transactional"})
+	if err != nil {
+		et.Fatal(err)
+	}
+	addLeafs(et, tree, 3)
+
+	tx, err := tree.Begin()
+	if err != nil {
+		et.Fatal(err)
+	}
+	for _, data := range []string{"Leaf3", "Leaf4", "Leaf5", "Leaf6"} {
+		tx.Add([]byte(data))
+	}
+	root, err := tx.Commit()
+	et.Assert(err == nil && root == memoryTree.Root() && tree.Length() == 7, "The transaction was not committed", err)
+
+	// The nodes saved in the transaction are the nodes of the tree
+	nodeTree, err := LoadNodeTreeContext(ctx, Options{DB: db, Tree: "transactional"})
+	et.Assert(err == nil, "Error was thrown on opening the nodes of the tree", err)
+	assertSameTree(et, nodeTree, memoryTree, "committed")
+	record, err := tree.RootAtSize(7)
+	et.Assert(err == nil && record.Root == root, "The root of the transaction was not recorded", err)
+
+	tx, _ = tree.Begin()
+	tx.Add([]byte("Leaf7"))
+	et.Assert(tx.Rollback() == nil, "Error was thrown on rollback")
+	leafs, _ := NewNamedStore(db, "transactional").LoadLeaves()
+	et.Assert(tree.Length() == 7 && len(leafs) == 7 && tree.Root() == root, "The transaction was not rolled back")
+
+	// The leaf taken by another process fails the transaction
+	_, err = db.Exec(InsertQuery, "transactional", 7, common.Hash{1}.Bytes())
+	if err != nil {
+		et.Fatal(err)
+	}
+	tx, _ = tree.Begin()
+	tx.Add([]byte("Leaf7"))
+	_, err = tx.Commit()
+	et.Assert(err != nil, "Error was not thrown on taken index")
+	et.Assert(tree.Length() == 7 && tree.Root() == root, "The failed transaction was not rolled back")
+	_, err = tx.Commit()
+	et.Assert(err != nil && err.Error() == transactionClosed, "Incorrect error was thrown on committing twice")
+}
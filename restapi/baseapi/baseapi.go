@@ -3,10 +3,17 @@ package baseapi
 import (
 	"encoding/json"
 	"github.com/LimeChain/merkletree"
+	"github.com/LimeChain/merkletree/encoder"
 	"github.com/go-chi/chi"
 	"github.com/go-chi/render"
 	"net/http"
 	"strconv"
+	"time"
+)
+
+const (
+	noRootHistory = "Incorrect tree - The tree does not keep the history of its roots"
+	notAdded      = "Incorrect data - The data could not be added to the tree"
 )
 
 // MerkleTreeStatus takes pointer to initialized router and the merkle tree and exposes Rest API routes for getting of status
@@ -21,7 +28,8 @@ func MerkleTreeHashes(treeRouter *chi.Mux, tree merkletree.ExternalMerkleTree) *
 	return treeRouter
 }
 
-// MerkleTreeInsert takes pointer to initialized router and the merkle tree and exposes Rest API routes for addition
+// MerkleTreeInsert takes pointer to initialized router and the merkle tree and exposes Rest API routes for addition.
+// If the tree is merkletree.Appender, failed additions are reported to the client
 func MerkleTreeInsert(treeRouter *chi.Mux, tree merkletree.ExternalMerkleTree) *chi.Mux {
 	treeRouter.Post("/", addDataHandler(tree, true))
 	return treeRouter
@@ -33,6 +41,32 @@ func MerkleTreeRawInsert(treeRouter *chi.Mux, tree merkletree.ExternalMerkleTree
 	return treeRouter
 }
 
+// MerkleTreeRoots takes pointer to initialized router and the root history of a tree and exposes Rest API routes for getting
+// the root at a size and the root at a time given as RFC 3339 time parameter, e.g. /roots?time=2020-03-01T14:00:00Z
+func MerkleTreeRoots(treeRouter *chi.Mux, history merkletree.RootHistory) *chi.Mux {
+	treeRouter.Get("/roots", getRootAtTimeHandler(history))
+	treeRouter.Get("/roots/{size}", getRootAtSizeHandler(history))
+	return treeRouter
+}
+
+// MerkleTrees takes pointer to initialized router and the registry of trees and exposes Rest API routes for listing and creation
+// of trees and for the status, addition, intermediary hashes and roots of every tree under /trees/{tree}
+func MerkleTrees(treeRouter *chi.Mux, registry merkletree.TreeRegistry) *chi.Mux {
+	treeRouter.Get("/trees", listTreesHandler(registry))
+	treeRouter.Post("/trees", createTreeHandler(registry))
+	treeRouter.Get("/trees/{tree}", withTree(registry, getTreeStatus))
+	treeRouter.Post("/trees/{tree}", withTree(registry, func(tree merkletree.ExternalMerkleTree) http.HandlerFunc {
+		return addDataHandler(tree, true)
+	}))
+	treeRouter.Post("/trees/{tree}/raw", withTree(registry, func(tree merkletree.ExternalMerkleTree) http.HandlerFunc {
+		return addDataHandler(tree, false)
+	}))
+	treeRouter.Get("/trees/{tree}/hashes/{index}", withTree(registry, getIntermediaryHashesHandler))
+	treeRouter.Get("/trees/{tree}/roots", withRootHistory(registry, getRootAtTimeHandler))
+	treeRouter.Get("/trees/{tree}/roots/{size}", withRootHistory(registry, getRootAtSizeHandler))
+	return treeRouter
+}
+
 // MerkleAPIResponse represents the minimal response structure
 type MerkleAPIResponse struct {
 	Status bool   `json:"status"`
@@ -76,8 +110,12 @@ func getIntermediaryHashesHandler(tree merkletree.ExternalMerkleTree) http.Handl
 	}
 }
 
+// addDataRequest is the body of the addition. Instead of raw data it can carry structured payload and the name of the
+// encoder that canonicalizes it, e.g. {"encoding": "jcs", "payload": {"name": "Alice"}}
 type addDataRequest struct {
-	Data string `json:"data"`
+	Data     string          `json:"data"`
+	Encoding string          `json:"encoding,omitempty"`
+	Payload  json.RawMessage `json:"payload,omitempty"`
 }
 
 type addDataResponse struct {
@@ -96,17 +134,149 @@ func addDataHandler(tree merkletree.ExternalMerkleTree, recalculate bool) http.H
 			return
 		}
 
-		if b.Data == "" {
+		data := []byte(b.Data)
+		if b.Encoding != "" {
+			data, err = encoder.Encode(b.Encoding, b.Payload)
+			if err != nil {
+				render.JSON(w, r, addDataResponse{MerkleAPIResponse{false, err.Error()}, -1, ""})
+				return
+			}
+		}
+
+		if len(data) == 0 {
 			render.JSON(w, r, addDataResponse{MerkleAPIResponse{false, "Missing data field"}, -1, ""})
 			return
 		}
 		var index int
 		var hash string
-		if recalculate {
-			index, hash = tree.Add([]byte(b.Data))
+		if appender, ok := tree.(merkletree.Appender); ok {
+			if recalculate {
+				index, hash, err = appender.Append(data)
+			} else {
+				index, hash, err = appender.RawAppend(data)
+			}
+			if err != nil {
+				render.JSON(w, r, addDataResponse{MerkleAPIResponse{false, err.Error()}, -1, ""})
+				return
+			}
+		} else if recalculate {
+			index, hash = tree.Add(data)
 		} else {
-			index, hash = tree.RawAdd([]byte(b.Data))
+			index, hash = tree.RawAdd(data)
+		}
+		if index < 0 {
+			render.JSON(w, r, addDataResponse{MerkleAPIResponse{false, notAdded}, -1, ""})
+			return
 		}
 		render.JSON(w, r, addDataResponse{MerkleAPIResponse{true, ""}, index, hash})
 	}
 }
+
+// withTree resolves the tree in the route and serves the request with the handler of that tree
+func withTree(registry merkletree.TreeRegistry, handler func(tree merkletree.ExternalMerkleTree) http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tree, err := registry.Tree(chi.URLParam(r, "tree"))
+		if err != nil {
+			render.JSON(w, r, MerkleAPIResponse{false, err.Error()})
+			return
+		}
+		handler(tree)(w, r)
+	}
+}
+
+type listTreesResponse struct {
+	MerkleAPIResponse
+	Trees []string `json:"trees"`
+}
+
+func listTreesHandler(registry merkletree.TreeRegistry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		names, err := registry.Trees()
+		if err != nil {
+			render.JSON(w, r, listTreesResponse{MerkleAPIResponse{false, err.Error()}, nil})
+			return
+		}
+		render.JSON(w, r, listTreesResponse{MerkleAPIResponse{true, ""}, names})
+	}
+}
+
+type createTreeRequest struct {
+	Name string `json:"name"`
+}
+
+func createTreeHandler(registry merkletree.TreeRegistry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		decoder := json.NewDecoder(r.Body)
+		var b createTreeRequest
+		err := decoder.Decode(&b)
+		if err != nil {
+			render.JSON(w, r, MerkleAPIResponse{false, err.Error()})
+			return
+		}
+
+		if b.Name == "" {
+			render.JSON(w, r, MerkleAPIResponse{false, "Missing name field"})
+			return
+		}
+		if _, err := registry.Create(b.Name); err != nil {
+			render.JSON(w, r, MerkleAPIResponse{false, err.Error()})
+			return
+		}
+		render.JSON(w, r, MerkleAPIResponse{true, ""})
+	}
+}
+
+// withRootHistory resolves the tree in the route and serves the request with the handler of its root history
+func withRootHistory(registry merkletree.TreeRegistry, handler func(history merkletree.RootHistory) http.HandlerFunc) http.HandlerFunc {
+	return withTree(registry, func(tree merkletree.ExternalMerkleTree) http.HandlerFunc {
+		history, ok := tree.(merkletree.RootHistory)
+		if !ok {
+			return func(w http.ResponseWriter, r *http.Request) {
+				render.JSON(w, r, rootResponse{MerkleAPIResponse{false, noRootHistory}, nil})
+			}
+		}
+		return handler(history)
+	})
+}
+
+type rootResponse struct {
+	MerkleAPIResponse
+	Record *merkletree.RootRecord `json:"record,omitempty"`
+}
+
+func getRootAtSizeHandler(history merkletree.RootHistory) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		size, err := strconv.Atoi(chi.URLParam(r, "size"))
+		if err != nil {
+			render.JSON(w, r, rootResponse{MerkleAPIResponse{false, err.Error()}, nil})
+			return
+		}
+		record, err := history.RootAtSize(size)
+		if err != nil {
+			render.JSON(w, r, rootResponse{MerkleAPIResponse{false, err.Error()}, nil})
+			return
+		}
+		render.JSON(w, r, rootResponse{MerkleAPIResponse{true, ""}, &record})
+	}
+}
+
+func getRootAtTimeHandler(history merkletree.RootHistory) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		param := r.URL.Query().Get("time")
+		if param == "" {
+			render.JSON(w, r, rootResponse{MerkleAPIResponse{false, "Missing time parameter"}, nil})
+			return
+		}
+		t, err := time.Parse(time.RFC3339, param)
+		if err != nil {
+			render.JSON(w, r, rootResponse{MerkleAPIResponse{false, err.Error()}, nil})
+			return
+		}
+		record, err := history.RootAtTime(t)
+		if err != nil {
+			render.JSON(w, r, rootResponse{MerkleAPIResponse{false, err.Error()}, nil})
+			return
+		}
+		render.JSON(w, r, rootResponse{MerkleAPIResponse{true, ""}, &record})
+	}
+}
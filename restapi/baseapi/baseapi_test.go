@@ -3,9 +3,12 @@ package baseapi
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/LimeChain/merkletree"
 	"github.com/LimeChain/merkletree/memory"
 	"github.com/LimeChain/merkletree/merkletreetest"
+	"github.com/LimeChain/merkletree/persistent"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/middleware"
@@ -13,8 +16,10 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"sort"
 	"strings"
 	"testing"
+	"time"
 )
 
 func assertValidResponse(et *merkletreetest.ExtendedTesting, resp *http.Response, err error) {
@@ -132,3 +137,298 @@ func TestMerkleTreeInsert(t *testing.T) {
 	et.Assert(r.Index == -1, "The inserted index was not -1 for wrong addition")
 
 }
+
+func TestMerkleTreeInsertEncoded(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree := memory.NewMerkleTree()
+
+	router := chi.NewRouter()
+	router.Use(
+		render.SetContentType(render.ContentTypeJSON),
+		middleware.Logger,
+		middleware.DefaultCompress,
+		middleware.RedirectSlashes,
+		middleware.Recoverer,
+	)
+	router.Route("/v1", func(r chi.Router) {
+		treeRouter := chi.NewRouter()
+		treeRouter = MerkleTreeInsert(treeRouter, tree)
+		r.Mount("/api/merkletree", treeRouter)
+	})
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	post := func(req string) addDataResponse {
+		resp, err := server.Client().Post(server.URL+"/v1/api/merkletree", "application/json", bytes.NewBufferString(req))
+		assertValidResponse(et, resp, err)
+
+		var r addDataResponse
+		err = json.NewDecoder(resp.Body).Decode(&r)
+		et.Assert(err == nil, "Error was thrown when parsing the response")
+		return r
+	}
+
+	r1 := post(`{"encoding": "jcs", "payload": {"name": "Alice", "age": 30.0}}`)
+	et.Assert(r1.Status, "The status for inserting encoded payload was false")
+	et.Assert(r1.Hash == crypto.Keccak256Hash([]byte(`{"age":30,"name":"Alice"}`)).Hex(), "The returned hash was not the hash of the canonical payload")
+
+	r2 := post(`{"encoding": "jcs", "payload": {"age": 30, "name": "Alice"}}`)
+	et.Assert(r2.Status, "The status for inserting encoded payload was false")
+	et.Assert(r2.Index == 1, "The inserted index was not 1 for second addition")
+	et.Assert(r1.Hash == r2.Hash, "Semantically equal payloads did not produce the same leaf")
+
+	r := post(`{"encoding": "yaml", "payload": {"name": "Alice"}}`)
+	et.Assert(!r.Status, "The status for unknown encoding was true")
+	et.Assert(r.Index == -1, "The inserted index was not -1 for wrong addition")
+
+	r = post(`{"encoding": "jcs"}`)
+	et.Assert(!r.Status, "The status for missing payload was true")
+	et.Assert(tree.Length() == 2, "Invalid requests were inserted in the tree")
+}
+
+func TestMerkleTreeInsertFailure(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	store := &merkletreetest.MemoryStore{}
+	tree, _ := persistent.Load(memory.NewMerkleTree(), store)
+
+	router := chi.NewRouter()
+	router.Use(
+		render.SetContentType(render.ContentTypeJSON),
+		middleware.Logger,
+		middleware.DefaultCompress,
+		middleware.RedirectSlashes,
+		middleware.Recoverer,
+	)
+	router.Route("/v1", func(r chi.Router) {
+		treeRouter := chi.NewRouter()
+		treeRouter = MerkleTreeInsert(treeRouter, tree)
+		treeRouter = MerkleTreeRawInsert(treeRouter, tree)
+		r.Mount("/api/merkletree", treeRouter)
+	})
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	post := func(path string, data string) addDataResponse {
+		reqString, _ := json.Marshal(addDataRequest{Data: data})
+		resp, err := server.Client().Post(server.URL+"/v1/api/merkletree"+path, "application/json", bytes.NewBuffer(reqString))
+		assertValidResponse(et, resp, err)
+
+		var r addDataResponse
+		err = json.NewDecoder(resp.Body).Decode(&r)
+		et.Assert(err == nil, "Error was thrown when parsing the response")
+		return r
+	}
+
+	r := post("", "First Leaf")
+	et.Assert(r.Status, "The status for inserting in the tree was false")
+	root := tree.Root()
+
+	store.Err = errors.New("connection refused")
+	r = post("", "Second Leaf")
+	et.Assert(!r.Status, "The status for failed insert was true")
+	et.Assert(r.Error == "connection refused", "The error of the store was not returned")
+	et.Assert(r.Index == -1, "The inserted index was not -1 for failed addition")
+	et.Assert(tree.Length() == 1 && tree.Root() == root, "The failed addition was not rolled back")
+
+	r = post("/raw", "Second Leaf")
+	et.Assert(!r.Status, "The status for failed raw insert was true")
+	et.Assert(tree.Length() == 1, "The failed raw addition was not rolled back")
+
+	store.Err = nil
+	r = post("", "Second Leaf")
+	et.Assert(r.Status && r.Index == 1, "The insert after the failure did not take the next index")
+	et.Assert(len(store.Leafs) == 2, "The store and the tree diverged")
+}
+
+// rejectingTree fails every addition the way trees without merkletree.Appender report it
+type rejectingTree struct {
+	*memory.MerkleTree
+}
+
+func (tree rejectingTree) Add(data []byte) (index int, hash string) {
+	return -1, ""
+}
+
+func (tree rejectingTree) RawAdd(data []byte) (index int, hash string) {
+	return -1, ""
+}
+
+func TestMerkleTreeInsertRejected(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree := rejectingTree{memory.NewMerkleTree()}
+
+	router := chi.NewRouter()
+	router.Use(render.SetContentType(render.ContentTypeJSON))
+	router.Route("/v1", func(r chi.Router) {
+		treeRouter := chi.NewRouter()
+		treeRouter = MerkleTreeInsert(treeRouter, tree)
+		treeRouter = MerkleTreeRawInsert(treeRouter, tree)
+		r.Mount("/api/merkletree", treeRouter)
+	})
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	for _, path := range []string{"", "/raw"} {
+		reqString, _ := json.Marshal(addDataRequest{Data: "First Leaf"})
+		resp, err := server.Client().Post(server.URL+"/v1/api/merkletree"+path, "application/json", bytes.NewBuffer(reqString))
+		assertValidResponse(et, resp, err)
+
+		var r addDataResponse
+		err = json.NewDecoder(resp.Body).Decode(&r)
+		et.Assert(err == nil, "Error was thrown when parsing the response")
+		et.Assert(!r.Status, "The status for rejected insert was true", path)
+		et.Assert(r.Error == notAdded, "Incorrect error for rejected insert", r.Error)
+		et.Assert(r.Index == -1 && r.Hash == "", "Incorrect index and hash for rejected insert")
+	}
+}
+
+// memoryRegistry keeps the trees in memory the way a database registry would
+type memoryRegistry struct {
+	trees map[string]merkletree.ExternalMerkleTree
+}
+
+func (registry *memoryRegistry) Trees() ([]string, error) {
+	names := make([]string, 0, len(registry.trees))
+	for name := range registry.trees {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (registry *memoryRegistry) Create(name string) (merkletree.ExternalMerkleTree, error) {
+	if _, ok := registry.trees[name]; ok {
+		return nil, errors.New("Incorrect tree - The tree already exists")
+	}
+	registry.trees[name] = memory.NewMerkleTree()
+	return registry.trees[name], nil
+}
+
+func (registry *memoryRegistry) Tree(name string) (merkletree.ExternalMerkleTree, error) {
+	tree, ok := registry.trees[name]
+	if !ok {
+		return nil, errors.New("Incorrect tree - The tree does not exist")
+	}
+	return tree, nil
+}
+
+func TestMerkleTrees(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	registry := &memoryRegistry{make(map[string]merkletree.ExternalMerkleTree)}
+
+	router := chi.NewRouter()
+	router.Use(
+		render.SetContentType(render.ContentTypeJSON),
+		middleware.Logger,
+		middleware.DefaultCompress,
+		middleware.RedirectSlashes,
+		middleware.Recoverer,
+	)
+	router.Route("/v1", func(r chi.Router) {
+		treeRouter := chi.NewRouter()
+		treeRouter = MerkleTrees(treeRouter, registry)
+		r.Mount("/api/merkletree", treeRouter)
+	})
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	post := func(path string, req interface{}, res interface{}) {
+		reqString, _ := json.Marshal(req)
+		resp, err := server.Client().Post(server.URL+"/v1/api/merkletree"+path, "application/json", bytes.NewBuffer(reqString))
+		assertValidResponse(et, resp, err)
+		err = json.NewDecoder(resp.Body).Decode(res)
+		et.Assert(err == nil, "Error was thrown when parsing the response")
+	}
+	get := func(path string, res interface{}) {
+		resp, err := server.Client().Get(server.URL + "/v1/api/merkletree" + path)
+		assertValidResponse(et, resp, err)
+		err = json.NewDecoder(resp.Body).Decode(res)
+		et.Assert(err == nil, "Error was thrown when parsing the response")
+	}
+
+	var created MerkleAPIResponse
+	post("/trees", createTreeRequest{"first"}, &created)
+	et.Assert(created.Status, "The status for creating tree was false")
+	post("/trees", createTreeRequest{"second"}, &created)
+	et.Assert(created.Status, "The status for creating tree was false")
+	post("/trees", createTreeRequest{"second"}, &created)
+	et.Assert(!created.Status, "The status for creating existing tree was true")
+
+	var list listTreesResponse
+	get("/trees", &list)
+	et.Assert(list.Status && len(list.Trees) == 2 && list.Trees[0] == "first", "The trees were not listed")
+
+	var added addDataResponse
+	post("/trees/first", addDataRequest{Data: "First Leaf"}, &added)
+	et.Assert(added.Status && added.Index == 0, "The data was not added to the first tree")
+	post("/trees/first/raw", addDataRequest{Data: "Second Leaf"}, &added)
+	et.Assert(added.Status && added.Index == 1, "The data was not raw added to the first tree")
+	post("/trees/second", addDataRequest{Data: "First Leaf"}, &added)
+	et.Assert(added.Status && added.Index == 0, "The data was not added to the second tree")
+
+	first, _ := registry.Tree("first")
+	second, _ := registry.Tree("second")
+	et.Assert(first.Length() == 2 && second.Length() == 1, "The additions were not kept separately")
+
+	var hashes intermediaryHashesResponse
+	get("/trees/second/hashes/0", &hashes)
+	et.Assert(hashes.Status, "The status for getting hashes of the second tree was false")
+
+	var status treeStatusResponse
+	get("/trees/third", &status)
+	et.Assert(!status.Status, "The status for missing tree was true")
+	et.Assert(status.Error == "Incorrect tree - The tree does not exist", "The error for missing tree was not returned")
+
+	var root rootResponse
+	get("/trees/first/roots/1", &root)
+	et.Assert(!root.Status && root.Error == noRootHistory, "The error for tree without root history was not returned")
+}
+
+func TestMerkleTreeRoots(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	store := &merkletreetest.MemoryStore{}
+	tree, _ := persistent.Load(memory.NewMerkleTree(), store)
+
+	router := chi.NewRouter()
+	router.Use(
+		render.SetContentType(render.ContentTypeJSON),
+		middleware.Logger,
+		middleware.DefaultCompress,
+		middleware.RedirectSlashes,
+		middleware.Recoverer,
+	)
+	router.Route("/v1", func(r chi.Router) {
+		treeRouter := chi.NewRouter()
+		treeRouter = MerkleTreeRoots(treeRouter, store)
+		r.Mount("/api/merkletree", treeRouter)
+	})
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	get := func(path string) rootResponse {
+		resp, err := server.Client().Get(server.URL + "/v1/api/merkletree" + path)
+		assertValidResponse(et, resp, err)
+		var r rootResponse
+		err = json.NewDecoder(resp.Body).Decode(&r)
+		et.Assert(err == nil, "Error was thrown when parsing the response")
+		return r
+	}
+
+	tree.Add([]byte("First Leaf"))
+	first := tree.Root()
+	tree.Add([]byte("Second Leaf"))
+
+	r := get("/roots/1")
+	et.Assert(r.Status && r.Record.Size == 1 && r.Record.Root == first, "The root at size 1 was not returned")
+	r = get("/roots/3")
+	et.Assert(!r.Status && r.Record == nil, "Root was returned for size that was never reached")
+	r = get("/roots/abc")
+	et.Assert(!r.Status, "The status for invalid size was true")
+
+	r = get("/roots?time=" + time.Now().Add(time.Minute).Format(time.RFC3339))
+	et.Assert(r.Status && r.Record.Size == 2 && r.Record.Root == tree.Root(), "The last root was not returned for time after it")
+	r = get("/roots?time=2000-01-01T00:00:00Z")
+	et.Assert(!r.Status, "Root was returned for time before the first root")
+	r = get("/roots")
+	et.Assert(!r.Status && r.Error == "Missing time parameter", "The error for missing time was not returned")
+}
@@ -0,0 +1,45 @@
+package claimapi
+
+import (
+	"errors"
+	"github.com/LimeChain/merkletree/distributor"
+	"github.com/LimeChain/merkletree/restapi/baseapi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"net/http"
+)
+
+const invalidAccount = "Incorrect account - The account is not a hex address"
+
+// MerkleDistributorClaim takes pointer to initialized router and the distributor and exposes Rest API routes for getting of the claim of an account.
+// The proofs are positional - verify them with distributor.VerifyClaim or the Memory verifier, not the Uniswap MerkleDistributor
+func MerkleDistributorClaim(treeRouter *chi.Mux, d *distributor.Distributor) *chi.Mux {
+	treeRouter.Get("/claims/{address}", getClaimHandler(d))
+	return treeRouter
+}
+
+// claimResponse carries the proof format, as the proofs are positional and the Uniswap MerkleDistributor can not verify them
+type claimResponse struct {
+	baseapi.MerkleAPIResponse
+	MerkleRoot  string             `json:"merkleRoot,omitempty"`
+	ProofFormat string             `json:"proofFormat,omitempty"`
+	Claim       *distributor.Claim `json:"claim,omitempty"`
+}
+
+func getClaimHandler(d *distributor.Distributor) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		address := chi.URLParam(r, "address")
+		if !common.IsHexAddress(address) {
+			render.JSON(w, r, claimResponse{baseapi.MerkleAPIResponse{Status: false, Error: errors.New(invalidAccount).Error()}, "", "", nil})
+			return
+		}
+
+		claim, err := d.Claim(common.HexToAddress(address))
+		if err != nil {
+			render.JSON(w, r, claimResponse{baseapi.MerkleAPIResponse{Status: false, Error: err.Error()}, "", "", nil})
+			return
+		}
+		render.JSON(w, r, claimResponse{baseapi.MerkleAPIResponse{Status: true, Error: ""}, d.Root(), distributor.ProofFormat, claim})
+	}
+}
@@ -0,0 +1,33 @@
+package claimapi_test
+
+import (
+	"github.com/LimeChain/merkletree/distributor"
+	"github.com/LimeChain/merkletree/restapi/claimapi"
+	"github.com/go-chi/chi"
+	"log"
+	"net/http"
+	"os"
+)
+
+func Example() {
+	file, err := os.Open("balances.csv")
+	if err != nil {
+		log.Fatal(err)
+	}
+	balances, err := distributor.ReadCSV(file)
+	if err != nil {
+		log.Fatal(err)
+	}
+	d, err := distributor.New(balances)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	router := chi.NewRouter()
+	router.Route("/v1", func(r chi.Router) {
+		treeRouter := chi.NewRouter()
+		treeRouter = claimapi.MerkleDistributorClaim(treeRouter, d)
+		r.Mount("/api/airdrop", treeRouter)
+	})
+	log.Fatal(http.ListenAndServe(":8080", router))
+}
@@ -0,0 +1,66 @@
+package claimapi
+
+import (
+	"encoding/json"
+	"github.com/LimeChain/merkletree/distributor"
+	"github.com/LimeChain/merkletree/merkletreetest"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMerkleDistributorClaim(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	alice := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	bob := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	d, err := distributor.New([]distributor.Balance{{Account: alice, Amount: big.NewInt(100)}, {Account: bob, Amount: big.NewInt(200)}})
+	et.Assert(err == nil, "Error was thrown when building the distributor")
+
+	router := chi.NewRouter()
+	router.Use(
+		render.SetContentType(render.ContentTypeJSON),
+		middleware.Logger,
+		middleware.DefaultCompress,
+		middleware.RedirectSlashes,
+		middleware.Recoverer,
+	)
+	router.Route("/v1", func(r chi.Router) {
+		treeRouter := chi.NewRouter()
+		treeRouter = MerkleDistributorClaim(treeRouter, d)
+		r.Mount("/api/airdrop", treeRouter)
+	})
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	resp, err := server.Client().Get(server.URL + "/v1/api/airdrop/claims/" + bob.Hex())
+	et.Assert(err == nil, "Error was thrown by the API on Request")
+
+	var r claimResponse
+	err = json.NewDecoder(resp.Body).Decode(&r)
+	et.Assert(err == nil, "Error was thrown when parsing the response")
+	et.Assert(r.Status, "The status for getting the claim was false")
+	et.Assert(r.MerkleRoot == d.Root(), "The returned root was not the root of the distribution")
+	et.Assert(r.ProofFormat == distributor.ProofFormat, "The returned proof format was not correct", r.ProofFormat)
+	et.Assert(r.Claim.Index == 1 && r.Claim.Amount == "0xc8", "The returned claim was not the claim of the account")
+	et.Assert(distributor.VerifyClaim(r.MerkleRoot, bob, r.Claim), "The returned claim could not be verified")
+
+	resp, err = server.Client().Get(server.URL + "/v1/api/airdrop/claims/0x3333333333333333333333333333333333333333")
+	et.Assert(err == nil, "Error was thrown by the API on Request")
+
+	r = claimResponse{}
+	err = json.NewDecoder(resp.Body).Decode(&r)
+	et.Assert(err == nil, "Error was thrown when parsing the response")
+	et.Assert(!r.Status, "The status for account without claim was true")
+
+	resp, err = server.Client().Get(server.URL + "/v1/api/airdrop/claims/abc")
+	et.Assert(err == nil, "Error was thrown by the API on Request")
+
+	r = claimResponse{}
+	err = json.NewDecoder(resp.Body).Decode(&r)
+	et.Assert(err == nil, "Error was thrown when parsing the response")
+	et.Assert(!r.Status, "The status for wrong address was true")
+}
@@ -0,0 +1,45 @@
+package namespaceapi
+
+import (
+	"github.com/LimeChain/merkletree/nmt"
+	"github.com/LimeChain/merkletree/restapi/baseapi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"net/http"
+)
+
+// MerkleTreeNamespace takes pointer to initialized router and the namespaced merkle tree and exposes Rest API routes for getting of all leafs of a namespace with their proof
+func MerkleTreeNamespace(treeRouter *chi.Mux, tree *nmt.MerkleTree) *chi.Mux {
+	treeRouter.Get("/namespace/{namespace}", getNamespaceHandler(tree))
+	return treeRouter
+}
+
+type namespaceResponse struct {
+	baseapi.MerkleAPIResponse
+	Root  string     `json:"root,omitempty"`
+	Data  []string   `json:"data"`
+	Proof *nmt.Proof `json:"proof,omitempty"`
+}
+
+func getNamespaceHandler(tree *nmt.MerkleTree) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		namespace, err := hexutil.Decode(chi.URLParam(r, "namespace"))
+		if err != nil {
+			render.JSON(w, r, namespaceResponse{baseapi.MerkleAPIResponse{Status: false, Error: err.Error()}, "", nil, nil})
+			return
+		}
+
+		data, proof, root, err := tree.ProveNamespaceWithRoot(namespace)
+		if err != nil {
+			render.JSON(w, r, namespaceResponse{baseapi.MerkleAPIResponse{Status: false, Error: err.Error()}, "", nil, nil})
+			return
+		}
+
+		encoded := make([]string, len(data))
+		for i, d := range data {
+			encoded[i] = hexutil.Encode(d)
+		}
+		render.JSON(w, r, namespaceResponse{baseapi.MerkleAPIResponse{Status: true, Error: ""}, root, encoded, proof})
+	}
+}
@@ -0,0 +1,24 @@
+package namespaceapi_test
+
+import (
+	"github.com/LimeChain/merkletree/nmt"
+	"github.com/LimeChain/merkletree/restapi/baseapi"
+	"github.com/LimeChain/merkletree/restapi/namespaceapi"
+	"github.com/go-chi/chi"
+	"log"
+	"net/http"
+)
+
+func Example() {
+	tree, _ := nmt.NewMerkleTree(8)
+	router := chi.NewRouter()
+	router.Route("/v1", func(r chi.Router) {
+		treeRouter := chi.NewRouter()
+		treeRouter = baseapi.MerkleTreeStatus(treeRouter, tree)
+		treeRouter = baseapi.MerkleTreeInsert(treeRouter, tree)
+		treeRouter = baseapi.MerkleTreeHashes(treeRouter, tree)
+		treeRouter = namespaceapi.MerkleTreeNamespace(treeRouter, tree)
+		r.Mount("/api/merkletree", treeRouter)
+	})
+	log.Fatal(http.ListenAndServe(":8080", router))
+}
@@ -0,0 +1,61 @@
+package namespaceapi
+
+import (
+	"encoding/json"
+	"github.com/LimeChain/merkletree/merkletreetest"
+	"github.com/LimeChain/merkletree/nmt"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMerkleTreeNamespace(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree, _ := nmt.NewMerkleTree(2)
+	tree.Push([]byte("aafirst"))
+	tree.Push([]byte("bbsecond"))
+	tree.Push([]byte("bbthird"))
+	tree.Push([]byte("ccfourth"))
+
+	router := chi.NewRouter()
+	router.Use(
+		render.SetContentType(render.ContentTypeJSON),
+		middleware.Logger,
+		middleware.DefaultCompress,
+		middleware.RedirectSlashes,
+		middleware.Recoverer,
+	)
+	router.Route("/v1", func(r chi.Router) {
+		treeRouter := chi.NewRouter()
+		treeRouter = MerkleTreeNamespace(treeRouter, tree)
+		r.Mount("/api/merkletree", treeRouter)
+	})
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	resp, err := server.Client().Get(server.URL + "/v1/api/merkletree/namespace/" + hexutil.Encode([]byte("bb")))
+	et.Assert(err == nil, "Error was thrown by the API on Request")
+
+	var r namespaceResponse
+	err = json.NewDecoder(resp.Body).Decode(&r)
+	et.Assert(err == nil, "Error was thrown when parsing the response")
+	et.Assert(r.Status, "The status for getting the namespace was false")
+	et.Assert(len(r.Data) == 2, "Not all leafs of the namespace were returned")
+
+	data := make([][]byte, len(r.Data))
+	for i, d := range r.Data {
+		data[i], _ = hexutil.Decode(d)
+	}
+	et.Assert(nmt.VerifyNamespace(2, r.Root, []byte("bb"), data, r.Proof), "The returned namespace could not be verified")
+
+	resp, err = server.Client().Get(server.URL + "/v1/api/merkletree/namespace/0x01")
+	et.Assert(err == nil, "Error was thrown by the API on Request")
+
+	r = namespaceResponse{}
+	err = json.NewDecoder(resp.Body).Decode(&r)
+	et.Assert(err == nil, "Error was thrown when parsing the response")
+	et.Assert(!r.Status, "The status for namespace of wrong size was true")
+}
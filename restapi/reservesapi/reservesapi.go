@@ -0,0 +1,40 @@
+package reservesapi
+
+import (
+	"github.com/LimeChain/merkletree/memory"
+	"github.com/LimeChain/merkletree/restapi/baseapi"
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"net/http"
+	"strconv"
+)
+
+// MerkleSumTreeProof takes pointer to initialized router and the merkle sum tree and exposes Rest API routes for getting of inclusion proofs with the published total
+func MerkleSumTreeProof(treeRouter *chi.Mux, tree *memory.SumTree) *chi.Mux {
+	treeRouter.Get("/proof/{index}", getSumProofHandler(tree))
+	return treeRouter
+}
+
+type sumProofResponse struct {
+	baseapi.MerkleAPIResponse
+	Root  string           `json:"root,omitempty"`
+	Total string           `json:"total,omitempty"`
+	Proof *memory.SumProof `json:"proof,omitempty"`
+}
+
+func getSumProofHandler(tree *memory.SumTree) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		index, err := strconv.Atoi(chi.URLParam(r, "index"))
+		if err != nil {
+			render.JSON(w, r, sumProofResponse{baseapi.MerkleAPIResponse{Status: false, Error: err.Error()}, "", "", nil})
+			return
+		}
+
+		proof, root, total, err := tree.ProofWithTotal(index)
+		if err != nil {
+			render.JSON(w, r, sumProofResponse{baseapi.MerkleAPIResponse{Status: false, Error: err.Error()}, "", "", nil})
+			return
+		}
+		render.JSON(w, r, sumProofResponse{baseapi.MerkleAPIResponse{Status: true, Error: ""}, root, total.String(), proof})
+	}
+}
@@ -0,0 +1,22 @@
+package reservesapi_test
+
+import (
+	"github.com/LimeChain/merkletree/memory"
+	"github.com/LimeChain/merkletree/restapi/reservesapi"
+	"github.com/go-chi/chi"
+	"log"
+	"math/big"
+	"net/http"
+)
+
+func Example() {
+	tree := memory.NewSumTree()
+	tree.Add([]byte("alice"), big.NewInt(10))
+	router := chi.NewRouter()
+	router.Route("/v1", func(r chi.Router) {
+		treeRouter := chi.NewRouter()
+		treeRouter = reservesapi.MerkleSumTreeProof(treeRouter, tree)
+		r.Mount("/api/reserves", treeRouter)
+	})
+	log.Fatal(http.ListenAndServe(":8080", router))
+}
@@ -0,0 +1,67 @@
+package reservesapi
+
+import (
+	"encoding/json"
+	"github.com/LimeChain/merkletree/memory"
+	"github.com/LimeChain/merkletree/merkletreetest"
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMerkleSumTreeProof(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree := memory.NewSumTree()
+
+	router := chi.NewRouter()
+	router.Use(
+		render.SetContentType(render.ContentTypeJSON),
+		middleware.Logger,
+		middleware.DefaultCompress,
+		middleware.RedirectSlashes,
+		middleware.Recoverer,
+	)
+	router.Route("/v1", func(r chi.Router) {
+		treeRouter := chi.NewRouter()
+		treeRouter = MerkleSumTreeProof(treeRouter, tree)
+		r.Mount("/api/reserves", treeRouter)
+	})
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	resp, err := server.Client().Get(server.URL + "/v1/api/reserves/proof/0")
+	et.Assert(err == nil, "Error was thrown by the API on Request")
+
+	var r sumProofResponse
+	err = json.NewDecoder(resp.Body).Decode(&r)
+	et.Assert(err == nil, "Error was thrown when parsing the response")
+	et.Assert(!r.Status, "The status for proof of missing leaf was true")
+
+	tree.Add([]byte("alice"), big.NewInt(10))
+	tree.Add([]byte("bob"), big.NewInt(20))
+	tree.Add([]byte("carol"), big.NewInt(5))
+
+	resp, err = server.Client().Get(server.URL + "/v1/api/reserves/proof/1")
+	et.Assert(err == nil, "Error was thrown by the API on Request")
+
+	r = sumProofResponse{}
+	err = json.NewDecoder(resp.Body).Decode(&r)
+	et.Assert(err == nil, "Error was thrown when parsing the response")
+	et.Assert(r.Status, "The status for getting the proof was false")
+	et.Assert(r.Root == tree.Root(), "The returned root was not the root of the tree")
+	et.Assert(r.Total == "35", "The returned total was not the sum of the balances")
+
+	total, _ := new(big.Int).SetString(r.Total, 10)
+	et.Assert(memory.VerifySumProof([]byte("bob"), big.NewInt(20), r.Proof, r.Root, total), "The returned proof could not be verified")
+
+	resp, err = server.Client().Get(server.URL + "/v1/api/reserves/proof/abc")
+	et.Assert(err == nil, "Error was thrown by the API on Request")
+
+	r = sumProofResponse{}
+	err = json.NewDecoder(resp.Body).Decode(&r)
+	et.Assert(err == nil, "Error was thrown when parsing the response")
+	et.Assert(!r.Status, "The status for wrong index was true")
+}
@@ -3,7 +3,10 @@ package validateapi
 import (
 	"encoding/json"
 	"github.com/LimeChain/merkletree"
+	"github.com/LimeChain/merkletree/encoder"
+	"github.com/LimeChain/merkletree/indexed"
 	"github.com/LimeChain/merkletree/restapi/baseapi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/go-chi/chi"
 	"github.com/go-chi/render"
 	"net/http"
@@ -15,10 +18,22 @@ func MerkleTreeValidate(treeRouter *chi.Mux, tree merkletree.ExternalMerkleTree)
 	return treeRouter
 }
 
+// MerkleTreeValidateAbsence takes pointer to initialized router and the indexed merkle tree and exposes Rest API routes for validation of non-membership
+func MerkleTreeValidateAbsence(treeRouter *chi.Mux, tree *indexed.MerkleTree) *chi.Mux {
+	treeRouter.Post("/validate/absence", validateAbsence(tree))
+	return treeRouter
+}
+
+// validateRequest is the body of the validation. The optional salt is hex encoded and is prepended to the data of salted leafs.
+// The data of salted leafs is hex encoded as well, as in memory.Disclosure. Instead of raw data it can carry structured
+// payload and the name of the encoder that canonicalizes it, the same way as the body of the baseapi addition
 type validateRequest struct {
-	Data   string   `json:"data"`
-	Index  int      `json:"index"`
-	Hashes []string `json:"hashes"`
+	Data     string          `json:"data"`
+	Salt     string          `json:"salt,omitempty"`
+	Index    int             `json:"index"`
+	Hashes   []string        `json:"hashes"`
+	Encoding string          `json:"encoding,omitempty"`
+	Payload  json.RawMessage `json:"payload,omitempty"`
 }
 
 type validateResponse struct {
@@ -36,11 +51,30 @@ func validate(tree merkletree.ExternalMerkleTree) http.HandlerFunc {
 			return
 		}
 
-		if b.Data == "" {
+		data := []byte(b.Data)
+		if b.Encoding != "" {
+			data, err = encoder.Encode(b.Encoding, b.Payload)
+		} else if b.Salt != "" {
+			data, err = hexutil.Decode(b.Data)
+		}
+		if err != nil {
+			render.JSON(w, r, validateResponse{baseapi.MerkleAPIResponse{Status: false, Error: err.Error()}, false})
+			return
+		}
+
+		if len(data) == 0 {
 			render.JSON(w, r, validateResponse{baseapi.MerkleAPIResponse{Status: false, Error: "Missing data field"}, false})
 			return
 		}
-		exists, err := tree.ValidateExistence([]byte(b.Data), b.Index, b.Hashes)
+		if b.Salt != "" {
+			salt, err := hexutil.Decode(b.Salt)
+			if err != nil {
+				render.JSON(w, r, validateResponse{baseapi.MerkleAPIResponse{Status: false, Error: err.Error()}, false})
+				return
+			}
+			data = append(salt, data...)
+		}
+		exists, err := tree.ValidateExistence(data, b.Index, b.Hashes)
 		if err != nil {
 			render.JSON(w, r, validateResponse{baseapi.MerkleAPIResponse{Status: false, Error: err.Error()}, false})
 			return
@@ -49,3 +83,33 @@ func validate(tree merkletree.ExternalMerkleTree) http.HandlerFunc {
 		render.JSON(w, r, validateResponse{baseapi.MerkleAPIResponse{Status: true, Error: ""}, exists})
 	}
 }
+
+type validateAbsenceRequest struct {
+	Data string `json:"data"`
+	indexed.Proof
+}
+
+type validateAbsenceResponse struct {
+	baseapi.MerkleAPIResponse
+	Absent bool `json:"absent"`
+}
+
+func validateAbsence(tree *indexed.MerkleTree) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		decoder := json.NewDecoder(r.Body)
+		var b validateAbsenceRequest
+		err := decoder.Decode(&b)
+		if err != nil {
+			render.JSON(w, r, validateAbsenceResponse{baseapi.MerkleAPIResponse{Status: false, Error: err.Error()}, false})
+			return
+		}
+
+		if b.Data == "" {
+			render.JSON(w, r, validateAbsenceResponse{baseapi.MerkleAPIResponse{Status: false, Error: "Missing data field"}, false})
+			return
+		}
+		absent := indexed.VerifyNonMembership(tree.Root(), []byte(b.Data), &b.Proof)
+
+		render.JSON(w, r, validateAbsenceResponse{baseapi.MerkleAPIResponse{Status: true, Error: ""}, absent})
+	}
+}
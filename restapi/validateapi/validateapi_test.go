@@ -0,0 +1,168 @@
+package validateapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/LimeChain/merkletree/encoder"
+	"github.com/LimeChain/merkletree/indexed"
+	"github.com/LimeChain/merkletree/memory"
+	"github.com/LimeChain/merkletree/merkletreetest"
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMerkleTreeValidateAbsence(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree := indexed.NewMerkleTree()
+	tree.Add([]byte("First Key"))
+	tree.Add([]byte("Second Key"))
+
+	router := chi.NewRouter()
+	router.Use(
+		render.SetContentType(render.ContentTypeJSON),
+		middleware.Logger,
+		middleware.DefaultCompress,
+		middleware.RedirectSlashes,
+		middleware.Recoverer,
+	)
+	router.Route("/v1", func(r chi.Router) {
+		treeRouter := chi.NewRouter()
+		treeRouter = MerkleTreeValidateAbsence(treeRouter, tree)
+		r.Mount("/api/merkletree", treeRouter)
+	})
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	post := func(req validateAbsenceRequest) validateAbsenceResponse {
+		reqString, _ := json.Marshal(req)
+		resp, err := server.Client().Post(server.URL+"/v1/api/merkletree/validate/absence", "application/json", bytes.NewBuffer(reqString))
+		et.Assert(err == nil, "Error was thrown by the API on Request")
+
+		var r validateAbsenceResponse
+		err = json.NewDecoder(resp.Body).Decode(&r)
+		et.Assert(err == nil, "Error was thrown when parsing the response")
+		return r
+	}
+
+	proof, _ := tree.ProveNonMembership([]byte("Missing Key"))
+	r := post(validateAbsenceRequest{"Missing Key", *proof})
+	et.Assert(r.Status, "The status for validating absence was false")
+	et.Assert(r.Absent, "The absence of missing key was not validated")
+
+	r = post(validateAbsenceRequest{"First Key", *proof})
+	et.Assert(r.Status, "The status for validating absence was false")
+	et.Assert(!r.Absent, "The absence of present key was validated")
+
+	r = post(validateAbsenceRequest{"", *proof})
+	et.Assert(!r.Status, "The status for missing data was true")
+	et.Assert(r.Error == "Missing data field", "Incorrect message was returned for missing data")
+}
+
+func TestMerkleTreeValidateSalted(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree := memory.NewMerkleTree()
+	tree.Add([]byte("First Leaf"))
+	tree.AddSalted([]byte("Alice"))
+	d, _ := tree.AddSalted([]byte("1990-01-01"))
+
+	router := chi.NewRouter()
+	router.Use(
+		render.SetContentType(render.ContentTypeJSON),
+		middleware.Logger,
+		middleware.DefaultCompress,
+		middleware.RedirectSlashes,
+		middleware.Recoverer,
+	)
+	router.Route("/v1", func(r chi.Router) {
+		treeRouter := chi.NewRouter()
+		treeRouter = MerkleTreeValidate(treeRouter, tree)
+		r.Mount("/api/merkletree", treeRouter)
+	})
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	post := func(req interface{}) validateResponse {
+		reqString, _ := json.Marshal(req)
+		resp, err := server.Client().Post(server.URL+"/v1/api/merkletree/validate", "application/json", bytes.NewBuffer(reqString))
+		et.Assert(err == nil, "Error was thrown by the API on Request")
+
+		var r validateResponse
+		err = json.NewDecoder(resp.Body).Decode(&r)
+		et.Assert(err == nil, "Error was thrown when parsing the response")
+		return r
+	}
+
+	r := post(d)
+	et.Assert(r.Status, "The status for validating the disclosure was false")
+	et.Assert(r.Exists, "The disclosure was not validated")
+
+	r = post(validateRequest{"1990-01-01", "", d.Index, d.Hashes, "", nil})
+	et.Assert(r.Status, "The status for validating without salt was false")
+	et.Assert(!r.Exists, "The salted data was validated without salt")
+
+	hashes, _ := tree.IntermediaryHashesByIndex(0)
+	r = post(validateRequest{"First Leaf", "", 0, hashes, "", nil})
+	et.Assert(r.Status, "The status for validating unsalted data was false")
+	et.Assert(r.Exists, "The unsalted data was not validated")
+
+	r = post(validateRequest{d.Data.String(), "salt", d.Index, d.Hashes, "", nil})
+	et.Assert(!r.Status, "The status for invalid salt was true")
+
+	r = post(validateRequest{"1990-01-01", d.Salt, d.Index, d.Hashes, "", nil})
+	et.Assert(!r.Status, "The status for salted data that is not hex was true")
+}
+
+func TestMerkleTreeValidateEncoded(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree := memory.NewMerkleTree()
+	tree.Add([]byte("First Leaf"))
+	data, _ := encoder.Encode(encoder.JSON, []byte(`{"name": "Alice", "age": 30}`))
+	index, _ := tree.Add(data)
+
+	router := chi.NewRouter()
+	router.Use(
+		render.SetContentType(render.ContentTypeJSON),
+		middleware.Logger,
+		middleware.DefaultCompress,
+		middleware.RedirectSlashes,
+		middleware.Recoverer,
+	)
+	router.Route("/v1", func(r chi.Router) {
+		treeRouter := chi.NewRouter()
+		treeRouter = MerkleTreeValidate(treeRouter, tree)
+		r.Mount("/api/merkletree", treeRouter)
+	})
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	post := func(req validateRequest) validateResponse {
+		reqString, _ := json.Marshal(req)
+		resp, err := server.Client().Post(server.URL+"/v1/api/merkletree/validate", "application/json", bytes.NewBuffer(reqString))
+		et.Assert(err == nil, "Error was thrown by the API on Request")
+
+		var r validateResponse
+		err = json.NewDecoder(resp.Body).Decode(&r)
+		et.Assert(err == nil, "Error was thrown when parsing the response")
+		return r
+	}
+
+	hashes, _ := tree.IntermediaryHashesByIndex(index)
+	r := post(validateRequest{Index: index, Hashes: hashes, Encoding: encoder.JSON, Payload: []byte(`{"age": 30, "name": "Alice"}`)})
+	et.Assert(r.Status, "The status for validating encoded payload was false")
+	et.Assert(r.Exists, "The payload in other key order was not validated")
+
+	r = post(validateRequest{Index: index, Hashes: hashes, Encoding: encoder.JSON, Payload: []byte(`{"age": 31, "name": "Alice"}`)})
+	et.Assert(r.Status && !r.Exists, "Wrong payload was validated")
+
+	r = post(validateRequest{Index: index, Hashes: hashes, Encoding: "xml", Payload: []byte(`{"name": "Alice"}`)})
+	et.Assert(!r.Status, "The status for unknown encoding was true")
+
+	r = post(validateRequest{Index: index, Hashes: hashes, Encoding: encoder.JSON})
+	et.Assert(!r.Status, "The status for missing payload was true")
+
+	r = post(validateRequest{Index: index, Hashes: hashes})
+	et.Assert(!r.Status && r.Error == "Missing data field", "Incorrect message was returned for missing data")
+}
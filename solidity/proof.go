@@ -0,0 +1,83 @@
+package solidity
+
+import (
+	"errors"
+	"github.com/LimeChain/merkletree"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"math/big"
+)
+
+const (
+	negativeIndex = "Incorrect index - Index must not be negative"
+)
+
+// Proof is a proof of a single leaf in the form the generated verifiers accept it
+type Proof struct {
+	Leaf     common.Hash   `json:"leaf"`
+	Siblings []common.Hash `json:"siblings"`
+	Index    *big.Int      `json:"index"`
+}
+
+var proofArguments abi.Arguments
+
+func init() {
+	for _, t := range []string{"bytes32", "bytes32[]", "uint256"} {
+		typ, err := abi.NewType(t, "", nil)
+		if err != nil {
+			panic(err)
+		}
+		proofArguments = append(proofArguments, abi.Argument{Type: typ})
+	}
+}
+
+// NewProof returns the proof of the leaf at the given index of the tree
+func NewProof(tree merkletree.MerkleTree, index int) (*Proof, error) {
+	leaf, err := tree.HashAt(index)
+	if err != nil {
+		return nil, err
+	}
+	intermediaryHashes, err := tree.IntermediaryHashesByIndex(index)
+	if err != nil {
+		return nil, err
+	}
+	return ProofFromHashes(leaf, index, intermediaryHashes)
+}
+
+// ProofFromHashes returns the proof of the leaf hash at the given index out of the intermediary hashes returned by the trees
+func ProofFromHashes(leafHash string, index int, intermediaryHashes []string) (*Proof, error) {
+	if index < 0 {
+		return nil, errors.New(negativeIndex)
+	}
+
+	siblings := make([]common.Hash, len(intermediaryHashes))
+	for i, h := range intermediaryHashes {
+		siblings[i] = common.HexToHash(h)
+	}
+	return &Proof{common.HexToHash(leafHash), siblings, big.NewInt(int64(index))}, nil
+}
+
+// EncodeProof returns abi.encode(bytes32 leaf, bytes32[] siblings, uint256 index) of the proof
+func EncodeProof(proof *Proof) ([]byte, error) {
+	siblings := make([][32]byte, len(proof.Siblings))
+	for i, s := range proof.Siblings {
+		siblings[i] = s
+	}
+	return proofArguments.Pack([32]byte(proof.Leaf), siblings, proof.Index)
+}
+
+// DecodeProof parses proof encoded with EncodeProof
+func DecodeProof(data []byte) (*Proof, error) {
+	values, err := proofArguments.UnpackValues(data)
+	if err != nil {
+		return nil, err
+	}
+
+	leaf := values[0].([32]byte)
+	siblings := values[1].([][32]byte)
+	proof := &Proof{Leaf: leaf, Siblings: make([]common.Hash, len(siblings)), Index: values[2].(*big.Int)}
+	for i, s := range siblings {
+		proof.Siblings[i] = s
+	}
+	return proof, nil
+}
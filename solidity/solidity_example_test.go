@@ -0,0 +1,26 @@
+package solidity_test
+
+import (
+	"fmt"
+	"github.com/LimeChain/merkletree/memory"
+	"github.com/LimeChain/merkletree/solidity"
+	"strings"
+)
+
+func Example() {
+	source, _ := solidity.GenerateVerifier(solidity.Options{Mode: solidity.Memory})
+	fmt.Println(strings.Contains(source, "library MerkleVerifier"))
+
+	tree := memory.NewMerkleTree()
+	tree.Add([]byte("hello"))
+	tree.Add([]byte("world"))
+	tree.Add([]byte("Merkle Trees Rock"))
+
+	proof, _ := solidity.NewProof(tree, 2)
+	encoded, _ := solidity.EncodeProof(proof)
+	fmt.Printf("Siblings: %v, Encoded Bytes: %v\n", len(proof.Siblings), len(encoded))
+
+	// Output:
+	// true
+	// Siblings: 2, Encoded Bytes: 192
+}
@@ -0,0 +1,170 @@
+package solidity
+
+import (
+	"encoding/hex"
+	"github.com/LimeChain/merkletree/kary"
+	"github.com/LimeChain/merkletree/memory"
+	"github.com/LimeChain/merkletree/merkletreetest"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/crypto"
+	"io/ioutil"
+	"math/big"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// The harness in testdata exposes the generated libraries. Its bytecode is compiled with solc 0.8.21 for the istanbul EVM,
+// the last fork supported by the simulated backend:
+//
+//	solc --optimize --evm-version istanbul --bin --abi VerifierHarness.sol
+//
+// TestGenerateVerifier keeps the libraries in testdata in sync with the generator, so the bytecode is of the current output
+func readTestdata(et *merkletreetest.ExtendedTesting, name string) string {
+	data, err := ioutil.ReadFile("testdata/" + name)
+	if err != nil {
+		et.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestGenerateVerifier(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	source, err := GenerateVerifier(Options{})
+	et.Assert(err == nil, "Error was thrown on generating the memory verifier")
+	et.Assert(source == readTestdata(et, "MerkleVerifier.sol"), "Memory verifier differs from testdata/MerkleVerifier.sol")
+
+	source, err = GenerateVerifier(Options{Mode: Kary, Arity: 4, Name: "KaryMerkleVerifier"})
+	et.Assert(err == nil, "Error was thrown on generating the kary verifier")
+	et.Assert(source == readTestdata(et, "KaryMerkleVerifier.sol"), "Kary verifier differs from testdata/KaryMerkleVerifier.sol")
+
+	_, err = GenerateVerifier(Options{Mode: Kary, Arity: 1})
+	et.Assert(err != nil, "Kary verifier with arity 1 did not return error")
+	_, err = GenerateVerifier(Options{Name: "Merkle Verifier"})
+	et.Assert(err != nil, "Verifier with invalid name did not return error")
+	_, err = GenerateVerifier(Options{Mode: Mode(42)})
+	et.Assert(err != nil, "Unknown mode did not return error")
+}
+
+func TestEncodeProof(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	proof := &Proof{common.Hash{1}, []common.Hash{{2}, {3}}, big.NewInt(5)}
+	encoded, err := EncodeProof(proof)
+	et.Assert(err == nil, "Error was thrown on encoding the proof")
+
+	word := func(s string) string {
+		return strings.Repeat("0", 64-len(s)) + s
+	}
+	expected := common.Hash{1}.Hex()[2:] + // leaf
+		word("60") + // offset of the siblings
+		word("5") + // index
+		word("2") + common.Hash{2}.Hex()[2:] + common.Hash{3}.Hex()[2:]
+	et.Assert(hex.EncodeToString(encoded) == expected, "Encoded proof was not correct", hex.EncodeToString(encoded))
+
+	decoded, err := DecodeProof(encoded)
+	et.Assert(err == nil, "Error was thrown on decoding the proof")
+	et.Assert(decoded.Leaf == proof.Leaf && len(decoded.Siblings) == 2, "Decoded proof was not correct", decoded)
+	et.Assert(decoded.Siblings[1] == proof.Siblings[1] && decoded.Index.Cmp(proof.Index) == 0, "Decoded proof was not correct", decoded)
+
+	_, err = DecodeProof(encoded[:64])
+	et.Assert(err != nil, "Decoding short proof did not return error")
+}
+
+type harness struct {
+	contract *bind.BoundContract
+}
+
+func deployHarness(et *merkletreetest.ExtendedTesting) *harness {
+	parsed, err := abi.JSON(strings.NewReader(readTestdata(et, "VerifierHarness.abi")))
+	if err != nil {
+		et.Fatal(err)
+	}
+
+	key, _ := crypto.GenerateKey()
+	auth := bind.NewKeyedTransactor(key)
+	backend := backends.NewSimulatedBackend(core.GenesisAlloc{auth.From: {Balance: big.NewInt(1e18)}}, 8000000)
+
+	_, _, contract, err := bind.DeployContract(auth, parsed, common.FromHex(readTestdata(et, "VerifierHarness.bin")), backend)
+	if err != nil {
+		et.Fatal(err)
+	}
+	backend.Commit()
+
+	return &harness{contract}
+}
+
+func (h *harness) call(et *merkletreetest.ExtendedTesting, method string, params ...interface{}) bool {
+	var result bool
+	if err := h.contract.Call(nil, &result, method, params...); err != nil {
+		et.Fatalf("Calling %v returned %v", method, err)
+	}
+	return result
+}
+
+func siblings(proof *Proof) [][32]byte {
+	res := make([][32]byte, len(proof.Siblings))
+	for i, s := range proof.Siblings {
+		res[i] = s
+	}
+	return res
+}
+
+func TestMemoryVerifier(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	h := deployHarness(et)
+
+	for _, size := range []int{1, 2, 3, 5, 8, 13} {
+		tree := memory.NewMerkleTree()
+		for i := 0; i < size; i++ {
+			tree.Add([]byte("hello" + strconv.Itoa(i)))
+		}
+		root := common.HexToHash(tree.Root())
+
+		for i := 0; i < size; i++ {
+			proof, err := NewProof(tree, i)
+			et.Assert(err == nil, "Error was thrown on creating the proof", i, size)
+			encoded, _ := EncodeProof(proof)
+
+			et.Assert(h.call(et, "verify", [32]byte(root), [32]byte(proof.Leaf), siblings(proof), proof.Index), "Proof was not verified", i, size)
+			et.Assert(h.call(et, "verifyProof", [32]byte(root), encoded), "Encoded proof was not verified", i, size)
+			et.Assert(h.call(et, "verifyData", [32]byte(root), []byte("hello"+strconv.Itoa(i)), siblings(proof), proof.Index), "Data was not verified", i, size)
+			et.Assert(!h.call(et, "verifyData", [32]byte(root), []byte("world"), siblings(proof), proof.Index), "Wrong data was verified", i, size)
+
+			// Setting a bit above the last level leaves the index non zero after the last level
+			width := new(big.Int).Lsh(big.NewInt(1), uint(len(proof.Siblings)))
+			proof.Index.Add(proof.Index, width)
+			encoded, _ = EncodeProof(proof)
+			et.Assert(!h.call(et, "verifyProof", [32]byte(root), encoded), "Proof was verified at wrong index", i, size)
+		}
+	}
+}
+
+func TestKaryVerifier(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	h := deployHarness(et)
+
+	for _, size := range []int{1, 4, 5, 16, 21} {
+		tree, _ := kary.NewMerkleTree(4)
+		for i := 0; i < size; i++ {
+			tree.Add([]byte("hello" + strconv.Itoa(i)))
+		}
+		root := common.HexToHash(tree.Root())
+
+		for i := 0; i < size; i++ {
+			proof, err := NewProof(tree, i)
+			et.Assert(err == nil, "Error was thrown on creating the proof", i, size)
+			encoded, _ := EncodeProof(proof)
+			et.Assert(h.call(et, "verifyKary", [32]byte(root), encoded), "Proof was not verified", i, size)
+
+			// Moving the index past the last leaf of the tree leaves it non zero after the last level
+			width := new(big.Int).Exp(big.NewInt(4), big.NewInt(int64(len(proof.Siblings)/3)), nil)
+			proof.Index.Add(proof.Index, width)
+			encoded, _ = EncodeProof(proof)
+			et.Assert(!h.call(et, "verifyKary", [32]byte(root), encoded), "Proof was verified at wrong index", i, size)
+		}
+	}
+}
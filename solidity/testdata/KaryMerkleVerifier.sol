@@ -0,0 +1,47 @@
+// SPDX-License-Identifier: MIT
+// Code generated by github.com/LimeChain/merkletree/solidity. DO NOT EDIT.
+pragma solidity ^0.8.0;
+
+/// @title KaryMerkleVerifier
+/// @notice Verifies proofs of github.com/LimeChain/merkletree/kary.MerkleTree with arity 4.
+/// Leafs are the keccak256 hashes of the data and every parent is keccak256 of the concatenation of its 4 children.
+/// Incomplete groups at the right edge are padded with their last node.
+/// Every level of the proof holds the 4 - 1 siblings of the node in the order they appear in their group
+library KaryMerkleVerifier {
+    uint256 internal constant ARITY = 4;
+
+    function verify(bytes32 root, bytes32 leaf, bytes32[] memory siblings, uint256 index) internal pure returns (bool) {
+        if (siblings.length % (ARITY - 1) != 0) {
+            return false;
+        }
+
+        bytes32 hash = leaf;
+        bytes32[4] memory group;
+        for (uint256 i = 0; i < siblings.length; i += ARITY - 1) {
+            uint256 position = index % ARITY;
+            uint256 s = i;
+            for (uint256 j = 0; j < ARITY; j++) {
+                if (j == position) {
+                    group[j] = hash;
+                } else {
+                    group[j] = siblings[s];
+                    s++;
+                }
+            }
+            hash = keccak256(abi.encodePacked(group));
+            index /= ARITY;
+        }
+        return index == 0 && hash == root;
+    }
+
+    /// @notice Verifies ABI encoded proof (bytes32 leaf, bytes32[] siblings, uint256 index) as produced by solidity.EncodeProof
+    function verifyProof(bytes32 root, bytes memory proof) internal pure returns (bool) {
+        (bytes32 leaf, bytes32[] memory siblings, uint256 index) = abi.decode(proof, (bytes32, bytes32[], uint256));
+        return verify(root, leaf, siblings, index);
+    }
+
+    /// @notice Verifies that the data is at the index of the tree with the given root
+    function verifyData(bytes32 root, bytes memory data, bytes32[] memory siblings, uint256 index) internal pure returns (bool) {
+        return verify(root, keccak256(data), siblings, index);
+    }
+}
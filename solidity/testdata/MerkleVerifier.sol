@@ -0,0 +1,35 @@
+// SPDX-License-Identifier: MIT
+// Code generated by github.com/LimeChain/merkletree/solidity. DO NOT EDIT.
+pragma solidity ^0.8.0;
+
+/// @title MerkleVerifier
+/// @notice Verifies proofs of github.com/LimeChain/merkletree/memory.MerkleTree.
+/// Leafs are the keccak256 hashes of the data and every parent is keccak256(left || right) of its two children.
+/// The last node of a level with odd count of nodes is paired with itself, so a sibling may equal the node it is hashed with.
+/// The siblings go from the leaf up to the root. As in memory.Verify, bit i of the index tells whether the node on level i is a right child.
+/// Indexes with bits above the last level are rejected, so a leaf verifies only at its own index
+library MerkleVerifier {
+    function verify(bytes32 root, bytes32 leaf, bytes32[] memory siblings, uint256 index) internal pure returns (bool) {
+        bytes32 hash = leaf;
+        for (uint256 i = 0; i < siblings.length; i++) {
+            if (index % 2 == 0) {
+                hash = keccak256(abi.encodePacked(hash, siblings[i]));
+            } else {
+                hash = keccak256(abi.encodePacked(siblings[i], hash));
+            }
+            index /= 2;
+        }
+        return index == 0 && hash == root;
+    }
+
+    /// @notice Verifies ABI encoded proof (bytes32 leaf, bytes32[] siblings, uint256 index) as produced by solidity.EncodeProof
+    function verifyProof(bytes32 root, bytes memory proof) internal pure returns (bool) {
+        (bytes32 leaf, bytes32[] memory siblings, uint256 index) = abi.decode(proof, (bytes32, bytes32[], uint256));
+        return verify(root, leaf, siblings, index);
+    }
+
+    /// @notice Verifies that the data is at the index of the tree with the given root
+    function verifyData(bytes32 root, bytes memory data, bytes32[] memory siblings, uint256 index) internal pure returns (bool) {
+        return verify(root, keccak256(data), siblings, index);
+    }
+}
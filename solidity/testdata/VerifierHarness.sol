@@ -0,0 +1,24 @@
+// SPDX-License-Identifier: MIT
+pragma solidity ^0.8.0;
+
+import "./MerkleVerifier.sol";
+import "./KaryMerkleVerifier.sol";
+
+// Exposes the generated libraries to the tests of package solidity
+contract VerifierHarness {
+    function verify(bytes32 root, bytes32 leaf, bytes32[] calldata siblings, uint256 index) external pure returns (bool) {
+        return MerkleVerifier.verify(root, leaf, siblings, index);
+    }
+
+    function verifyProof(bytes32 root, bytes calldata proof) external pure returns (bool) {
+        return MerkleVerifier.verifyProof(root, proof);
+    }
+
+    function verifyData(bytes32 root, bytes calldata data, bytes32[] calldata siblings, uint256 index) external pure returns (bool) {
+        return MerkleVerifier.verifyData(root, data, siblings, index);
+    }
+
+    function verifyKary(bytes32 root, bytes calldata proof) external pure returns (bool) {
+        return KaryMerkleVerifier.verifyProof(root, proof);
+    }
+}
@@ -0,0 +1,142 @@
+// Package solidity generates Solidity libraries that verify the proofs of the trees in this module on chain,
+// and encodes the proofs the way these libraries expect them
+package solidity
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"text/template"
+)
+
+const (
+	invalidMode  = "Incorrect mode - The mode is not supported"
+	invalidArity = "Incorrect arity - Arity must be at least 2"
+	invalidName  = "Incorrect name - The library name is not a Solidity identifier"
+)
+
+// Mode selects the tree whose pairing rules the generated verifier follows
+type Mode int
+
+const (
+	// Memory verifies the proofs of memory.MerkleTree and the trees built on it
+	Memory Mode = iota
+	// Kary verifies the proofs of kary.MerkleTree with the arity given in the Options
+	Kary
+)
+
+// DefaultName is the name of the generated library when Options.Name is empty
+const DefaultName = "MerkleVerifier"
+
+// Options configure the generated verifier
+type Options struct {
+	Mode  Mode
+	Arity int
+	Name  string
+}
+
+var identifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+const header = `// SPDX-License-Identifier: MIT
+// Code generated by github.com/LimeChain/merkletree/solidity. DO NOT EDIT.
+pragma solidity ^0.8.0;
+`
+
+const decode = `
+    /// @notice Verifies ABI encoded proof (bytes32 leaf, bytes32[] siblings, uint256 index) as produced by solidity.EncodeProof
+    function verifyProof(bytes32 root, bytes memory proof) internal pure returns (bool) {
+        (bytes32 leaf, bytes32[] memory siblings, uint256 index) = abi.decode(proof, (bytes32, bytes32[], uint256));
+        return verify(root, leaf, siblings, index);
+    }
+
+    /// @notice Verifies that the data is at the index of the tree with the given root
+    function verifyData(bytes32 root, bytes memory data, bytes32[] memory siblings, uint256 index) internal pure returns (bool) {
+        return verify(root, keccak256(data), siblings, index);
+    }
+}
+`
+
+var memoryTemplate = template.Must(template.New("memory").Parse(header + `
+/// @title {{.Name}}
+/// @notice Verifies proofs of github.com/LimeChain/merkletree/memory.MerkleTree.
+/// Leafs are the keccak256 hashes of the data and every parent is keccak256(left || right) of its two children.
+/// The last node of a level with odd count of nodes is paired with itself, so a sibling may equal the node it is hashed with.
+/// The siblings go from the leaf up to the root. As in memory.Verify, bit i of the index tells whether the node on level i is a right child.
+/// Indexes with bits above the last level are rejected, so a leaf verifies only at its own index
+library {{.Name}} {
+    function verify(bytes32 root, bytes32 leaf, bytes32[] memory siblings, uint256 index) internal pure returns (bool) {
+        bytes32 hash = leaf;
+        for (uint256 i = 0; i < siblings.length; i++) {
+            if (index % 2 == 0) {
+                hash = keccak256(abi.encodePacked(hash, siblings[i]));
+            } else {
+                hash = keccak256(abi.encodePacked(siblings[i], hash));
+            }
+            index /= 2;
+        }
+        return index == 0 && hash == root;
+    }
+` + decode))
+
+var karyTemplate = template.Must(template.New("kary").Parse(header + `
+/// @title {{.Name}}
+/// @notice Verifies proofs of github.com/LimeChain/merkletree/kary.MerkleTree with arity {{.Arity}}.
+/// Leafs are the keccak256 hashes of the data and every parent is keccak256 of the concatenation of its {{.Arity}} children.
+/// Incomplete groups at the right edge are padded with their last node.
+/// Every level of the proof holds the {{.Arity}} - 1 siblings of the node in the order they appear in their group
+library {{.Name}} {
+    uint256 internal constant ARITY = {{.Arity}};
+
+    function verify(bytes32 root, bytes32 leaf, bytes32[] memory siblings, uint256 index) internal pure returns (bool) {
+        if (siblings.length % (ARITY - 1) != 0) {
+            return false;
+        }
+
+        bytes32 hash = leaf;
+        bytes32[{{.Arity}}] memory group;
+        for (uint256 i = 0; i < siblings.length; i += ARITY - 1) {
+            uint256 position = index % ARITY;
+            uint256 s = i;
+            for (uint256 j = 0; j < ARITY; j++) {
+                if (j == position) {
+                    group[j] = hash;
+                } else {
+                    group[j] = siblings[s];
+                    s++;
+                }
+            }
+            hash = keccak256(abi.encodePacked(group));
+            index /= ARITY;
+        }
+        return index == 0 && hash == root;
+    }
+` + decode))
+
+// GenerateVerifier returns the source of a Solidity library that verifies the proofs of the selected tree
+func GenerateVerifier(options Options) (string, error) {
+	if options.Name == "" {
+		options.Name = DefaultName
+	}
+	if !identifier.MatchString(options.Name) {
+		return "", errors.New(invalidName)
+	}
+
+	var tmpl *template.Template
+	switch options.Mode {
+	case Memory:
+		tmpl = memoryTemplate
+	case Kary:
+		if options.Arity < 2 {
+			return "", errors.New(invalidArity)
+		}
+		tmpl = karyTemplate
+	default:
+		return "", errors.New(invalidMode)
+	}
+
+	b := bytes.Buffer{}
+	if err := tmpl.Execute(&b, options); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
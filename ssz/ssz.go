@@ -0,0 +1,124 @@
+// Package ssz implements the merkleization of SimpleSerialize, the serialization of the Ethereum beacon chain.
+// Unlike memory.MerkleTree the chunks are padded with zero chunks up to the next power of two of their limit
+// and lists mix their length into the root. Nodes are addressed by generalized index - the root is 1 and
+// the children of node i are 2i and 2i + 1 - which is also how the proofs are requested
+package ssz
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"github.com/ethereum/go-ethereum/common"
+	"math/bits"
+)
+
+const (
+	// BytesPerChunk is the size of a single chunk
+	BytesPerChunk = 32
+	// MaxDepth is the maximum depth of the merkleized chunks
+	MaxDepth = 64
+)
+
+const (
+	limitExceeded = "Incorrect data - The count of chunks exceeds the limit"
+)
+
+var zeroHashes [MaxDepth + 1]common.Hash
+
+func init() {
+	for i := 1; i < len(zeroHashes); i++ {
+		zeroHashes[i] = hash(zeroHashes[i-1], zeroHashes[i-1])
+	}
+}
+
+func hash(left, right common.Hash) common.Hash {
+	return sha256.Sum256(append(left[:], right[:]...))
+}
+
+// ZeroHash returns the root of a tree of the given depth with only zero chunks
+func ZeroHash(depth int) common.Hash {
+	return zeroHashes[depth]
+}
+
+// depth returns the depth of the tree of the given count of chunks padded to the next power of two
+func depth(limit uint64) int {
+	if limit <= 1 {
+		return 0
+	}
+	return bits.Len64(limit - 1)
+}
+
+// Pack splits the data into chunks, right padding the last one with zeros
+func Pack(data []byte) []common.Hash {
+	chunks := make([]common.Hash, (len(data)+BytesPerChunk-1)/BytesPerChunk)
+	for i := range chunks {
+		copy(chunks[i][:], data[i*BytesPerChunk:])
+	}
+	return chunks
+}
+
+// MixInLength returns the root of a list - the hash of the root of its chunks and its length
+func MixInLength(root common.Hash, length uint64) common.Hash {
+	var chunk common.Hash
+	binary.LittleEndian.PutUint64(chunk[:], length)
+	return hash(root, chunk)
+}
+
+// Merkleize returns the root of the chunks padded with zero chunks to the next power of two of the limit
+func Merkleize(chunks []common.Hash, limit uint64) (common.Hash, error) {
+	if uint64(len(chunks)) > limit {
+		return common.Hash{}, errors.New(limitExceeded)
+	}
+	tree := &MerkleTree{Nodes: [][]common.Hash{chunks}, Depth: depth(limit), Limit: limit}
+	tree.recalculate()
+	return tree.dataRoot(), nil
+}
+
+// HashTreeRootVector returns the hash_tree_root of a vector of chunks
+func HashTreeRootVector(chunks []common.Hash) common.Hash {
+	root, _ := Merkleize(chunks, uint64(len(chunks)))
+	return root
+}
+
+// HashTreeRootList returns the hash_tree_root of a list of chunks with the given maximum length
+func HashTreeRootList(chunks []common.Hash, limit uint64) (common.Hash, error) {
+	root, err := Merkleize(chunks, limit)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return MixInLength(root, uint64(len(chunks))), nil
+}
+
+// HashTreeRootByteVector returns the hash_tree_root of a vector of bytes
+func HashTreeRootByteVector(data []byte) common.Hash {
+	return HashTreeRootVector(Pack(data))
+}
+
+// HashTreeRootByteList returns the hash_tree_root of a list of bytes with the given maximum length in bytes
+func HashTreeRootByteList(data []byte, maxLength uint64) (common.Hash, error) {
+	root, err := Merkleize(Pack(data), (maxLength+BytesPerChunk-1)/BytesPerChunk)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return MixInLength(root, uint64(len(data))), nil
+}
+
+// VerifyProof validates that the leaf is the node at the generalized index of the tree with the given root,
+// using the sibling hashes from the bottom up as returned by Proof
+func VerifyProof(root string, gindex uint64, leaf string, branch []string) bool {
+	if gindex == 0 || bits.Len64(gindex)-1 != len(branch) {
+		return false
+	}
+
+	node := common.HexToHash(leaf)
+	for _, h := range branch {
+		if gindex&1 == 1 {
+			node = hash(common.HexToHash(h), node)
+		} else {
+			node = hash(node, common.HexToHash(h))
+		}
+		gindex >>= 1
+	}
+
+	return node == common.HexToHash(root)
+}
@@ -0,0 +1,25 @@
+package ssz_test
+
+import (
+	"fmt"
+	"github.com/LimeChain/merkletree/ssz"
+	"strconv"
+)
+
+func Example() {
+	list, _ := ssz.NewList(1024)
+	for i := 0; i < 100; i++ {
+		list.Add([]byte("hello" + strconv.Itoa(i)))
+	}
+
+	gindex := list.GeneralizedIndex(42)
+	leaf, branch, _ := list.Proof(gindex)
+	fmt.Printf("Generalized Index: %v\n", gindex)
+	fmt.Printf("Hashes: %v\n", len(branch))
+	fmt.Printf("Proof Valid: %v\n", ssz.VerifyProof(list.Root(), gindex, leaf, branch))
+
+	// Output:
+	// Generalized Index: 2090
+	// Hashes: 11
+	// Proof Valid: true
+}
@@ -0,0 +1,199 @@
+package ssz
+
+import (
+	"crypto/sha256"
+	"github.com/LimeChain/merkletree"
+	"github.com/LimeChain/merkletree/merkletreetest"
+	"github.com/ethereum/go-ethereum/common"
+	"strconv"
+	"testing"
+)
+
+// naiveMerkleize pads the chunks with zero chunks to the next power of two of the limit and hashes them level by level
+func naiveMerkleize(chunks []common.Hash, limit uint64) common.Hash {
+	size := uint64(1)
+	for size < limit {
+		size *= 2
+	}
+	level := make([]common.Hash, size)
+	copy(level, chunks)
+	for len(level) > 1 {
+		next := make([]common.Hash, len(level)/2)
+		for i := range next {
+			next[i] = sha256.Sum256(append(level[2*i][:], level[2*i+1][:]...))
+		}
+		level = next
+	}
+	return level[0]
+}
+
+func chunks(n int) []common.Hash {
+	res := make([]common.Hash, n)
+	for i := range res {
+		res[i] = sha256.Sum256([]byte(strconv.Itoa(i)))
+	}
+	return res
+}
+
+func TestZeroHash(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	expected := "0xf5a5fd42d16a20302798ef6ed309979b43003d2320d9f0e8ea9831a92759fb4b"
+	et.Assert(ZeroHash(1).Hex() == expected, "Zero hash of depth 1 was not correct", ZeroHash(1).Hex())
+	expected = "0xdb56114e00fdd4c1f85c892bf35ac9a89289aaecb1ebd0a96cde606a748b5d71"
+	et.Assert(ZeroHash(2).Hex() == expected, "Zero hash of depth 2 was not correct", ZeroHash(2).Hex())
+}
+
+func TestHashTreeRootList(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	// Root of the deposit contract without deposits - a list of at most 2^32 chunks
+	root, err := HashTreeRootList(nil, 1<<32)
+	et.Assert(err == nil, "Error was thrown on empty list")
+	expected := "0xd70a234731285c6804c2a4f56711ddb8c82c99740f207854891028af34e27e5e"
+	et.Assert(root.Hex() == expected, "Root of empty deposit list was not correct", root.Hex())
+
+	for _, limit := range []uint64{1, 2, 3, 8, 13, 100} {
+		for n := 0; uint64(n) <= limit && n < 20; n++ {
+			data := chunks(n)
+			root, err := HashTreeRootList(data, limit)
+			et.Assert(err == nil, "Error was thrown on list", n, limit)
+			et.Assert(root == MixInLength(naiveMerkleize(data, limit), uint64(n)), "Root of list was not correct", n, limit)
+		}
+	}
+
+	_, err = HashTreeRootList(chunks(3), 2)
+	et.Assert(err != nil, "List over its limit did not return error")
+}
+
+func TestHashTreeRootVector(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	for n := 1; n < 20; n++ {
+		data := chunks(n)
+		et.Assert(HashTreeRootVector(data) == naiveMerkleize(data, uint64(n)), "Root of vector was not correct", n)
+	}
+}
+
+func TestHashTreeRootBytes(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	data := make([]byte, 32)
+	data[0] = 1
+	et.Assert(HashTreeRootByteVector(data) == common.BytesToHash(data), "Root of 32 byte vector was not the data itself")
+
+	data = []byte("SimpleSerialize merkleization of a byte list")
+	packed := Pack(data)
+	et.Assert(len(packed) == 2, "Incorrect count of packed chunks", len(packed))
+	et.Assert(packed[1][len(data)-32] == 0 && packed[1][len(data)-33] == data[len(data)-1], "Data was not packed correctly")
+
+	root, err := HashTreeRootByteList(data, 256)
+	et.Assert(err == nil, "Error was thrown on byte list")
+	et.Assert(root == MixInLength(naiveMerkleize(packed, 8), uint64(len(data))), "Root of byte list was not correct")
+
+	tree, _ := NewByteList(data, 256)
+	et.Assert(tree.Root() == root.Hex(), "Root of byte list tree was not correct")
+
+	_, err = NewByteList(data, 10)
+	et.Assert(err != nil, "Byte list over its limit did not return error")
+}
+
+func TestInsert(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree, _ := NewList(13)
+	data := chunks(13)
+	for i, chunk := range data {
+		tree.Insert(chunk.Hex())
+		expected, _ := HashTreeRootList(data[:i+1], 13)
+		et.Assert(tree.Root() == expected.Hex(), "Root after insert was not correct", i+1, tree.Root())
+	}
+
+	et.Assert(tree.Insert(data[0].Hex()) == -1, "Insert over the limit did not return -1")
+
+	tree.Truncate(5)
+	expected, _ := HashTreeRootList(data[:5], 13)
+	et.Assert(tree.Root() == expected.Hex(), "Root after truncate was not correct")
+
+	raw, _ := NewList(13)
+	for _, chunk := range data[:5] {
+		raw.RawInsert(chunk.Hex())
+	}
+	et.Assert(raw.Recalculate() == expected.Hex(), "Root after recalculate was not correct")
+}
+
+func TestAppend(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree, _ := NewList(2)
+	_, isAppender := interface{}(tree).(merkletree.Appender)
+	et.Assert(isAppender, "The tree did not implement the Appender interface")
+
+	index, hash, err := tree.Append([]byte("0"))
+	et.Assert(err == nil && index == 0, "Chunk was not appended")
+	et.Assert(hash == chunks(1)[0].Hex(), "The chunk was not the sha256 hash of the data")
+	index, _, err = tree.RawAppend([]byte("1"))
+	et.Assert(err == nil && index == 1, "Chunk was not raw appended")
+	expected, _ := HashTreeRootList(chunks(2), 2)
+	et.Assert(tree.Recalculate() == expected.Hex(), "Root after appending was not correct")
+
+	index, hash, err = tree.Append([]byte("2"))
+	et.Assert(err != nil && err.Error() == limitExceeded, "The error of full list was not returned")
+	et.Assert(index == -1 && hash == "", "Chunk was appended to full list")
+	_, _, err = tree.RawAppend([]byte("2"))
+	et.Assert(err != nil && err.Error() == limitExceeded, "The error of full list was not returned on raw append")
+
+	vector, _ := NewVector(chunks(2))
+	_, _, err = vector.Append([]byte("2"))
+	et.Assert(err != nil && err.Error() == fixedLength, "The error of appending to vector was not returned")
+	index, hash = vector.Add([]byte("2"))
+	et.Assert(index == -1 && hash == "", "Chunk was added to vector")
+}
+
+func TestProof(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	list, _ := NewList(16)
+	for i := 0; i < 11; i++ {
+		list.Add([]byte(strconv.Itoa(i)))
+	}
+	vector, _ := NewVector(chunks(11))
+
+	for _, tree := range []*MerkleTree{list, vector} {
+		root := tree.Root()
+		for i := 0; i < 11; i++ {
+			gindex := tree.GeneralizedIndex(i)
+			leaf, branch, err := tree.Proof(gindex)
+			et.Assert(err == nil, "Error was thrown on proof of chunk", i)
+			chunk, _ := tree.HashAt(i)
+			et.Assert(leaf == chunk, "Leaf of chunk was not correct", i)
+			et.Assert(VerifyProof(root, gindex, leaf, branch), "Proof of chunk did not verify", i)
+			et.Assert(!VerifyProof(root, gindex+1, leaf, branch), "Proof of chunk verified at wrong generalized index", i)
+		}
+
+		// Padding chunks and inner nodes are provable as well
+		for _, gindex := range []uint64{1, 2, 3, 5, tree.GeneralizedIndex(15)} {
+			leaf, branch, err := tree.Proof(gindex)
+			et.Assert(err == nil, "Error was thrown on proof of generalized index", gindex)
+			et.Assert(VerifyProof(root, gindex, leaf, branch), "Proof of generalized index did not verify", gindex)
+		}
+	}
+
+	// The length of the list is the right child of the root
+	leaf, _, _ := list.Proof(3)
+	et.Assert(common.HexToHash(leaf)[0] == 11, "Length chunk was not correct")
+
+	_, _, err := list.Proof(7)
+	et.Assert(err != nil, "Proof below the length chunk did not return error")
+	_, _, err = vector.Proof(vector.GeneralizedIndex(0) * 2)
+	et.Assert(err != nil, "Proof below a chunk did not return error")
+}
+
+func TestValidateExistence(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree, _ := NewList(1024)
+	for i := 0; i < 100; i++ {
+		tree.Add([]byte(strconv.Itoa(i)))
+	}
+
+	hashes, _ := tree.IntermediaryHashesByIndex(42)
+	et.Assert(len(hashes) == 11, "Proof length was not correct", len(hashes))
+
+	ok, err := tree.ValidateExistence([]byte("42"), 42, hashes)
+	et.Assert(err == nil && ok, "Data did not validate")
+	ok, _ = tree.ValidateExistence([]byte("43"), 42, hashes)
+	et.Assert(!ok, "Wrong data validated")
+}
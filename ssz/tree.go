@@ -0,0 +1,419 @@
+package ssz
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/LimeChain/merkletree"
+	"github.com/ethereum/go-ethereum/common"
+	"math/bits"
+	"strings"
+	"sync"
+)
+
+const (
+	outOfBounds   = "Incorrect index - Index out of bounds"
+	invalidSize   = "Incorrect size - Size out of bounds"
+	invalidGindex = "Incorrect generalized index - The index is not a node of the tree"
+	limitTooBig   = "Incorrect limit - The limit does not allow generalized indexes to fit in 64 bits"
+	fixedLength   = "Incorrect data - Chunks can not be added to a vector or a byte list"
+	maxProofDepth = 62
+)
+
+// Node is implementation of types.Node and representation of a single chunk in the tree
+type Node struct {
+	hash  common.Hash
+	index int
+}
+
+// Hash returns the string representation of the chunk
+func (node *Node) Hash() string {
+	return node.hash.Hex()
+}
+
+// Index returns the index of this chunk
+func (node *Node) Index() int {
+	return node.index
+}
+
+// String returns the hash of this node. Alias to Hash()
+func (node Node) String() string {
+	return node.Hash()
+}
+
+// MerkleTree is the merkleization of a list or vector of chunks. Nodes keeps only the levels up to the last chunk,
+// everything to the right of it is implied to be the zero hash of its level. Lists accept new chunks up to their Limit,
+// vectors and byte lists are fixed once created
+type MerkleTree struct {
+	Nodes  [][]common.Hash
+	Depth  int
+	Limit  uint64
+	list   bool
+	fixed  bool
+	length uint64
+	Mutex  sync.RWMutex
+}
+
+func (tree *MerkleTree) updateParent(level int, parent int) {
+	nodes := tree.Nodes[level]
+	right := zeroHashes[level]
+	if parent*2+1 < len(nodes) {
+		right = nodes[parent*2+1]
+	}
+	h := hash(nodes[parent*2], right)
+
+	if parent == len(tree.Nodes[level+1]) {
+		tree.Nodes[level+1] = append(tree.Nodes[level+1], h)
+	} else {
+		tree.Nodes[level+1][parent] = h
+	}
+}
+
+func (tree *MerkleTree) recalculate() {
+	tree.Nodes = append(tree.Nodes[:1], make([][]common.Hash, tree.Depth)...)
+	for level := 0; level < tree.Depth; level++ {
+		tree.Nodes[level+1] = make([]common.Hash, 0, (len(tree.Nodes[level])+1)/2)
+		for parent := 0; parent*2 < len(tree.Nodes[level]); parent++ {
+			tree.updateParent(level, parent)
+		}
+	}
+}
+
+func (tree *MerkleTree) propagateChange() {
+	for level := 0; level < tree.Depth && len(tree.Nodes[level]) > 0; level++ {
+		tree.updateParent(level, (len(tree.Nodes[level])-1)/2)
+	}
+}
+
+func (tree *MerkleTree) dataRoot() common.Hash {
+	if len(tree.Nodes[tree.Depth]) == 0 {
+		return zeroHashes[tree.Depth]
+	}
+	return tree.Nodes[tree.Depth][0]
+}
+
+func (tree *MerkleTree) root() common.Hash {
+	if tree.list {
+		return MixInLength(tree.dataRoot(), tree.length)
+	}
+	return tree.dataRoot()
+}
+
+func (tree *MerkleTree) lengthChunk() common.Hash {
+	var chunk common.Hash
+	binary.LittleEndian.PutUint64(chunk[:], tree.length)
+	return chunk
+}
+
+// node returns the node at the generalized index
+func (tree *MerkleTree) node(gindex uint64) (common.Hash, error) {
+	if gindex == 0 {
+		return common.Hash{}, errors.New(invalidGindex)
+	}
+	if tree.list {
+		if gindex == 1 {
+			return tree.root(), nil
+		}
+		d := bits.Len64(gindex) - 1
+		if gindex>>uint(d-1) == 3 {
+			if gindex == 3 {
+				return tree.lengthChunk(), nil
+			}
+			return common.Hash{}, errors.New(invalidGindex)
+		}
+		// Drop the step into the left subtree holding the chunks
+		gindex = gindex - 1<<uint(d) + 1<<uint(d-1)
+	}
+
+	d := bits.Len64(gindex) - 1
+	if d > tree.Depth {
+		return common.Hash{}, errors.New(invalidGindex)
+	}
+	level := tree.Depth - d
+	position := gindex - 1<<uint(d)
+	if position < uint64(len(tree.Nodes[level])) {
+		return tree.Nodes[level][position], nil
+	}
+	return zeroHashes[level], nil
+}
+
+// GeneralizedIndex returns the generalized index of the chunk at the given index
+func (tree *MerkleTree) GeneralizedIndex(index int) uint64 {
+	if tree.list {
+		return 1<<uint(tree.Depth+1) + uint64(index)
+	}
+	return 1<<uint(tree.Depth) + uint64(index)
+}
+
+// Proof returns the node at the generalized index together with the sibling hashes needed to produce the root from it
+func (tree *MerkleTree) Proof(gindex uint64) (leaf string, branch []string, err error) {
+	tree.Mutex.RLock()
+	defer tree.Mutex.RUnlock()
+
+	return tree.proof(gindex)
+}
+
+func (tree *MerkleTree) proof(gindex uint64) (leaf string, branch []string, err error) {
+	node, err := tree.node(gindex)
+	if err != nil {
+		return "", nil, err
+	}
+
+	branch = make([]string, 0, bits.Len64(gindex)-1)
+	for ; gindex > 1; gindex >>= 1 {
+		sibling, _ := tree.node(gindex ^ 1)
+		branch = append(branch, sibling.Hex())
+	}
+
+	return node.Hex(), branch, nil
+}
+
+// Append hashes the data with sha256 and appends it as a chunk to the list.
+// Returns the index it was inserted and the chunk or error if the tree does not accept more chunks
+func (tree *MerkleTree) Append(data []byte) (index int, hash string, err error) {
+	chunk := common.Hash(sha256.Sum256(data))
+
+	tree.Mutex.Lock()
+	defer tree.Mutex.Unlock()
+
+	if index, err = tree.rawInsert(chunk.Hex()); err != nil {
+		return -1, "", err
+	}
+	tree.propagateChange()
+	return index, chunk.Hex(), nil
+}
+
+// RawAppend hashes the data with sha256 and appends it as a chunk to the list without recalculating the tree.
+// Returns the index it was inserted and the chunk or error if the tree does not accept more chunks
+func (tree *MerkleTree) RawAppend(data []byte) (index int, hash string, err error) {
+	chunk := common.Hash(sha256.Sum256(data))
+
+	tree.Mutex.Lock()
+	defer tree.Mutex.Unlock()
+
+	if index, err = tree.rawInsert(chunk.Hex()); err != nil {
+		return -1, "", err
+	}
+	return index, chunk.Hex(), nil
+}
+
+// Add is Append that keeps the tree a merkletree.MerkleTree.
+// Returns -1 and empty chunk if the tree does not accept more chunks
+func (tree *MerkleTree) Add(data []byte) (index int, hash string) {
+	index, hash, _ = tree.Append(data)
+	return index, hash
+}
+
+// RawAdd is RawAppend that keeps the tree a merkletree.MerkleTree.
+// Returns -1 and empty chunk if the tree does not accept more chunks
+func (tree *MerkleTree) RawAdd(data []byte) (index int, hash string) {
+	index, hash, _ = tree.RawAppend(data)
+	return index, hash
+}
+
+// RawInsert appends the chunk to the list without recalculating the tree.
+// Returns the index of the chunk and the node or -1 and nil if the tree does not accept more chunks
+func (tree *MerkleTree) RawInsert(hash string) (index int, insertedLeaf merkletree.Node) {
+	tree.Mutex.Lock()
+	defer tree.Mutex.Unlock()
+
+	index, err := tree.rawInsert(hash)
+	if err != nil {
+		return -1, nil
+	}
+	return index, &Node{tree.Nodes[0][index], index}
+}
+
+func (tree *MerkleTree) rawInsert(hash string) (int, error) {
+	if tree.fixed {
+		return -1, errors.New(fixedLength)
+	}
+	if uint64(len(tree.Nodes[0])) >= tree.Limit {
+		return -1, errors.New(limitExceeded)
+	}
+
+	index := len(tree.Nodes[0])
+	tree.Nodes[0] = append(tree.Nodes[0], common.HexToHash(hash))
+	tree.length++
+
+	return index, nil
+}
+
+// Insert appends the chunk to the list and recalculates the right edge of the tree.
+// Returns the index it was inserted at or -1 if the tree does not accept more chunks
+func (tree *MerkleTree) Insert(hash string) (index int) {
+	tree.Mutex.Lock()
+	defer tree.Mutex.Unlock()
+
+	index, err := tree.rawInsert(hash)
+	if err != nil {
+		return -1
+	}
+	tree.propagateChange()
+	return index
+}
+
+// Recalculate recreates the whole tree bottom up and returns the hex string of the new root.
+// Great to be used with RawInsert when loading up the tree data.
+func (tree *MerkleTree) Recalculate() (treeRoot string) {
+	tree.Mutex.Lock()
+	defer tree.Mutex.Unlock()
+
+	tree.recalculate()
+	return tree.root().Hex()
+}
+
+// Truncate removes all chunks from the given size onwards and recalculates the tree.
+// Returns error if the size is negative or bigger than the current length of the list
+func (tree *MerkleTree) Truncate(size int) error {
+	tree.Mutex.Lock()
+	defer tree.Mutex.Unlock()
+
+	if tree.fixed || size < 0 || size > len(tree.Nodes[0]) {
+		return errors.New(invalidSize)
+	}
+
+	tree.Nodes[0] = tree.Nodes[0][:size]
+	tree.length = uint64(size)
+	tree.recalculate()
+
+	return nil
+}
+
+// IntermediaryHashesByIndex returns the sibling hashes needed to produce the root from the chunk at the given index.
+// For lists the last hash is the length that is mixed in
+func (tree *MerkleTree) IntermediaryHashesByIndex(index int) (intermediaryHashes []string, err error) {
+	tree.Mutex.RLock()
+	defer tree.Mutex.RUnlock()
+
+	if index < 0 || index >= len(tree.Nodes[0]) {
+		return nil, errors.New(outOfBounds)
+	}
+	_, intermediaryHashes, err = tree.proof(tree.GeneralizedIndex(index))
+	return intermediaryHashes, err
+}
+
+// ValidateExistence emulates how third party would validate the data. Given original data, the index it is supposed to be and the intermediaryHashes,
+// the method validates that the sha256 hash of the data is the chunk at that index
+func (tree *MerkleTree) ValidateExistence(original []byte, index int, intermediaryHashes []string) (result bool, err error) {
+	tree.Mutex.RLock()
+	defer tree.Mutex.RUnlock()
+
+	if index < 0 || index >= len(tree.Nodes[0]) {
+		return false, errors.New(outOfBounds)
+	}
+
+	chunk := common.Hash(sha256.Sum256(original))
+	return VerifyProof(tree.root().Hex(), tree.GeneralizedIndex(index), chunk.Hex(), intermediaryHashes), nil
+}
+
+// HashAt returns the chunk at given index
+func (tree *MerkleTree) HashAt(index int) (string, error) {
+	tree.Mutex.RLock()
+	defer tree.Mutex.RUnlock()
+
+	if index < 0 || index >= len(tree.Nodes[0]) {
+		return "", errors.New(outOfBounds)
+	}
+	return tree.Nodes[0][index].Hex(), nil
+}
+
+// Root returns the hash_tree_root of the list or vector
+func (tree *MerkleTree) Root() string {
+	tree.Mutex.RLock()
+	defer tree.Mutex.RUnlock()
+
+	return tree.root().Hex()
+}
+
+// Length returns the count of the chunks
+func (tree *MerkleTree) Length() int {
+	tree.Mutex.RLock()
+	defer tree.Mutex.RUnlock()
+
+	return len(tree.Nodes[0])
+}
+
+// String returns human readable version of the tree
+func (tree *MerkleTree) String() string {
+	tree.Mutex.RLock()
+	defer tree.Mutex.RUnlock()
+
+	b := strings.Builder{}
+	b.WriteString(fmt.Sprintf("Root: %v, Limit: %v, Length: %v\n", tree.root().Hex(), tree.Limit, tree.length))
+	for i := len(tree.Nodes) - 1; i >= 0; i-- {
+		b.WriteString(fmt.Sprintf("Level: %v, Count: %v\n", i, len(tree.Nodes[i])))
+		for _, node := range tree.Nodes[i] {
+			b.WriteString(fmt.Sprintf("%v\t", node.Hex()))
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// MarshalJSON Creates JSON version of the needed fields of the tree
+func (tree *MerkleTree) MarshalJSON() ([]byte, error) {
+	tree.Mutex.RLock()
+	defer tree.Mutex.RUnlock()
+
+	res := fmt.Sprintf("{\"root\":\"%v\", \"length\":%v, \"limit\":%v}", tree.root().Hex(), len(tree.Nodes[0]), tree.Limit)
+	return []byte(res), nil
+}
+
+func newMerkleTree(chunks []common.Hash, limit uint64, list bool) (*MerkleTree, error) {
+	if uint64(len(chunks)) > limit {
+		return nil, errors.New(limitExceeded)
+	}
+	d := depth(limit)
+	if d > maxProofDepth {
+		return nil, errors.New(limitTooBig)
+	}
+
+	tree := &MerkleTree{
+		Nodes:  [][]common.Hash{append([]common.Hash{}, chunks...)},
+		Depth:  d,
+		Limit:  limit,
+		list:   list,
+		length: uint64(len(chunks)),
+	}
+	tree.recalculate()
+
+	return tree, nil
+}
+
+// NewList returns a pointer to an initialized empty list of at most limit chunks
+func NewList(limit uint64) (*MerkleTree, error) {
+	return newMerkleTree(nil, limit, true)
+}
+
+// NewVector returns a pointer to an initialized vector of the given chunks
+func NewVector(chunks []common.Hash) (*MerkleTree, error) {
+	tree, err := newMerkleTree(chunks, uint64(len(chunks)), false)
+	if err != nil {
+		return nil, err
+	}
+	tree.fixed = true
+	return tree, nil
+}
+
+// NewByteVector returns a pointer to an initialized vector of the packed data
+func NewByteVector(data []byte) (*MerkleTree, error) {
+	return NewVector(Pack(data))
+}
+
+// NewByteList returns a pointer to an initialized list of the packed data with the given maximum length in bytes.
+// The length mixed into the root is the length of the data in bytes
+func NewByteList(data []byte, maxLength uint64) (*MerkleTree, error) {
+	if uint64(len(data)) > maxLength {
+		return nil, errors.New(limitExceeded)
+	}
+	tree, err := newMerkleTree(Pack(data), (maxLength+BytesPerChunk-1)/BytesPerChunk, true)
+	if err != nil {
+		return nil, err
+	}
+	tree.fixed = true
+	tree.length = uint64(len(data))
+	return tree, nil
+}
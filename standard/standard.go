@@ -0,0 +1,330 @@
+// Package standard implements the StandardMerkleTree of the @openzeppelin/merkle-tree JavaScript library.
+// Leafs are keccak256(keccak256(abi.encode(values))) of typed values, siblings are hashed in sorted order and the tree
+// is kept as a flat array with the root at 0 and the leafs at the end in reverse order. Trees load from and dump to the
+// "standard-v1" JSON format of the library, so roots and proofs agree between Go and JavaScript
+package standard
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"sort"
+	"strings"
+)
+
+// Format is the format identifier of the JSON dump
+const Format = "standard-v1"
+
+const (
+	outOfBounds   = "Incorrect index - Index out of bounds"
+	emptyTree     = "Incorrect values - Expected at least one value"
+	unknownFormat = "Incorrect format - Expected " + Format
+	invalidTree   = "Incorrect tree - The tree nodes are not consistent"
+	invalidLeaf   = "Incorrect tree - The value does not match the leaf at its tree index"
+	valueNotFound = "Incorrect value - The value is not in the tree"
+)
+
+// Value is a leaf value of the tree with the position of its leaf in the tree array
+type Value struct {
+	Value     []interface{} `json:"value"`
+	TreeIndex int           `json:"treeIndex"`
+}
+
+// Dump is the "standard-v1" JSON representation of the tree
+type Dump struct {
+	Format       string   `json:"format"`
+	Tree         []string `json:"tree"`
+	Values       []Value  `json:"values"`
+	LeafEncoding []string `json:"leafEncoding"`
+}
+
+// MerkleTree is a StandardMerkleTree. It is built once out of all values and can not be changed afterwards
+type MerkleTree struct {
+	leafEncoding []string
+	arguments    abi.Arguments
+	tree         []common.Hash
+	values       []Value
+}
+
+func hashPair(a, b common.Hash) common.Hash {
+	if bytes.Compare(a[:], b[:]) > 0 {
+		a, b = b, a
+	}
+	return crypto.Keccak256Hash(a[:], b[:])
+}
+
+func leftChild(i int) int {
+	return 2*i + 1
+}
+
+func sibling(i int) int {
+	if i%2 == 1 {
+		return i + 1
+	}
+	return i - 1
+}
+
+func parent(i int) int {
+	return (i - 1) / 2
+}
+
+func leafHash(args abi.Arguments, value []interface{}) (common.Hash, error) {
+	encoded, err := encode(args, value)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash(crypto.Keccak256(encoded)), nil
+}
+
+// LeafHash returns keccak256(keccak256(abi.encode(value))) of the value with the given leaf encoding
+func LeafHash(leafEncoding []string, value []interface{}) (string, error) {
+	args, err := arguments(leafEncoding)
+	if err != nil {
+		return "", err
+	}
+	h, err := leafHash(args, value)
+	if err != nil {
+		return "", err
+	}
+	return h.Hex(), nil
+}
+
+// makeTree lays out the leafs at the end of the array in reverse order and hashes the parents in front of them
+func makeTree(leafs []common.Hash) []common.Hash {
+	tree := make([]common.Hash, 2*len(leafs)-1)
+	for i, leaf := range leafs {
+		tree[len(tree)-1-i] = leaf
+	}
+	for i := len(tree) - 1 - len(leafs); i >= 0; i-- {
+		tree[i] = hashPair(tree[leftChild(i)], tree[leftChild(i)+1])
+	}
+	return tree
+}
+
+// Of builds the tree out of the values, each of them a list of fields typed by the leaf encoding, e.g. ["address", "uint256"].
+// The leafs are sorted by hash, the same as the sortLeaves default of the JavaScript library
+func Of(values [][]interface{}, leafEncoding []string) (*MerkleTree, error) {
+	if len(values) == 0 {
+		return nil, errors.New(emptyTree)
+	}
+	args, err := arguments(leafEncoding)
+	if err != nil {
+		return nil, err
+	}
+
+	type hashedValue struct {
+		valueIndex int
+		hash       common.Hash
+	}
+	hashed := make([]hashedValue, len(values))
+	for i, value := range values {
+		h, err := leafHash(args, value)
+		if err != nil {
+			return nil, err
+		}
+		hashed[i] = hashedValue{i, h}
+	}
+	sort.SliceStable(hashed, func(i, j int) bool {
+		return bytes.Compare(hashed[i].hash[:], hashed[j].hash[:]) < 0
+	})
+
+	leafs := make([]common.Hash, len(hashed))
+	for i, h := range hashed {
+		leafs[i] = h.hash
+	}
+	tree := &MerkleTree{
+		leafEncoding: append([]string{}, leafEncoding...),
+		arguments:    args,
+		tree:         makeTree(leafs),
+		values:       make([]Value, len(values)),
+	}
+	for leafIndex, h := range hashed {
+		tree.values[h.valueIndex] = Value{normalize(values[h.valueIndex]), len(tree.tree) - 1 - leafIndex}
+	}
+
+	return tree, nil
+}
+
+// Load parses tree dumped in the "standard-v1" JSON format and validates it
+func Load(data []byte) (*MerkleTree, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+
+	dump := Dump{}
+	if err := d.Decode(&dump); err != nil {
+		return nil, err
+	}
+	if dump.Format != Format {
+		return nil, errors.New(unknownFormat)
+	}
+	if len(dump.Tree) == 0 || len(dump.Values) == 0 {
+		return nil, errors.New(emptyTree)
+	}
+
+	args, err := arguments(dump.LeafEncoding)
+	if err != nil {
+		return nil, err
+	}
+	tree := &MerkleTree{
+		leafEncoding: dump.LeafEncoding,
+		arguments:    args,
+		tree:         make([]common.Hash, len(dump.Tree)),
+		values:       dump.Values,
+	}
+	for i, node := range dump.Tree {
+		h, err := hexutil.Decode(node)
+		if err != nil || len(h) != common.HashLength {
+			return nil, errors.New(invalidTree)
+		}
+		tree.tree[i] = common.BytesToHash(h)
+	}
+
+	if err := tree.Validate(); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// Validate checks that every parent is the hash of its children and that every value matches the leaf at its tree index
+func (tree *MerkleTree) Validate() error {
+	if len(tree.tree)%2 == 0 {
+		return errors.New(invalidTree)
+	}
+	for i := len(tree.tree)/2 - 1; i >= 0; i-- {
+		if tree.tree[i] != hashPair(tree.tree[leftChild(i)], tree.tree[leftChild(i)+1]) {
+			return errors.New(invalidTree)
+		}
+	}
+
+	for _, value := range tree.values {
+		if value.TreeIndex < len(tree.tree)/2 || value.TreeIndex >= len(tree.tree) {
+			return errors.New(invalidLeaf)
+		}
+		h, err := leafHash(tree.arguments, value.Value)
+		if err != nil {
+			return err
+		}
+		if h != tree.tree[value.TreeIndex] {
+			return errors.New(invalidLeaf)
+		}
+	}
+
+	return nil
+}
+
+// Dump returns the tree in the "standard-v1" JSON format
+func (tree *MerkleTree) Dump() ([]byte, error) {
+	dump := Dump{
+		Format:       Format,
+		LeafEncoding: tree.leafEncoding,
+		Tree:         make([]string, len(tree.tree)),
+		Values:       tree.values,
+	}
+	for i, node := range tree.tree {
+		dump.Tree[i] = node.Hex()
+	}
+	return json.Marshal(dump)
+}
+
+// Root returns the hash of the root of the tree
+func (tree *MerkleTree) Root() string {
+	return tree.tree[0].Hex()
+}
+
+// Length returns the count of the values
+func (tree *MerkleTree) Length() int {
+	return len(tree.values)
+}
+
+// LeafEncoding returns the types of the fields of the values
+func (tree *MerkleTree) LeafEncoding() []string {
+	return append([]string{}, tree.leafEncoding...)
+}
+
+// At returns the value at the given index in the order the values were given to Of
+func (tree *MerkleTree) At(index int) ([]interface{}, error) {
+	if index < 0 || index >= len(tree.values) {
+		return nil, errors.New(outOfBounds)
+	}
+	return tree.values[index].Value, nil
+}
+
+// HashAt returns the leaf hash of the value at the given index
+func (tree *MerkleTree) HashAt(index int) (string, error) {
+	if index < 0 || index >= len(tree.values) {
+		return "", errors.New(outOfBounds)
+	}
+	return tree.tree[tree.values[index].TreeIndex].Hex(), nil
+}
+
+// IndexOf returns the index of the value or error if it is not in the tree
+func (tree *MerkleTree) IndexOf(value []interface{}) (int, error) {
+	h, err := leafHash(tree.arguments, value)
+	if err != nil {
+		return -1, err
+	}
+	for i, v := range tree.values {
+		if tree.tree[v.TreeIndex] == h {
+			return i, nil
+		}
+	}
+	return -1, errors.New(valueNotFound)
+}
+
+// GetProof returns the sibling hashes from the leaf of the value at the given index up to the root
+func (tree *MerkleTree) GetProof(index int) ([]string, error) {
+	if index < 0 || index >= len(tree.values) {
+		return nil, errors.New(outOfBounds)
+	}
+
+	proof := make([]string, 0)
+	for i := tree.values[index].TreeIndex; i > 0; i = parent(i) {
+		proof = append(proof, tree.tree[sibling(i)].Hex())
+	}
+	return proof, nil
+}
+
+// IntermediaryHashesByIndex is alias to GetProof
+func (tree *MerkleTree) IntermediaryHashesByIndex(index int) (intermediaryHashes []string, err error) {
+	return tree.GetProof(index)
+}
+
+// Verify validates the proof of the value against the root of the tree
+func (tree *MerkleTree) Verify(value []interface{}, proof []string) (bool, error) {
+	return Verify(tree.Root(), tree.leafEncoding, value, proof)
+}
+
+// Verify validates that the value with the given leaf encoding is in the tree with the given root
+func Verify(root string, leafEncoding []string, value []interface{}, proof []string) (bool, error) {
+	h, err := LeafHash(leafEncoding, value)
+	if err != nil {
+		return false, err
+	}
+
+	node := common.HexToHash(h)
+	for _, p := range proof {
+		node = hashPair(node, common.HexToHash(p))
+	}
+	return node == common.HexToHash(root), nil
+}
+
+// String returns human readable version of the tree
+func (tree *MerkleTree) String() string {
+	b := strings.Builder{}
+	b.WriteString(fmt.Sprintf("Root: %v, Count: %v\n", tree.Root(), tree.Length()))
+	for i, v := range tree.values {
+		b.WriteString(fmt.Sprintf("%v) %v\n", i, v.Value))
+	}
+	return b.String()
+}
+
+// MarshalJSON Creates JSON version of the needed fields of the tree. Use Dump for the "standard-v1" format
+func (tree *MerkleTree) MarshalJSON() ([]byte, error) {
+	res := fmt.Sprintf("{\"root\":\"%v\", \"length\":%v}", tree.Root(), tree.Length())
+	return []byte(res), nil
+}
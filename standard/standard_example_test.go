@@ -0,0 +1,28 @@
+package standard_test
+
+import (
+	"fmt"
+	"github.com/LimeChain/merkletree/standard"
+)
+
+func Example() {
+	values := [][]interface{}{
+		{"0x1111111111111111111111111111111111111111", "5000000000000000000"},
+		{"0x2222222222222222222222222222222222222222", "2500000000000000000"},
+	}
+	tree, _ := standard.Of(values, []string{"address", "uint256"})
+	fmt.Printf("Merkle Root: %v\n", tree.Root())
+
+	proof, _ := tree.GetProof(0)
+	fmt.Printf("Proof: %v\n", proof)
+
+	dump, _ := tree.Dump()
+	loaded, _ := standard.Load(dump)
+	valid, _ := loaded.Verify(values[0], proof)
+	fmt.Printf("Proof Valid: %v\n", valid)
+
+	// Output:
+	// Merkle Root: 0xd4dee0beab2d53f2cc83e567171bd2820e49898130a22622b10ead383e90bd77
+	// Proof: [0xb92c48e9d7abe27fd8dfd6b5dfdbfb1c9a463f80c712b66f3a5180a090cccafc]
+	// Proof Valid: true
+}
@@ -0,0 +1,135 @@
+package standard
+
+import (
+	"github.com/LimeChain/merkletree/merkletreetest"
+	"github.com/ethereum/go-ethereum/common"
+	"math/big"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Values, root and proof of the README of @openzeppelin/merkle-tree
+var readmeValues = [][]interface{}{
+	{"0x1111111111111111111111111111111111111111", "5000000000000000000"},
+	{"0x2222222222222222222222222222222222222222", "2500000000000000000"},
+}
+
+const (
+	readmeRoot  = "0xd4dee0beab2d53f2cc83e567171bd2820e49898130a22622b10ead383e90bd77"
+	readmeProof = "0xb92c48e9d7abe27fd8dfd6b5dfdbfb1c9a463f80c712b66f3a5180a090cccafc"
+)
+
+func TestOf(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree, err := Of(readmeValues, []string{"address", "uint256"})
+	et.Assert(err == nil, "Error was thrown on building the tree")
+	et.Assert(tree.Root() == readmeRoot, "Root was not correct", tree.Root())
+
+	proof, err := tree.GetProof(0)
+	et.Assert(err == nil, "Error was thrown on getting the proof")
+	et.Assert(len(proof) == 1 && proof[0] == readmeProof, "Proof was not correct", proof)
+
+	ok, err := Verify(readmeRoot, []string{"address", "uint256"}, readmeValues[0], proof)
+	et.Assert(err == nil && ok, "Proof did not verify")
+
+	_, err = Of(nil, []string{"address"})
+	et.Assert(err != nil, "Empty tree did not return error")
+	_, err = Of(readmeValues, []string{"address"})
+	et.Assert(err != nil, "Values with more fields than the encoding did not return error")
+	_, err = Of(readmeValues, []string{"address", "uint256[]"})
+	et.Assert(err != nil, "Unsupported type did not return error")
+}
+
+func TestTypes(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	encoding := []string{"address", "uint8", "int256", "bool", "string", "bytes", "bytes32", "bytes4"}
+	values := [][]interface{}{
+		{common.HexToAddress("0x1111111111111111111111111111111111111111"), 255, big.NewInt(-1), true, "hello", []byte{1, 2, 3}, common.Hash{1}, "0x01020304"},
+		{"0x2222222222222222222222222222222222222222", "0x10", "-5", false, "", "0x", common.Hash{2}.Hex(), []byte{5, 6, 7, 8}},
+		{"0x3333333333333333333333333333333333333333", 0, 0, false, "world", "0xff", common.Hash{3}, "0xffffffff"},
+	}
+
+	tree, err := Of(values, encoding)
+	et.Assert(err == nil, "Error was thrown on building the tree")
+	for i, value := range values {
+		proof, _ := tree.GetProof(i)
+		ok, err := tree.Verify(value, proof)
+		et.Assert(err == nil && ok, "Proof of value did not verify", i)
+	}
+
+	invalid := [][]interface{}{
+		{"0x11", 1, 1, true, "", "0x", common.Hash{}, "0x01020304"},
+		{"0x1111111111111111111111111111111111111111", 256, 1, true, "", "0x", common.Hash{}, "0x01020304"},
+		{"0x1111111111111111111111111111111111111111", -1, 1, true, "", "0x", common.Hash{}, "0x01020304"},
+		{"0x1111111111111111111111111111111111111111", 1, 1, "true", "", "0x", common.Hash{}, "0x01020304"},
+		{"0x1111111111111111111111111111111111111111", 1, 1, true, "", "0x", common.Hash{}, "0x010203"},
+		{"0x1111111111111111111111111111111111111111", 1, 1, true, "", "zz", common.Hash{}, "0x01020304"},
+	}
+	for i, value := range invalid {
+		_, err := LeafHash(encoding, value)
+		et.Assert(err != nil, "Invalid value did not return error", i)
+	}
+}
+
+func TestProofs(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	values := make([][]interface{}, 0)
+	for n := 1; n <= 17; n++ {
+		values = append(values, []interface{}{"0x" + strings.Repeat(strconv.Itoa(n%10), 40), strconv.Itoa(n * 1000)})
+
+		tree, err := Of(values, []string{"address", "uint256"})
+		et.Assert(err == nil, "Error was thrown on building the tree of", n)
+		for i, value := range values {
+			proof, _ := tree.GetProof(i)
+			ok, _ := tree.Verify(value, proof)
+			et.Assert(ok, "Proof did not verify", i, n)
+
+			index, err := tree.IndexOf(value)
+			et.Assert(err == nil && index == i, "Incorrect index of value", i, index)
+			if n > 1 {
+				ok, _ = tree.Verify([]interface{}{value[0], "1"}, proof)
+				et.Assert(!ok, "Proof of wrong value verified", i, n)
+			}
+		}
+	}
+}
+
+func TestDumpLoad(t *testing.T) {
+	et := merkletreetest.WrapTesting(t)
+	tree, _ := Of(readmeValues, []string{"address", "uint256"})
+	dump, err := tree.Dump()
+	et.Assert(err == nil, "Error was thrown on dumping the tree")
+
+	expected := `{"format":"standard-v1","tree":["` + readmeRoot + `","0xeb02c421cfa48976e66dfb29120745909ea3a0f843456c263cf8f1253483e283","` + readmeProof + `"],` +
+		`"values":[{"value":["0x1111111111111111111111111111111111111111","5000000000000000000"],"treeIndex":1},` +
+		`{"value":["0x2222222222222222222222222222222222222222","2500000000000000000"],"treeIndex":2}],"leafEncoding":["address","uint256"]}`
+	et.Assert(string(dump) == expected, "Dump was not correct", string(dump))
+
+	loaded, err := Load(dump)
+	et.Assert(err == nil, "Error was thrown on loading the dump")
+	et.Assert(loaded.Root() == readmeRoot, "Root of loaded tree was not correct")
+	again, _ := loaded.Dump()
+	et.Assert(string(again) == string(dump), "Dump of loaded tree was not the same")
+
+	// Numbers stay numbers
+	numbers := `{"format":"standard-v1","tree":["0x0000000000000000000000000000000000000000000000000000000000000000"],"values":[{"value":[5],"treeIndex":0}],"leafEncoding":["uint256"]}`
+	single, _ := Of([][]interface{}{{5}}, []string{"uint256"})
+	numbers = strings.Replace(numbers, common.Hash{}.Hex(), single.Root(), 1)
+	loaded, err = Load([]byte(numbers))
+	et.Assert(err == nil, "Error was thrown on loading dump with numbers")
+	again, _ = loaded.Dump()
+	et.Assert(string(again) == numbers, "Dump of loaded tree was not correct", string(again))
+
+	tampered := []string{
+		strings.Replace(string(dump), "standard-v1", "standard-v2", 1),
+		strings.Replace(string(dump), "5000000000000000000", "5000000000000000001", 1),
+		strings.Replace(string(dump), `"treeIndex":1`, `"treeIndex":0`, 1),
+		strings.Replace(string(dump), readmeRoot[:10], "0x00000000", 1),
+		strings.Replace(string(dump), readmeProof, "0x1234", 1),
+	}
+	for i, data := range tampered {
+		_, err := Load([]byte(data))
+		et.Assert(err != nil, "Loading tampered dump did not return error", i)
+	}
+}
@@ -0,0 +1,192 @@
+package standard
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"math/big"
+	"reflect"
+	"strings"
+)
+
+const (
+	unsupportedType = "Incorrect leaf encoding - Only address, bool, string, bytes, bytesN, uintN and intN are supported"
+	wrongValueCount = "Incorrect value - The count of fields does not match the leaf encoding"
+)
+
+func invalidValue(t string, v interface{}) error {
+	return fmt.Errorf("Incorrect value - %v is not a valid %v", v, t)
+}
+
+func arguments(leafEncoding []string) (abi.Arguments, error) {
+	args := make(abi.Arguments, len(leafEncoding))
+	for i, t := range leafEncoding {
+		typ, err := abi.NewType(t, "", nil)
+		if err != nil {
+			return nil, err
+		}
+		switch typ.T {
+		case abi.AddressTy, abi.BoolTy, abi.StringTy, abi.BytesTy, abi.FixedBytesTy, abi.UintTy, abi.IntTy:
+		default:
+			return nil, errors.New(unsupportedType)
+		}
+		args[i] = abi.Argument{Type: typ}
+	}
+	return args, nil
+}
+
+// toInteger parses integers given as decimal or 0x prefixed hex strings, JSON numbers, Go integers or *big.Int
+func toInteger(v interface{}) (*big.Int, bool) {
+	switch value := v.(type) {
+	case *big.Int:
+		if value == nil {
+			return nil, false
+		}
+		return new(big.Int).Set(value), true
+	case string:
+		if strings.HasPrefix(value, "0x") || strings.HasPrefix(value, "0X") {
+			return new(big.Int).SetString(value[2:], 16)
+		}
+		return new(big.Int).SetString(value, 10)
+	case json.Number:
+		return new(big.Int).SetString(value.String(), 10)
+	case float64:
+		i, accuracy := big.NewFloat(value).Int(nil)
+		return i, accuracy == big.Exact
+	case int:
+		return big.NewInt(int64(value)), true
+	case int64:
+		return big.NewInt(value), true
+	case uint64:
+		return new(big.Int).SetUint64(value), true
+	}
+	return nil, false
+}
+
+func toBytes(v interface{}) ([]byte, bool) {
+	switch value := v.(type) {
+	case string:
+		b, err := hexutil.Decode(value)
+		return b, err == nil
+	case []byte:
+		return value, true
+	case common.Hash:
+		return value[:], true
+	case common.Address:
+		return value[:], true
+	}
+	return nil, false
+}
+
+// toABIValue converts the value into the Go type the abi package packs for the given type
+func toABIValue(typ abi.Type, v interface{}) (interface{}, error) {
+	t := typ.String()
+	switch typ.T {
+	case abi.AddressTy:
+		if address, ok := v.(common.Address); ok {
+			return address, nil
+		}
+		s, ok := v.(string)
+		if !ok || !common.IsHexAddress(s) || !strings.HasPrefix(s, "0x") {
+			return nil, invalidValue(t, v)
+		}
+		return common.HexToAddress(s), nil
+
+	case abi.BoolTy:
+		b, ok := v.(bool)
+		if !ok {
+			return nil, invalidValue(t, v)
+		}
+		return b, nil
+
+	case abi.StringTy:
+		s, ok := v.(string)
+		if !ok {
+			return nil, invalidValue(t, v)
+		}
+		return s, nil
+
+	case abi.BytesTy:
+		b, ok := toBytes(v)
+		if !ok {
+			return nil, invalidValue(t, v)
+		}
+		return b, nil
+
+	case abi.FixedBytesTy:
+		b, ok := toBytes(v)
+		if !ok || len(b) != typ.Size {
+			return nil, invalidValue(t, v)
+		}
+		array := reflect.New(typ.Type).Elem()
+		reflect.Copy(array, reflect.ValueOf(b))
+		return array.Interface(), nil
+
+	case abi.UintTy, abi.IntTy:
+		i, ok := toInteger(v)
+		if !ok {
+			return nil, invalidValue(t, v)
+		}
+		min, max := new(big.Int), new(big.Int).Lsh(big.NewInt(1), uint(typ.Size))
+		if typ.T == abi.IntTy {
+			max.Rsh(max, 1)
+			min.Neg(max)
+		}
+		if i.Cmp(min) < 0 || i.Cmp(max) >= 0 {
+			return nil, invalidValue(t, v)
+		}
+
+		switch typ.Kind {
+		case reflect.Ptr:
+			return i, nil
+		case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			value := reflect.New(typ.Type).Elem()
+			value.SetUint(i.Uint64())
+			return value.Interface(), nil
+		default:
+			value := reflect.New(typ.Type).Elem()
+			value.SetInt(i.Int64())
+			return value.Interface(), nil
+		}
+	}
+	return nil, errors.New(unsupportedType)
+}
+
+func encode(args abi.Arguments, value []interface{}) ([]byte, error) {
+	if len(value) != len(args) {
+		return nil, errors.New(wrongValueCount)
+	}
+	values := make([]interface{}, len(value))
+	for i, v := range value {
+		abiValue, err := toABIValue(args[i].Type, v)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = abiValue
+	}
+	return args.Pack(values...)
+}
+
+// normalize converts Go values into their JSON representation in the dump, where integers are decimal strings and
+// byte values are 0x prefixed hex, the way the JavaScript library expects them
+func normalize(value []interface{}) []interface{} {
+	res := make([]interface{}, len(value))
+	for i, v := range value {
+		switch v := v.(type) {
+		case *big.Int:
+			res[i] = v.String()
+		case common.Address:
+			res[i] = v.Hex()
+		case common.Hash:
+			res[i] = v.Hex()
+		case []byte:
+			res[i] = hexutil.Encode(v)
+		default:
+			res[i] = v
+		}
+	}
+	return res
+}
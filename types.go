@@ -3,6 +3,7 @@ package merkletree
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 )
 
 // Node represents a single node in a Merkle tree
@@ -37,6 +38,11 @@ type InternalMerkleTree interface {
 	internaler
 }
 
+// Truncater is implemented by trees that can be rolled back to an earlier size
+type Truncater interface {
+	Truncate(size int) error
+}
+
 type externaler interface {
 	json.Marshaler
 }
@@ -53,3 +59,107 @@ type FullMerkleTree interface {
 	internaler
 	externaler
 }
+
+// Transaction groups additions to a tree that are published together on Commit or discarded on Rollback
+type Transaction interface {
+	Add(data []byte) (index int, hash string)
+	Insert(hash string) (index int)
+	Commit() (root string, err error)
+	Rollback() error
+}
+
+// TransactionalMerkleTree is a FullMerkleTree that can group several additions into a single Transaction
+type TransactionalMerkleTree interface {
+	FullMerkleTree
+	Begin() (Transaction, error)
+}
+
+// KeyValueMerkleTree defines the methods a Merkle tree that maps keys to values should have.
+// Proofs are the encoded nodes on the path from the root to the key. An empty value stands for a missing key
+type KeyValueMerkleTree interface {
+	Put(key []byte, value []byte) error
+	Get(key []byte) ([]byte, error)
+	Delete(key []byte) error
+	Prove(key []byte) (proof []string, err error)
+	ValidateProof(key []byte, value []byte, proof []string) (bool, error)
+	Root() string
+}
+
+// Store persists the leafs of a tree, so the tree can be loaded back after restart. The leafs are appended starting at
+// the given index and are returned in the order of their indexes. IterateLeaves streams them without loading all at once
+// and stops on the first error returned by the callback
+type Store interface {
+	AppendLeaves(start int, hashes []string) error
+	TruncateLeaves(size int) error
+	LoadLeaves() (hashes []string, err error)
+	IterateLeaves(fn func(index int, hash string) error) error
+}
+
+// StoredNode is a node of a tree persisted by its level and its index in the level. Level 0 are the leafs
+type StoredNode struct {
+	Level int
+	Index int
+	Hash  string
+}
+
+// NodeStore is a Store that optionally persists the intermediary nodes of the tree as well. Truncating the leafs also
+// removes the nodes that are no longer part of the tree
+type NodeStore interface {
+	Store
+	SaveNodes(nodes []StoredNode) error
+	LoadNode(level int, index int) (hash string, err error)
+}
+
+// SaltStore is a Store that keeps the salts of the salted leafs, so they can be disclosed after restart.
+// Saving a salt replaces the one saved at the same index and truncating the leafs also removes their salts
+type SaltStore interface {
+	Store
+	SaveSalt(index int, salt []byte) error
+	LoadSalts() (salts map[int][]byte, err error)
+}
+
+// PathMerkleTree is a tree that exposes the nodes on the path from a leaf up to the root - the only nodes that change
+// when the leaf is added
+type PathMerkleTree interface {
+	Path(index int) (nodes []Node, err error)
+}
+
+// PrefixMerkleTree is a tree that can tell the root it had at any earlier size without being truncated
+type PrefixMerkleTree interface {
+	RootAt(size int) (root string, err error)
+}
+
+// Appender is implemented by trees whose additions can fail, e.g. because they are written to a store.
+// A failed addition leaves the tree as it was before it
+type Appender interface {
+	Append(data []byte) (index int, hash string, err error)
+	RawAppend(data []byte) (index int, hash string, err error)
+}
+
+// TreeRegistry keeps several trees by name, e.g. in a single database
+type TreeRegistry interface {
+	Trees() (names []string, err error)
+	Create(name string) (ExternalMerkleTree, error)
+	Tree(name string) (ExternalMerkleTree, error)
+}
+
+// RootRecord is a root the tree had at the given size, recorded at the given time and optionally signed
+type RootRecord struct {
+	Size      int       `json:"size"`
+	Root      string    `json:"root"`
+	CreatedAt time.Time `json:"createdAt"`
+	Signature string    `json:"signature,omitempty"`
+}
+
+// RootRecorder is implemented by stores that keep the history of the roots. RecordRoot is called every time the root
+// of the tree changes
+type RootRecorder interface {
+	RecordRoot(size int, root string) error
+}
+
+// RootHistory answers which root a tree had. RootAtSize returns the last root recorded at the given size and RootAtTime
+// the last root recorded at or before the given time
+type RootHistory interface {
+	RootAtSize(size int) (RootRecord, error)
+	RootAtTime(t time.Time) (RootRecord, error)
+}